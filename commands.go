@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/generate"
+	"github.com/mount-exporter/mount-exporter/metrics"
+)
+
+// runCommand runs a subcommand
+func runCommand(args []string) error {
+	switch args[0] {
+	case "generate":
+		return runGenerate(args[1:])
+	case "config":
+		return runConfig(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q, see -help", args[0])
+	}
+}
+
+// runGenerate writes Prometheus rules or a Grafana dashboard derived from
+// the configuration and the collectors' metric descriptors
+func runGenerate(args []string) error {
+	if len(args) == 0 || (args[0] != "rules" && args[0] != "dashboard") {
+		return fmt.Errorf("usage: mount-exporter generate <rules|dashboard> [-config file] [-output file]")
+	}
+
+	fs := flag.NewFlagSet("generate "+args[0], flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to configuration file")
+	output := fs.String("output", "", "Write to this file instead of standard output")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := loadConfiguration(*configPath)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	descs := generate.Descriptors(cfg)
+
+	var data []byte
+	if args[0] == "rules" {
+		data, err = generate.Rules(cfg, descs)
+	} else {
+		data, err = generate.Dashboard(cfg, descs)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate %s: %w", args[0], err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0o644)
+}
+
+// runConfig runs the config subcommands
+func runConfig(args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "check":
+			return runConfigCheck(args[1:])
+		case "schema":
+			return runConfigSchema(args[1:])
+		}
+	}
+	return fmt.Errorf("usage: mount-exporter config check [-dry-run] <file> | config schema [-output file]")
+}
+
+// runConfigSchema writes the JSON Schema of the configuration file
+func runConfigSchema(args []string) error {
+	fs := flag.NewFlagSet("config schema", flag.ContinueOnError)
+	output := fs.String("output", "", "Write to this file instead of standard output")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	data, err := config.Schema()
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0o644)
+}
+
+// runConfigCheck reports every problem in a configuration file and,
+// optionally, what a collection with it would export
+func runConfigCheck(args []string) error {
+	fs := flag.NewFlagSet("config check", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "Collect once and print what would be exported for each mount")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// Allow flags after the file name as well
+	file := fs.Arg(0)
+	if fs.NArg() > 1 {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 0 {
+			file = ""
+		}
+	}
+	if file == "" {
+		return fmt.Errorf("usage: mount-exporter config check [-dry-run] <file>")
+	}
+	return checkConfigFile(file, *dryRun)
+}
+
+// checkConfigFile prints the errors and warnings found in file, then runs
+// the dry run when requested and the file has neither
+func checkConfigFile(file string, dryRun bool) error {
+	cfg, problems, err := config.CheckFile(file)
+	if err != nil {
+		return err
+	}
+
+	errorCount := 0
+	for _, p := range problems {
+		kind := "warning"
+		if !p.Warning {
+			kind = "error"
+			errorCount++
+		}
+
+		location := file
+		if p.File != "" {
+			location = p.File
+		}
+		if p.Line > 0 {
+			location = fmt.Sprintf("%s:%d", location, p.Line)
+			if p.Column > 0 {
+				location = fmt.Sprintf("%s:%d", location, p.Column)
+			}
+		}
+		fmt.Printf("%s: %s: %s\n", location, kind, p.Message)
+	}
+
+	// The exporter refuses to start with unknown keys, so warnings fail the
+	// check as well
+	if len(problems) > 0 {
+		return fmt.Errorf("%s has %d error(s) and %d warning(s)", file, errorCount, len(problems)-errorCount)
+	}
+	fmt.Printf("%s: OK\n", file)
+
+	if dryRun {
+		fmt.Println()
+		return metrics.NewCollector(cfg).DryRun(os.Stdout)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// labelNamePattern matches valid Prometheus label names
+var labelNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// AlertingConfig represents the settings used by `mount-exporter generate
+// rules` and `generate dashboard`. It does not affect the exporter itself.
+type AlertingConfig struct {
+	// Severity is the severity label of generated alerts; empty means warning
+	Severity string `yaml:"severity"`
+	// For is how long a condition must hold before an alert fires
+	For time.Duration `yaml:"for"`
+	// Labels are added to every generated alert
+	Labels map[string]string `yaml:"labels,omitempty"`
+	// Mounts override severity and labels, and set expectations, per mount
+	Mounts []MountAlertConfig `yaml:"mounts,omitempty"`
+}
+
+// MountAlertConfig represents the alerting settings of one configured mount
+// point. Expectations that are left empty are not checked.
+type MountAlertConfig struct {
+	MountPoint string            `yaml:"mount_point"`
+	Severity   string            `yaml:"severity,omitempty"`
+	Labels     map[string]string `yaml:"labels,omitempty"`
+	// FSType is the expected filesystem type
+	FSType string `yaml:"fs_type,omitempty"`
+	// ReadOnly is the expected read-only state
+	ReadOnly *bool `yaml:"read_only,omitempty"`
+	// Options are mount options that must be set, e.g. "noexec"
+	Options []string `yaml:"options,omitempty"`
+}
+
+// MountAlert returns the alerting settings of mountPoint, or false when it
+// has none
+func (a AlertingConfig) MountAlert(mountPoint string) (MountAlertConfig, bool) {
+	for _, m := range a.Mounts {
+		if m.MountPoint == mountPoint {
+			return m, true
+		}
+	}
+	return MountAlertConfig{}, false
+}
+
+// validateAlerting checks the for duration and label names, and that per-mount
+// settings refer to configured mount points
+func (c *Config) validateAlerting() error {
+	a := c.Alerting
+	if a.For < 0 {
+		return fmt.Errorf("alerting for cannot be negative, got %v", a.For)
+	}
+
+	if err := validateAlertLabels("alerting labels", a.Labels); err != nil {
+		return err
+	}
+
+	configured := make(map[string]bool)
+	for _, m := range c.allMounts() {
+		configured[m.Path] = true
+	}
+
+	seen := make(map[string]bool, len(a.Mounts))
+	for i, m := range a.Mounts {
+		if err := validateMountAlert(m, configured, seen); err != nil {
+			return &FieldError{Path: fmt.Sprintf("alerting.mounts[%d]", i), Err: err}
+		}
+		seen[m.MountPoint] = true
+	}
+
+	return nil
+}
+
+// validateMountAlert checks a single alerting mounts entry against the
+// configured mount points and the entries seen before it
+func validateMountAlert(m MountAlertConfig, configured, seen map[string]bool) error {
+	if !configured[m.MountPoint] {
+		return fmt.Errorf("alerting mounts entry %q is not a configured mount point", m.MountPoint)
+	}
+	if seen[m.MountPoint] {
+		return fmt.Errorf("alerting mounts entry %s is configured more than once", m.MountPoint)
+	}
+
+	if err := validateAlertLabels("alerting mounts entry "+m.MountPoint+" labels", m.Labels); err != nil {
+		return err
+	}
+	for _, opt := range m.Options {
+		if opt == "" {
+			return fmt.Errorf("alerting mounts entry %s: options cannot be empty", m.MountPoint)
+		}
+	}
+	return nil
+}
+
+// validateAlertLabels checks label names; severity is set through its own
+// setting instead
+func validateAlertLabels(name string, labels map[string]string) error {
+	for label := range labels {
+		if !labelNamePattern.MatchString(label) {
+			return fmt.Errorf("%s: invalid label name %q", name, label)
+		}
+		if label == "severity" {
+			return fmt.Errorf("%s: use severity instead of a severity label", name)
+		}
+	}
+	return nil
+}
+
+// clone returns a deep copy of the alerting configuration
+func (a AlertingConfig) clone() AlertingConfig {
+	a.Labels = cloneLabels(a.Labels)
+	if a.Mounts != nil {
+		mounts := make([]MountAlertConfig, len(a.Mounts))
+		for i, m := range a.Mounts {
+			m.Labels = cloneLabels(m.Labels)
+			m.Options = append([]string(nil), m.Options...)
+			if m.ReadOnly != nil {
+				readOnly := *m.ReadOnly
+				m.ReadOnly = &readOnly
+			}
+			mounts[i] = m
+		}
+		a.Mounts = mounts
+	}
+	return a
+}
+
+// cloneLabels returns a copy of a label map
+func cloneLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+
+	clone := make(map[string]string, len(labels))
+	for k, v := range labels {
+		clone[k] = v
+	}
+	return clone
+}
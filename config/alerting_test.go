@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate_Alerting(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*AlertingConfig)
+		errMsg string
+	}{
+		{"negative for", func(a *AlertingConfig) { a.For = -time.Minute }, "alerting for cannot be negative"},
+		{"invalid label", func(a *AlertingConfig) { a.Labels = map[string]string{"team-name": "x"} }, `alerting labels: invalid label name "team-name"`},
+		{"severity label", func(a *AlertingConfig) { a.Labels = map[string]string{"severity": "page"} }, "use severity instead"},
+		{"unknown mount", func(a *AlertingConfig) { a.Mounts = []MountAlertConfig{{MountPoint: "/missing"}} }, `alerting mounts entry "/missing" is not a configured mount point`},
+		{"duplicate mount", func(a *AlertingConfig) {
+			a.Mounts = []MountAlertConfig{{MountPoint: "/data"}, {MountPoint: "/data"}}
+		}, "alerting mounts entry /data is configured more than once"},
+		{"invalid mount label", func(a *AlertingConfig) {
+			a.Mounts = []MountAlertConfig{{MountPoint: "/data", Labels: map[string]string{"1x": "y"}}}
+		}, "alerting mounts entry /data labels: invalid label name"},
+		{"empty option", func(a *AlertingConfig) { a.Mounts = []MountAlertConfig{{MountPoint: "/data", Options: []string{""}}} }, "options cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MountPoints = []string{"/data"}
+			tt.modify(&config.Alerting)
+
+			err := config.Validate()
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing '%s', got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	// Per-mount settings may refer to mounts in other namespaces
+	config := DefaultConfig()
+	config.Mounts = []MountConfig{{Path: "/var/lib/postgresql", Container: "db"}}
+	config.Alerting.Mounts = []MountAlertConfig{{MountPoint: "/var/lib/postgresql", Severity: "critical", Options: []string{"noexec"}}}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected valid alerting settings, got %v", err)
+	}
+}
+
+func TestAlertingConfig_MountAlert(t *testing.T) {
+	a := AlertingConfig{Mounts: []MountAlertConfig{{MountPoint: "/data", Severity: "critical"}}}
+
+	if m, ok := a.MountAlert("/data"); !ok || m.Severity != "critical" {
+		t.Errorf("Expected /data settings, got %+v, %v", m, ok)
+	}
+	if _, ok := a.MountAlert("/tmp"); ok {
+		t.Error("Expected no settings for /tmp")
+	}
+}
+
+func TestAlertingConfig_Clone(t *testing.T) {
+	readOnly := true
+	config := DefaultConfig()
+	config.Alerting.Labels = map[string]string{"team": "storage"}
+	config.Alerting.Mounts = []MountAlertConfig{{
+		MountPoint: "/data",
+		Labels:     map[string]string{"tier": "1"},
+		ReadOnly:   &readOnly,
+		Options:    []string{"noexec"},
+	}}
+
+	clone := config.Clone()
+	clone.Alerting.Labels["team"] = "other"
+	clone.Alerting.Mounts[0].Labels["tier"] = "2"
+	*clone.Alerting.Mounts[0].ReadOnly = false
+	clone.Alerting.Mounts[0].Options[0] = "nosuid"
+
+	m := config.Alerting.Mounts[0]
+	if config.Alerting.Labels["team"] != "storage" || m.Labels["tier"] != "1" || !*m.ReadOnly || m.Options[0] != "noexec" {
+		t.Errorf("Clone shares state with the original: %+v", config.Alerting)
+	}
+}
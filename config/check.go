@@ -0,0 +1,343 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlLinePattern extracts the line number from yaml.v3 error messages
+var yamlLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// Problem is an error or warning found in a configuration file. File is set
+// for problems in included files. Line and Column are 1-based and zero when
+// the problem has no location in the file.
+type Problem struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string
+	Message string
+	// Warning is set for unknown keys, which config check reports apart
+	// from errors. LoadFromFile rejects them all the same.
+	Warning bool
+}
+
+// String formats the problem with its location
+func (p Problem) String() string {
+	var s string
+	switch {
+	case p.Line > 0 && p.Column > 0:
+		s = fmt.Sprintf("line %d, column %d: %s", p.Line, p.Column, p.Message)
+	case p.Line > 0:
+		s = fmt.Sprintf("line %d: %s", p.Line, p.Message)
+	default:
+		s = p.Message
+	}
+	if p.File != "" {
+		s = p.File + ": " + s
+	}
+	return s
+}
+
+// DecodeError reports every problem found while decoding a configuration
+// file: syntax errors, unknown keys and values of the wrong type
+type DecodeError struct {
+	Problems []Problem
+}
+
+// Error implements error
+func (e *DecodeError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.String()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// CheckFile loads filename like LoadFromFile and validates it, reporting
+// every problem found instead of stopping at the first one. The returned
+// configuration is nil when the file could not be parsed; an error is only
+// returned when it could not be read.
+func CheckFile(filename string) (*Config, []Problem, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file %s: %w", filename, err)
+	}
+	cfg, problems := check(data, filename)
+	return cfg, problems, nil
+}
+
+// Check parses and validates configuration data, see CheckFile. Included
+// files are not loaded since data has no location to resolve them from.
+func Check(data []byte) (*Config, []Problem) {
+	return check(data, "")
+}
+
+// check implements Check, merging the included files when filename is set
+func check(data []byte, filename string) (*Config, []Problem) {
+	config, root, problems := decode(data)
+	if config == nil {
+		return nil, problems
+	}
+
+	var locations map[string]includeLocation
+	if filename != "" {
+		var includeProblems []Problem
+		locations, includeProblems = config.mergeIncludes(filename, root)
+		problems = append(problems, includeProblems...)
+	}
+
+	if err := config.applyEnvOverrides(); err != nil {
+		problems = append(problems, Problem{Message: fmt.Sprintf("failed to apply environment overrides: %v", err)})
+	}
+
+	for _, fe := range config.ValidateAll() {
+		p, ok := locateIncluded(locations, fe.Path)
+		if !ok {
+			p.Line, p.Column = locate(root, fe.Path)
+		}
+		p.Path, p.Message = fe.Path, fe.Error()
+		problems = append(problems, p)
+	}
+
+	sortProblems(problems)
+	return config, problems
+}
+
+// decode strictly decodes data over the default configuration, reporting
+// syntax errors, unknown keys and values of the wrong type. The
+// configuration is nil when data is not valid YAML.
+func decode(data []byte) (*Config, *yaml.Node, []Problem) {
+	config := DefaultConfig()
+	root, problems := decodeStrict(data, config)
+	if root == nil {
+		return nil, nil, problems
+	}
+	return config, root, problems
+}
+
+// decodeStrict decodes data into out, a pointer to a struct, reporting
+// syntax errors, unknown keys and values of the wrong type. The returned
+// node is nil when data could not be decoded at all.
+func decodeStrict(data []byte, out interface{}) (*yaml.Node, []Problem) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, []Problem{yamlProblem(nil, err.Error())}
+	}
+
+	problems := unknownKeys(&root, reflect.TypeOf(out), "")
+
+	if len(root.Content) > 0 {
+		if err := root.Decode(out); err != nil {
+			var typeErr *yaml.TypeError
+			if !errors.As(err, &typeErr) {
+				return nil, append(problems, yamlProblem(&root, err.Error()))
+			}
+			// Decoding continues past type errors; report them all
+			for _, msg := range typeErr.Errors {
+				problems = append(problems, yamlProblem(&root, msg))
+			}
+		}
+	}
+
+	sortProblems(problems)
+	return &root, problems
+}
+
+// sortProblems orders problems by file, main file first, then by location;
+// problems without a location go last
+func sortProblems(problems []Problem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i], problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if (a.Line == 0) != (b.Line == 0) {
+			return b.Line == 0
+		}
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+}
+
+// yamlProblem turns a yaml.v3 error message into a problem, locating the
+// value on the reported line when root is known
+func yamlProblem(root *yaml.Node, msg string) Problem {
+	m := yamlLinePattern.FindStringSubmatch(msg)
+	if m == nil {
+		return Problem{Message: strings.TrimPrefix(msg, "yaml: ")}
+	}
+
+	line, _ := strconv.Atoi(m[1])
+	p := Problem{Line: line, Message: m[2]}
+	if root != nil {
+		p.Column = valueColumn(root, line)
+	}
+	return p
+}
+
+// valueColumn returns the column of the first scalar value on line, or zero
+func valueColumn(node *yaml.Node, line int) int {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Line == line {
+			return node.Column
+		}
+	case yaml.MappingNode:
+		// Skip keys so the value of "key: value" is found
+		for i := 1; i < len(node.Content); i += 2 {
+			if col := valueColumn(node.Content[i], line); col > 0 {
+				return col
+			}
+		}
+	default:
+		for _, child := range node.Content {
+			if col := valueColumn(child, line); col > 0 {
+				return col
+			}
+		}
+	}
+	return 0
+}
+
+// unknownKeys reports mapping keys under node that no field of t decodes
+func unknownKeys(node *yaml.Node, t reflect.Type, path string) []Problem {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			return unknownKeys(node.Content[0], t, path)
+		}
+	case yaml.AliasNode:
+		return unknownKeys(node.Alias, t, path)
+	case yaml.SequenceNode:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		var problems []Problem
+		for i, item := range node.Content {
+			problems = append(problems, unknownKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return problems
+	case yaml.MappingNode:
+		var problems []Problem
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinPath(path, key.Value)
+
+			switch t.Kind() {
+			case reflect.Map:
+				problems = append(problems, unknownKeys(value, t.Elem(), keyPath)...)
+			case reflect.Struct:
+				field, ok := yamlField(t, key.Value)
+				if !ok {
+					problems = append(problems, Problem{
+						Line:    key.Line,
+						Column:  key.Column,
+						Path:    keyPath,
+						Message: fmt.Sprintf("unknown key %q", keyPath),
+						Warning: true,
+					})
+					continue
+				}
+				problems = append(problems, unknownKeys(value, field.Type, keyPath)...)
+			}
+		}
+		return problems
+	}
+	return nil
+}
+
+// yamlField returns the field of struct t decoded from key
+func yamlField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if yamlName(field) == key {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// yamlName returns the key a struct field is decoded from, or "" when it is
+// skipped
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		// yaml.v3 lowercases untagged field names
+		return strings.ToLower(field.Name)
+	default:
+		return name
+	}
+}
+
+// locate returns the position of the setting at path, such as
+// "server.port" or "mount_points[2]". When the setting is not in the file,
+// the position of its closest enclosing key is returned instead.
+func locate(root *yaml.Node, path string) (int, int) {
+	node := root
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return 0, 0
+		}
+		node = node.Content[0]
+	}
+
+	line, column := 0, 0
+	for _, segment := range splitPath(path) {
+		var next, at *yaml.Node
+		if index, err := strconv.Atoi(segment); err == nil {
+			if node.Kind == yaml.SequenceNode && index < len(node.Content) {
+				next = node.Content[index]
+				at = next
+			}
+		} else if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					at, next = node.Content[i], node.Content[i+1]
+					break
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		line, column = at.Line, at.Column
+		node = next
+	}
+	return line, column
+}
+
+// splitPath splits "a.b[2].c" into "a", "b", "2", "c"
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+	return strings.Split(path, ".")
+}
+
+// joinPath appends key to a dotted path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
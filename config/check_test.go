@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestCheck(t *testing.T) {
+	data := []byte(`server:
+  port: 70000
+  pathh: /m
+intervall: 10s
+mount_points:
+  - /data
+  - relative
+logging:
+  level: verbose
+latency_probe:
+  mount_points: ["/data"]
+  interval: abc
+mount_groups:
+  db: ["/data"]
+`)
+
+	config, problems := Check(data)
+	if config == nil {
+		t.Fatal("Expected a configuration")
+	}
+
+	expected := []Problem{
+		{Line: 2, Column: 3, Path: "server.port", Message: "server port must be between 1 and 65535, got 70000"},
+		{Line: 3, Column: 3, Path: "server.pathh", Message: `unknown key "server.pathh"`, Warning: true},
+		{Line: 4, Column: 1, Path: "intervall", Message: `unknown key "intervall"`, Warning: true},
+		{Line: 7, Column: 5, Path: "mount_points[1]", Message: "mount point must be absolute path, got relative"},
+		{Line: 9, Column: 3, Path: "logging.level", Message: "invalid log level verbose, must be one of: debug, info, warn, error, fatal"},
+		{Line: 12, Column: 13, Message: "cannot unmarshal !!str `abc` into time.Duration"},
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Unexpected problems:\n%+v\nexpected:\n%+v", problems, expected)
+	}
+}
+
+func TestCheck_Valid(t *testing.T) {
+	config, problems := Check([]byte("mount_points: [\"/data\"]\n"))
+	if config == nil || len(config.MountPoints) != 1 {
+		t.Fatalf("Unexpected configuration %+v", config)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected no problems, got %+v", problems)
+	}
+}
+
+func TestCheck_Unlocated(t *testing.T) {
+	// A setting left at an invalid value is reported at its enclosing key
+	_, problems := Check([]byte("drift:\n  enabled: true\n  fstab_path: \"\"\n  systemd_units: false\n"))
+	if len(problems) != 2 {
+		t.Fatalf("Expected two problems, got %+v", problems)
+	}
+	if problems[0].Path != "drift" || problems[0].Line != 1 {
+		t.Errorf("Expected drift error on line 1, got %+v", problems[0])
+	}
+	if problems[1].Path != "mount_points" || problems[1].Line != 0 {
+		t.Errorf("Expected mount_points error without location, got %+v", problems[1])
+	}
+}
+
+func TestCheck_SyntaxError(t *testing.T) {
+	config, problems := Check([]byte("server:\n  port: 8080\n bad: [\n"))
+	if config != nil {
+		t.Error("Expected no configuration for invalid YAML")
+	}
+	if len(problems) != 1 || problems[0].Line == 0 {
+		t.Errorf("Expected a located syntax error, got %+v", problems)
+	}
+}
+
+func TestCheckFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("mount_points: [\"/data\"]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, problems, err := CheckFile(path); err != nil || len(problems) != 0 {
+		t.Errorf("Expected no problems, got %+v, %v", problems, err)
+	}
+
+	if _, _, err := CheckFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestUnknownKeys_Nested(t *testing.T) {
+	var root yaml.Node
+	data := `mounts:
+  - path: /data
+    containr: db
+alerting:
+  labels:
+    anything: goes
+  mounts:
+    - mount_point: /data
+      readonly: true
+`
+	if err := yaml.Unmarshal([]byte(data), &root); err != nil {
+		t.Fatal(err)
+	}
+
+	problems := unknownKeys(&root, reflect.TypeOf(Config{}), "")
+	if len(problems) != 2 {
+		t.Fatalf("Expected two unknown keys, got %+v", problems)
+	}
+	if problems[0].Path != "mounts[0].containr" || problems[0].Line != 3 || problems[0].Column != 5 {
+		t.Errorf("Unexpected problem %+v", problems[0])
+	}
+	if problems[1].Path != "alerting.mounts[0].readonly" || problems[1].Line != 9 {
+		t.Errorf("Unexpected problem %+v", problems[1])
+	}
+	if !problems[0].Warning || !problems[1].Warning {
+		t.Error("Expected unknown keys to be warnings")
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	err := &DecodeError{Problems: []Problem{
+		{Line: 1, Column: 1, Message: `unknown key "intervall"`},
+		{Message: "bad"},
+	}}
+	expected := `line 1, column 1: unknown key "intervall"; bad`
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestProblem_String(t *testing.T) {
+	tests := []struct {
+		problem  Problem
+		expected string
+	}{
+		{Problem{Line: 3, Column: 5, Message: "bad"}, "line 3, column 5: bad"},
+		{Problem{Line: 3, Message: "bad"}, "line 3: bad"},
+		{Problem{Message: "bad"}, "bad"},
+	}
+	for _, tt := range tests {
+		if got := tt.problem.String(); got != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, got)
+		}
+	}
+}
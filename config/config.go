@@ -2,23 +2,66 @@ package config
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/mount-exporter/mount-exporter/recovery"
+	"github.com/mount-exporter/mount-exporter/system"
 	"gopkg.in/yaml.v3"
 )
 
+// logLevels and logFormats are the accepted logging settings
+var (
+	logLevels  = []string{"debug", "info", "warn", "error", "fatal"}
+	logFormats = []string{"json", "text"}
+)
+
 // Config represents the application configuration
 type Config struct {
-	Server      ServerConfig      `yaml:"server"`
-	MountPoints []string          `yaml:"mount_points"`
-	Interval    time.Duration     `yaml:"interval"`
-	Logging     LoggingConfig     `yaml:"logging"`
-	mu          sync.RWMutex      `yaml:"-"`
+	// Include lists glob patterns of files whose mounts are merged in;
+	// relative patterns are resolved against this file's directory
+	Include     []string            `yaml:"include"`
+	Server      ServerConfig        `yaml:"server"`
+	MountPoints []string            `yaml:"mount_points"`
+	MountGroups map[string][]string `yaml:"mount_groups,omitempty"`
+	Mounts      []MountConfig       `yaml:"mounts,omitempty"`
+	// AllowUnknownMounts lets /metrics?mount=... check unconfigured mounts
+	AllowUnknownMounts bool               `yaml:"allow_unknown_mounts"`
+	Interval           time.Duration      `yaml:"interval"`
+	Logging            LoggingConfig      `yaml:"logging"`
+	Recovery           RecoveryConfig     `yaml:"recovery"`
+	Limits             LimitsConfig       `yaml:"limits"`
+	Drift              DriftConfig        `yaml:"drift"`
+	Autofs             AutofsConfig       `yaml:"autofs"`
+	WriteProbe         WriteProbeConfig   `yaml:"write_probe"`
+	LatencyProbe       LatencyProbeConfig `yaml:"latency_probe"`
+	BlockDevices       BlockDevicesConfig `yaml:"block_devices"`
+	NFSStats           NFSStatsConfig     `yaml:"nfs_stats"`
+	Kubernetes         KubernetesConfig   `yaml:"kubernetes"`
+	Alerting           AlertingConfig     `yaml:"alerting"`
+	mu                 sync.RWMutex       `yaml:"-"`
+}
+
+// MountConfig describes a mount point checked in a specific mount namespace.
+// At most one of PID, Namespace and Container may be set; none means the
+// exporter's own namespace.
+type MountConfig struct {
+	Path string `yaml:"path"`
+	// PID of a process in the mount namespace
+	PID int `yaml:"pid,omitempty"`
+	// Namespace is a /proc/<pid>/ns/mnt path
+	Namespace string `yaml:"namespace,omitempty"`
+	// Container name or ID, resolved through process cgroup paths
+	Container string `yaml:"container,omitempty"`
 }
 
 // ServerConfig represents HTTP server configuration
@@ -26,6 +69,17 @@ type ServerConfig struct {
 	Host string `yaml:"host"`
 	Port int    `yaml:"port"`
 	Path string `yaml:"path"`
+	// Timeouts for the HTTP server; zero uses the defaults
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// MaxHeaderBytes bounds request header size; zero uses the default (1 MiB)
+	MaxHeaderBytes int `yaml:"max_header_bytes"`
+	// MaxConcurrentScrapes rejects further scrapes with 503; zero is unlimited
+	MaxConcurrentScrapes int `yaml:"max_concurrent_scrapes"`
+	// DrainPeriod is how long /ready reports 503 before the listener closes
+	DrainPeriod time.Duration `yaml:"drain_period"`
 }
 
 // LoggingConfig represents logging configuration
@@ -34,13 +88,119 @@ type LoggingConfig struct {
 	Format string `yaml:"format"`
 }
 
+// RecoveryConfig represents panic recovery configuration
+type RecoveryConfig struct {
+	// CrashReportDir is the directory crash reports are written to; empty disables them
+	CrashReportDir  string `yaml:"crash_report_dir"`
+	MaxCrashReports int    `yaml:"max_crash_reports"`
+	// MaxPanics is the number of panics tolerated within PanicWindow before
+	// the process exits; zero disables the limit
+	MaxPanics   int           `yaml:"max_panics"`
+	PanicWindow time.Duration `yaml:"panic_window"`
+}
+
+// LimitsConfig represents process resource limits. Exceeding a soft limit
+// is logged; exceeding a hard (max_*) limit refuses new collections and
+// marks the exporter not ready. Zero disables a limit.
+type LimitsConfig struct {
+	SoftMemoryMB   int64         `yaml:"soft_memory_mb"`
+	MaxMemoryMB    int64         `yaml:"max_memory_mb"`
+	SoftGoroutines int64         `yaml:"soft_goroutines"`
+	MaxGoroutines  int64         `yaml:"max_goroutines"`
+	SoftOpenFDs    int64         `yaml:"soft_open_fds"`
+	MaxOpenFDs     int64         `yaml:"max_open_fds"`
+	SampleInterval time.Duration `yaml:"sample_interval"`
+}
+
+// DriftConfig represents fstab and systemd mount unit drift detection
+type DriftConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// FstabPath is the fstab to compare against; empty skips fstab
+	FstabPath string `yaml:"fstab_path"`
+	// SystemdUnits also compares against .mount units in SystemdUnitDirs
+	SystemdUnits    bool     `yaml:"systemd_units"`
+	SystemdUnitDirs []string `yaml:"systemd_unit_dirs"`
+}
+
+// AutofsConfig represents autofs-aware checking. Triggering accesses
+// untriggered autofs paths so the real filesystem is mounted and checked.
+type AutofsConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	Trigger        bool          `yaml:"trigger"`
+	TriggerTimeout time.Duration `yaml:"trigger_timeout"`
+}
+
+// BlockDevicesConfig represents I/O statistics of the block devices backing
+// mount points in the exporter's own mount namespace
+type BlockDevicesConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// NFSStatsConfig represents per-mount NFS client statistics read from
+// /proc/self/mountstats for configured NFS mount points
+type NFSStatsConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// KubernetesConfig represents the enrichment of kubelet volume mounts with
+// pod and PVC labels. Metadata comes from MetadataFile or MetadataURL; with
+// neither, only the pod UID and volume taken from the path are labelled.
+type KubernetesConfig struct {
+	Enabled     bool   `yaml:"enabled"`
+	KubeletRoot string `yaml:"kubelet_root"`
+	// Discover also checks the kubelet volume mounts found in the mount table
+	Discover        bool          `yaml:"discover"`
+	MetadataFile    string        `yaml:"metadata_file"`
+	MetadataURL     string        `yaml:"metadata_url"`
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	Timeout         time.Duration `yaml:"timeout"`
+	// ExcludeKinds skips discovered mounts of these kinds, e.g. "bind"
+	ExcludeKinds []string `yaml:"exclude_kinds"`
+	// ExcludeContainerRuntime skips discovered mounts managed by a container
+	// runtime, such as overlay roots and their bind mounts
+	ExcludeContainerRuntime bool `yaml:"exclude_container_runtime"`
+}
+
+// WriteProbeConfig represents write probes, which periodically create,
+// fsync, read back and remove a file in Directory under each listed mount
+// point. An empty MountPoints list disables write probes.
+type WriteProbeConfig struct {
+	MountPoints []string      `yaml:"mount_points"`
+	Directory   string        `yaml:"directory"`
+	Interval    time.Duration `yaml:"interval"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+// LatencyProbeConfig represents latency probes, which periodically time a
+// stat and a readdir of each listed mount point and, when a canary file is
+// configured for it, a small read of that file. Probes share the findmnt
+// timeout and circuit breaker. An empty MountPoints list disables them.
+type LatencyProbeConfig struct {
+	MountPoints []string      `yaml:"mount_points"`
+	Interval    time.Duration `yaml:"interval"`
+	// Buckets are the histogram buckets in seconds; empty means the defaults
+	Buckets []float64 `yaml:"buckets,omitempty"`
+	// CanaryFiles maps a mount point to a file path relative to it
+	CanaryFiles map[string]string `yaml:"canary_files,omitempty"`
+}
+
+// defaultInclude is the default include pattern, also applied when there is
+// no configuration file
+var defaultInclude = "/etc/mount-exporter/conf.d/*.yaml"
+
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
+		Include: []string{defaultInclude},
 		Server: ServerConfig{
-			Host: "0.0.0.0",
-			Port: 8080,
-			Path: "/metrics",
+			Host:            "0.0.0.0",
+			Port:            8080,
+			Path:            "/metrics",
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+			MaxHeaderBytes:  1 << 20,
 		},
 		MountPoints: []string{},
 		Interval:    30 * time.Second,
@@ -48,27 +208,62 @@ func DefaultConfig() *Config {
 			Level:  "info",
 			Format: "json",
 		},
+		Recovery: RecoveryConfig{
+			MaxCrashReports: 10,
+			PanicWindow:     time.Minute,
+		},
+		Limits: LimitsConfig{
+			SampleInterval: 15 * time.Second,
+		},
+		Drift: DriftConfig{
+			FstabPath:       "/etc/fstab",
+			SystemdUnitDirs: []string{"/etc/systemd/system"},
+		},
+		Autofs: AutofsConfig{
+			TriggerTimeout: 5 * time.Second,
+		},
+		WriteProbe: WriteProbeConfig{
+			Directory: ".mount-exporter",
+			Interval:  time.Minute,
+			Timeout:   10 * time.Second,
+		},
+		LatencyProbe: LatencyProbeConfig{
+			Interval: 30 * time.Second,
+		},
+		Kubernetes: KubernetesConfig{
+			KubeletRoot:     "/var/lib/kubelet",
+			RefreshInterval: time.Minute,
+			Timeout:         5 * time.Second,
+		},
+		Alerting: AlertingConfig{
+			Severity: "warning",
+			For:      5 * time.Minute,
+		},
 	}
 }
 
-// LoadFromFile loads configuration from a YAML file
+// LoadFromFile loads configuration from a YAML file. Without one, the
+// default configuration is returned with the default included files merged
+// in.
 func LoadFromFile(filename string) (*Config, error) {
-	config := DefaultConfig()
-
 	if filename == "" {
-		return config, nil
+		return loadDefault("")
 	}
 
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return config, nil
+			return loadDefault(filename)
 		}
 		return nil, fmt.Errorf("failed to read config file %s: %w", filename, err)
 	}
 
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file %s: %w", filename, err)
+	config, root, problems := decode(data)
+	if len(problems) == 0 {
+		_, problems = config.mergeIncludes(filename, root)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", filename, &DecodeError{Problems: problems})
 	}
 
 	// Apply environment variable overrides
@@ -79,6 +274,17 @@ func LoadFromFile(filename string) (*Config, error) {
 	return config, nil
 }
 
+// loadDefault returns the default configuration with the files matched by
+// the default include pattern merged in, for when filename is missing or
+// no configuration file was found
+func loadDefault(filename string) (*Config, error) {
+	config := DefaultConfig()
+	if _, problems := config.mergeIncludes(filename, &yaml.Node{}); len(problems) > 0 {
+		return nil, fmt.Errorf("failed to load included files: %w", &DecodeError{Problems: problems})
+	}
+	return config, nil
+}
+
 // applyEnvOverrides applies environment variable overrides
 func (c *Config) applyEnvOverrides() error {
 	if host := os.Getenv("MOUNT_EXPORTER_HOST"); host != "" {
@@ -112,54 +318,351 @@ func (c *Config) applyEnvOverrides() error {
 	return nil
 }
 
-// Validate validates the configuration
+// FieldError is a validation error of the setting at Path, e.g.
+// "server.port", "mount_points[2]" or a whole section such as "limits"
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+// Error implements error
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Validate validates the configuration, returning the first error found
 func (c *Config) Validate() error {
+	if errs := c.ValidateAll(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// ValidateAll validates the configuration and returns every error found.
+// Sections with their own validation report at most one error each.
+func (c *Config) ValidateAll() []*FieldError {
+	var errs []*FieldError
+	add := func(path string, err error) {
+		// Sections may report the entry at fault themselves
+		var fe *FieldError
+		switch {
+		case errors.As(err, &fe):
+			errs = append(errs, fe)
+		case err != nil:
+			errs = append(errs, &FieldError{Path: path, Err: err})
+		}
+	}
+
+	for i, pattern := range c.Include {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			add(fmt.Sprintf("include[%d]", i), fmt.Errorf("invalid include pattern %s: %w", pattern, err))
+		}
+	}
+
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
-		return fmt.Errorf("server port must be between 1 and 65535, got %d", c.Server.Port)
+		add("server.port", fmt.Errorf("server port must be between 1 and 65535, got %d", c.Server.Port))
 	}
 
 	if c.Server.Path == "" {
-		return fmt.Errorf("server path cannot be empty")
+		add("server.path", fmt.Errorf("server path cannot be empty"))
+	} else if c.Server.Path[0] != '/' {
+		add("server.path", fmt.Errorf("server path must start with '/', got %s", c.Server.Path))
 	}
 
-	if c.Server.Path[0] != '/' {
-		return fmt.Errorf("server path must start with '/', got %s", c.Server.Path)
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"read_timeout", c.Server.ReadTimeout},
+		{"write_timeout", c.Server.WriteTimeout},
+		{"idle_timeout", c.Server.IdleTimeout},
+		{"shutdown_timeout", c.Server.ShutdownTimeout},
+		{"drain_period", c.Server.DrainPeriod},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			add("server."+d.name, fmt.Errorf("server %s cannot be negative, got %v", d.name, d.value))
+		}
+	}
+
+	if c.Server.MaxHeaderBytes < 0 {
+		add("server.max_header_bytes", fmt.Errorf("server max_header_bytes cannot be negative, got %d", c.Server.MaxHeaderBytes))
+	}
+
+	if c.Server.MaxConcurrentScrapes < 0 {
+		add("server.max_concurrent_scrapes", fmt.Errorf("server max_concurrent_scrapes cannot be negative, got %d", c.Server.MaxConcurrentScrapes))
 	}
 
 	if c.Interval <= 0 {
-		return fmt.Errorf("interval must be positive, got %v", c.Interval)
+		add("interval", fmt.Errorf("interval must be positive, got %v", c.Interval))
 	}
 
-	if len(c.MountPoints) == 0 {
-		return fmt.Errorf("at least one mount point must be configured")
+	if len(c.MountPoints) == 0 && len(c.Mounts) == 0 && !(c.Kubernetes.Enabled && c.Kubernetes.Discover) {
+		add("mount_points", fmt.Errorf("at least one mount point must be configured"))
 	}
 
-	for _, mp := range c.MountPoints {
+	for i, mp := range c.MountPoints {
+		path := fmt.Sprintf("mount_points[%d]", i)
 		if mp == "" {
-			return fmt.Errorf("mount point cannot be empty")
+			add(path, fmt.Errorf("mount point cannot be empty"))
+		} else if mp[0] != '/' {
+			add(path, fmt.Errorf("mount point must be absolute path, got %s", mp))
+		}
+	}
+
+	add("mounts", c.validateMounts())
+	add("mount_groups", c.validateMountGroups())
+
+	if !oneOf(c.Logging.Level, logLevels) {
+		add("logging.level", fmt.Errorf("invalid log level %s, must be one of: %s", c.Logging.Level, strings.Join(logLevels, ", ")))
+	}
+
+	if !oneOf(c.Logging.Format, logFormats) {
+		add("logging.format", fmt.Errorf("invalid log format %s, must be one of: %s", c.Logging.Format, strings.Join(logFormats, ", ")))
+	}
+
+	if c.Recovery.MaxCrashReports < 0 {
+		add("recovery.max_crash_reports", fmt.Errorf("recovery max_crash_reports cannot be negative, got %d", c.Recovery.MaxCrashReports))
+	}
+
+	if c.Recovery.MaxPanics < 0 {
+		add("recovery.max_panics", fmt.Errorf("recovery max_panics cannot be negative, got %d", c.Recovery.MaxPanics))
+	}
+
+	if c.Recovery.MaxPanics > 0 && c.Recovery.PanicWindow <= 0 {
+		add("recovery.panic_window", fmt.Errorf("recovery panic_window must be positive when max_panics is set, got %v", c.Recovery.PanicWindow))
+	}
+
+	add("limits", c.Limits.validate())
+
+	if c.Drift.Enabled && c.Drift.FstabPath == "" && !c.Drift.SystemdUnits {
+		add("drift", fmt.Errorf("drift requires fstab_path or systemd_units when enabled"))
+	}
+
+	if c.Drift.SystemdUnits && len(c.Drift.SystemdUnitDirs) == 0 {
+		add("drift.systemd_unit_dirs", fmt.Errorf("drift systemd_unit_dirs cannot be empty when systemd_units is enabled"))
+	}
+
+	if c.Autofs.Trigger && !c.Autofs.Enabled {
+		add("autofs.trigger", fmt.Errorf("autofs trigger requires autofs to be enabled"))
+	}
+
+	if c.Autofs.Trigger && c.Autofs.TriggerTimeout <= 0 {
+		add("autofs.trigger_timeout", fmt.Errorf("autofs trigger_timeout must be positive when trigger is enabled, got %v", c.Autofs.TriggerTimeout))
+	}
+
+	add("write_probe", c.WriteProbe.validate())
+	add("latency_probe", c.LatencyProbe.validate())
+	add("kubernetes", c.Kubernetes.validate())
+	add("alerting", c.validateAlerting())
+
+	return errs
+}
+
+// validate checks that limits are non-negative and soft limits are below hard ones
+func (l LimitsConfig) validate() error {
+	pairs := []struct {
+		name       string
+		soft, hard int64
+	}{
+		{"memory_mb", l.SoftMemoryMB, l.MaxMemoryMB},
+		{"goroutines", l.SoftGoroutines, l.MaxGoroutines},
+		{"open_fds", l.SoftOpenFDs, l.MaxOpenFDs},
+	}
+
+	for _, p := range pairs {
+		if p.soft < 0 || p.hard < 0 {
+			return fmt.Errorf("limits soft_%s and max_%s cannot be negative", p.name, p.name)
+		}
+		if p.soft > 0 && p.hard > 0 && p.soft > p.hard {
+			return fmt.Errorf("limits soft_%s (%d) cannot exceed max_%s (%d)", p.name, p.soft, p.name, p.hard)
 		}
-		if mp[0] != '/' {
-			return fmt.Errorf("mount point must be absolute path, got %s", mp)
+	}
+
+	if l.SampleInterval < 0 {
+		return fmt.Errorf("limits sample_interval cannot be negative, got %v", l.SampleInterval)
+	}
+
+	return nil
+}
+
+// validate checks write probe mount points, directory and timing
+func (w WriteProbeConfig) validate() error {
+	if len(w.MountPoints) == 0 {
+		return nil
+	}
+
+	for _, mp := range w.MountPoints {
+		if mp == "" || mp[0] != '/' {
+			return fmt.Errorf("write_probe mount point must be absolute path, got %q", mp)
 		}
 	}
 
-	validLogLevels := map[string]bool{
-		"debug": true, "info": true, "warn": true, "error": true, "fatal": true,
+	if w.Directory == "" || filepath.IsAbs(w.Directory) || w.Directory == ".." || strings.HasPrefix(w.Directory, "../") {
+		return fmt.Errorf("write_probe directory must be a relative path inside the mount point, got %q", w.Directory)
+	}
+
+	if w.Interval <= 0 {
+		return fmt.Errorf("write_probe interval must be positive, got %v", w.Interval)
+	}
+
+	if w.Timeout <= 0 || w.Timeout > w.Interval {
+		return fmt.Errorf("write_probe timeout must be positive and at most interval, got %v", w.Timeout)
+	}
+
+	return nil
+}
+
+// clone returns a copy of the write probe configuration
+func (w WriteProbeConfig) clone() WriteProbeConfig {
+	w.MountPoints = append([]string(nil), w.MountPoints...)
+	return w
+}
+
+// validate checks latency probe mount points, buckets and canary files
+func (l LatencyProbeConfig) validate() error {
+	if len(l.MountPoints) == 0 {
+		return nil
+	}
+
+	probed := make(map[string]bool, len(l.MountPoints))
+	for _, mp := range l.MountPoints {
+		if mp == "" || mp[0] != '/' {
+			return fmt.Errorf("latency_probe mount point must be absolute path, got %q", mp)
+		}
+		probed[mp] = true
 	}
-	if !validLogLevels[c.Logging.Level] {
-		return fmt.Errorf("invalid log level %s, must be one of: debug, info, warn, error, fatal", c.Logging.Level)
+
+	if l.Interval <= 0 {
+		return fmt.Errorf("latency_probe interval must be positive, got %v", l.Interval)
 	}
 
-	validFormats := map[string]bool{
-		"json": true, "text": true,
+	for i, b := range l.Buckets {
+		if b <= 0 {
+			return fmt.Errorf("latency_probe buckets must be positive, got %v", b)
+		}
+		if i > 0 && b <= l.Buckets[i-1] {
+			return fmt.Errorf("latency_probe buckets must be strictly increasing, got %v after %v", b, l.Buckets[i-1])
+		}
 	}
-	if !validFormats[c.Logging.Format] {
-		return fmt.Errorf("invalid log format %s, must be one of: json, text", c.Logging.Format)
+
+	for mp, canary := range l.CanaryFiles {
+		if !probed[mp] {
+			return fmt.Errorf("latency_probe canary file configured for %q which is not probed", mp)
+		}
+		if canary == "" || filepath.IsAbs(canary) || canary == ".." || strings.HasPrefix(canary, "../") {
+			return fmt.Errorf("latency_probe canary file for %s must be a relative path inside the mount point, got %q", mp, canary)
+		}
 	}
 
 	return nil
 }
 
+// clone returns a copy of the latency probe configuration
+func (l LatencyProbeConfig) clone() LatencyProbeConfig {
+	l.MountPoints = append([]string(nil), l.MountPoints...)
+	l.Buckets = append([]float64(nil), l.Buckets...)
+	if l.CanaryFiles != nil {
+		canaries := make(map[string]string, len(l.CanaryFiles))
+		for mp, canary := range l.CanaryFiles {
+			canaries[mp] = canary
+		}
+		l.CanaryFiles = canaries
+	}
+	return l
+}
+
+// validate checks the kubelet root, metadata source and refresh timing
+func (k KubernetesConfig) validate() error {
+	if !k.Enabled {
+		return nil
+	}
+
+	if !filepath.IsAbs(k.KubeletRoot) {
+		return fmt.Errorf("kubernetes kubelet_root must be absolute path, got %q", k.KubeletRoot)
+	}
+
+	if k.MetadataFile != "" && k.MetadataURL != "" {
+		return fmt.Errorf("kubernetes metadata_file and metadata_url are mutually exclusive")
+	}
+
+	if k.MetadataURL != "" {
+		u, err := url.Parse(k.MetadataURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("kubernetes metadata_url must be an http or https URL, got %q", k.MetadataURL)
+		}
+	}
+
+	if k.MetadataFile != "" || k.MetadataURL != "" || k.Discover {
+		if k.RefreshInterval <= 0 {
+			return fmt.Errorf("kubernetes refresh_interval must be positive, got %v", k.RefreshInterval)
+		}
+		if k.Timeout <= 0 {
+			return fmt.Errorf("kubernetes timeout must be positive, got %v", k.Timeout)
+		}
+	}
+
+	for _, kind := range k.ExcludeKinds {
+		if _, err := system.ParseMountKind(kind); err != nil {
+			return fmt.Errorf("kubernetes exclude_kinds: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// MountFilter returns the filter applied to discovered mounts
+func (k KubernetesConfig) MountFilter() system.MountFilter {
+	filter := system.MountFilter{ContainerRuntime: k.ExcludeContainerRuntime}
+	for _, name := range k.ExcludeKinds {
+		if kind, err := system.ParseMountKind(name); err == nil {
+			filter.Kinds = append(filter.Kinds, kind)
+		}
+	}
+	return filter
+}
+
+// clone returns a copy of the Kubernetes configuration
+func (k KubernetesConfig) clone() KubernetesConfig {
+	k.ExcludeKinds = append([]string(nil), k.ExcludeKinds...)
+	return k
+}
+
+// clone returns a copy of the drift configuration
+func (d DriftConfig) clone() DriftConfig {
+	d.SystemdUnitDirs = append([]string(nil), d.SystemdUnitDirs...)
+	return d
+}
+
+// oneOf reports whether s is one of values
+func oneOf(s string, values []string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Hash returns a stable SHA-256 fingerprint of the configuration
+func (c *Config) Hash() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 // GetAddress returns the server address
 func (c *Config) GetAddress() string {
 	c.mu.RLock()
@@ -173,17 +676,27 @@ func (c *Config) Clone() *Config {
 	defer c.mu.RUnlock()
 
 	return &Config{
-		Server: ServerConfig{
-			Host: c.Server.Host,
-			Port: c.Server.Port,
-			Path: c.Server.Path,
-		},
-		MountPoints: append([]string{}, c.MountPoints...),
-		Interval:    c.Interval,
+		Include:            append([]string(nil), c.Include...),
+		Server:             c.Server,
+		MountPoints:        append([]string{}, c.MountPoints...),
+		MountGroups:        cloneMountGroups(c.MountGroups),
+		Mounts:             append([]MountConfig(nil), c.Mounts...),
+		AllowUnknownMounts: c.AllowUnknownMounts,
+		Interval:           c.Interval,
 		Logging: LoggingConfig{
 			Level:  c.Logging.Level,
 			Format: c.Logging.Format,
 		},
+		Recovery:     c.Recovery,
+		Limits:       c.Limits,
+		Drift:        c.Drift.clone(),
+		Autofs:       c.Autofs,
+		WriteProbe:   c.WriteProbe.clone(),
+		LatencyProbe: c.LatencyProbe.clone(),
+		BlockDevices: c.BlockDevices,
+		NFSStats:     c.NFSStats,
+		Kubernetes:   c.Kubernetes.clone(),
+		Alerting:     c.Alerting.clone(),
 	}
 }
 
@@ -192,13 +705,28 @@ func (c *Config) Update(newConfig *Config) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.Include = append([]string(nil), newConfig.Include...)
 	c.Server = newConfig.Server
 	c.MountPoints = append([]string{}, newConfig.MountPoints...)
+	c.MountGroups = cloneMountGroups(newConfig.MountGroups)
+	c.Mounts = append([]MountConfig(nil), newConfig.Mounts...)
+	c.AllowUnknownMounts = newConfig.AllowUnknownMounts
 	c.Interval = newConfig.Interval
 	c.Logging = newConfig.Logging
+	c.Recovery = newConfig.Recovery
+	c.Limits = newConfig.Limits
+	c.Drift = newConfig.Drift.clone()
+	c.Autofs = newConfig.Autofs
+	c.WriteProbe = newConfig.WriteProbe.clone()
+	c.LatencyProbe = newConfig.LatencyProbe.clone()
+	c.BlockDevices = newConfig.BlockDevices
+	c.NFSStats = newConfig.NFSStats
+	c.Kubernetes = newConfig.Kubernetes.clone()
+	c.Alerting = newConfig.Alerting.clone()
 }
 
-// ConfigWatcher watches for configuration file changes
+// ConfigWatcher watches for changes of a configuration file and the files
+// it includes
 type ConfigWatcher struct {
 	configPath string
 	config     *Config
@@ -207,6 +735,7 @@ type ConfigWatcher struct {
 	running    bool
 	ctx        context.Context
 	cancel     context.CancelFunc
+	supervisor *recovery.Supervisor
 }
 
 // NewConfigWatcher creates a new configuration watcher
@@ -227,6 +756,14 @@ func (cw *ConfigWatcher) AddCallback(callback func(*Config)) {
 	cw.callbacks = append(cw.callbacks, callback)
 }
 
+// SetSupervisor makes Watch run the watch loop as a supervised worker that
+// is restarted if it panics
+func (cw *ConfigWatcher) SetSupervisor(supervisor *recovery.Supervisor) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	cw.supervisor = supervisor
+}
+
 // Watch starts watching for configuration file changes
 func (cw *ConfigWatcher) Watch(interval time.Duration) error {
 	cw.mu.Lock()
@@ -235,40 +772,65 @@ func (cw *ConfigWatcher) Watch(interval time.Duration) error {
 		return fmt.Errorf("watcher is already running")
 	}
 	cw.running = true
+	supervisor := cw.supervisor
 	cw.mu.Unlock()
 
-	go cw.watchLoop(interval)
+	if supervisor == nil {
+		go cw.watchLoop(cw.ctx, interval)
+		return nil
+	}
+
+	err := supervisor.Go(recovery.WorkerSpec{
+		Name:   "config-watcher",
+		Policy: recovery.RestartOnPanic,
+		Run: func(ctx context.Context) error {
+			cw.watchLoop(ctx, interval)
+			return nil
+		},
+	})
+	if err != nil {
+		cw.mu.Lock()
+		cw.running = false
+		cw.mu.Unlock()
+		return fmt.Errorf("failed to start config watcher: %w", err)
+	}
 	return nil
 }
 
-// watchLoop periodically checks for configuration file changes
-func (cw *ConfigWatcher) watchLoop(interval time.Duration) {
+// watchLoop periodically checks for configuration file changes until ctx
+// is cancelled or the watcher is stopped
+func (cw *ConfigWatcher) watchLoop(ctx context.Context, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	var lastModTime time.Time
-
-	// Get initial modification time
-	if info, err := os.Stat(cw.configPath); err == nil {
-		lastModTime = info.ModTime()
-	}
+	// Modification times of the configuration file and its included files
+	lastModTimes := modTimes(cw.configPath, cw.config)
 
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case <-cw.ctx.Done():
 			return
 		case <-ticker.C:
-			info, err := os.Stat(cw.configPath)
-			if err != nil {
-				continue // File might not exist, skip
+			times := modTimes(cw.configPath, cw.config)
+			// A configuration file that disappears is most likely being
+			// replaced; keep the running configuration until it is back.
+			// Without one from the start, the default includes are watched.
+			if _, ok := times[cw.configPath]; !ok {
+				if _, had := lastModTimes[cw.configPath]; had {
+					continue
+				}
 			}
 
-			if info.ModTime().After(lastModTime) {
-				lastModTime = info.ModTime()
+			if modTimesChanged(lastModTimes, times) {
+				lastModTimes = times
 				if err := cw.reloadConfig(); err != nil {
 					// Log error but continue watching
 					continue
 				}
+				// The reloaded configuration may include other files
+				lastModTimes = modTimes(cw.configPath, cw.config)
 			}
 		}
 	}
@@ -321,4 +883,4 @@ func (cw *ConfigWatcher) IsRunning() bool {
 	cw.mu.RLock()
 	defer cw.mu.RUnlock()
 	return cw.running
-}
\ No newline at end of file
+}
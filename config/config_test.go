@@ -1,9 +1,14 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/mount-exporter/mount-exporter/system"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -138,6 +143,26 @@ path: "/metrics"
 	}
 }
 
+func TestLoadFromFile_UnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "mount_point:\n  - /data\nintervall: 10s\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config content: %v", err)
+	}
+
+	_, err := LoadFromFile(path)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("Expected a DecodeError, got %v", err)
+	}
+	if len(decodeErr.Problems) != 2 || decodeErr.Problems[0].Line != 1 || decodeErr.Problems[1].Line != 3 {
+		t.Errorf("Unexpected problems %+v", decodeErr.Problems)
+	}
+	if !strings.Contains(err.Error(), `line 3, column 1: unknown key "intervall"`) {
+		t.Errorf("Expected the location of the unknown key in %q", err.Error())
+	}
+}
+
 func TestApplyEnvOverrides(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -401,6 +426,135 @@ func TestLoadFromFile_EmptyFile(t *testing.T) {
 	}
 }
 
+func TestValidate_Recovery(t *testing.T) {
+	tests := []struct {
+		name     string
+		recovery RecoveryConfig
+		errMsg   string
+	}{
+		{"negative crash reports", RecoveryConfig{MaxCrashReports: -1}, "max_crash_reports cannot be negative"},
+		{"negative max panics", RecoveryConfig{MaxPanics: -1}, "max_panics cannot be negative"},
+		{"missing panic window", RecoveryConfig{MaxPanics: 3}, "panic_window must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MountPoints = []string{"/data"}
+			config.Recovery = tt.recovery
+
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("Expected validation error")
+			}
+			if !contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing '%s', got '%s'", tt.errMsg, err.Error())
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.MountPoints = []string{"/data"}
+	config.Recovery.MaxPanics = 5
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected default recovery config with max_panics to be valid, got %v", err)
+	}
+}
+
+func TestValidate_Limits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits LimitsConfig
+		errMsg string
+	}{
+		{"negative memory", LimitsConfig{MaxMemoryMB: -1}, "cannot be negative"},
+		{"soft above hard", LimitsConfig{SoftGoroutines: 500, MaxGoroutines: 100}, "soft_goroutines (500) cannot exceed max_goroutines (100)"},
+		{"negative interval", LimitsConfig{SampleInterval: -time.Second}, "sample_interval cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MountPoints = []string{"/data"}
+			config.Limits = tt.limits
+
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("Expected validation error")
+			}
+			if !contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing '%s', got '%s'", tt.errMsg, err.Error())
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.MountPoints = []string{"/data"}
+	config.Limits = LimitsConfig{SoftMemoryMB: 64, MaxMemoryMB: 128, MaxOpenFDs: 1024}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected limits to be valid, got %v", err)
+	}
+}
+
+func TestValidate_ServerLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*ServerConfig)
+		errMsg string
+	}{
+		{"negative read timeout", func(s *ServerConfig) { s.ReadTimeout = -time.Second }, "server read_timeout cannot be negative"},
+		{"negative drain period", func(s *ServerConfig) { s.DrainPeriod = -time.Second }, "server drain_period cannot be negative"},
+		{"negative header size", func(s *ServerConfig) { s.MaxHeaderBytes = -1 }, "server max_header_bytes cannot be negative"},
+		{"negative scrapes", func(s *ServerConfig) { s.MaxConcurrentScrapes = -1 }, "server max_concurrent_scrapes cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MountPoints = []string{"/data"}
+			tt.modify(&config.Server)
+
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("Expected validation error")
+			}
+			if !contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing '%s', got '%s'", tt.errMsg, err.Error())
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.MountPoints = []string{"/data"}
+	config.Server.MaxConcurrentScrapes = 2
+	config.Server.DrainPeriod = 5 * time.Second
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected server limits to be valid, got %v", err)
+	}
+	if clone := config.Clone(); clone.Server != config.Server {
+		t.Errorf("Expected clone to copy server settings, got %+v", clone.Server)
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	a := DefaultConfig()
+	a.MountPoints = []string{"/data"}
+	b := a.Clone()
+
+	if a.Hash() == "" {
+		t.Fatal("Expected non-empty hash")
+	}
+
+	if a.Hash() != b.Hash() {
+		t.Error("Expected identical configurations to have the same hash")
+	}
+
+	b.MountPoints = append(b.MountPoints, "/var")
+	if a.Hash() == b.Hash() {
+		t.Error("Expected different configurations to have different hashes")
+	}
+}
+
 // Helper function to check if a string contains a substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
@@ -413,4 +567,179 @@ func contains(s, substr string) bool {
 			}
 			return false
 		}())))
-}
\ No newline at end of file
+}
+func TestValidate_Drift(t *testing.T) {
+	config := DefaultConfig()
+	config.MountPoints = []string{"/data"}
+	config.Drift.Enabled = true
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected default drift settings to be valid, got %v", err)
+	}
+
+	config.Drift.FstabPath = ""
+	if err := config.Validate(); err == nil || !contains(err.Error(), "drift requires fstab_path or systemd_units") {
+		t.Errorf("Expected missing source error, got %v", err)
+	}
+
+	config.Drift.SystemdUnits = true
+	config.Drift.SystemdUnitDirs = nil
+	if err := config.Validate(); err == nil || !contains(err.Error(), "systemd_unit_dirs cannot be empty") {
+		t.Errorf("Expected empty unit dirs error, got %v", err)
+	}
+
+	config.Drift.SystemdUnitDirs = []string{"/etc/systemd/system"}
+	clone := config.Clone()
+	clone.Drift.SystemdUnitDirs[0] = "/changed"
+	if config.Drift.SystemdUnitDirs[0] != "/etc/systemd/system" {
+		t.Error("Expected clone to deep copy drift unit dirs")
+	}
+}
+
+func TestValidate_Autofs(t *testing.T) {
+	config := DefaultConfig()
+	config.MountPoints = []string{"/net/data"}
+	config.Autofs.Trigger = true
+	if err := config.Validate(); err == nil || !contains(err.Error(), "autofs trigger requires autofs to be enabled") {
+		t.Errorf("Expected trigger without enabled error, got %v", err)
+	}
+
+	config.Autofs.Enabled = true
+	config.Autofs.TriggerTimeout = 0
+	if err := config.Validate(); err == nil || !contains(err.Error(), "autofs trigger_timeout must be positive") {
+		t.Errorf("Expected trigger timeout error, got %v", err)
+	}
+
+	config.Autofs.TriggerTimeout = time.Second
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected autofs settings to be valid, got %v", err)
+	}
+}
+
+func TestValidate_WriteProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*WriteProbeConfig)
+		errMsg string
+	}{
+		{"relative mount point", func(w *WriteProbeConfig) { w.MountPoints = []string{"data"} }, "write_probe mount point must be absolute path"},
+		{"absolute directory", func(w *WriteProbeConfig) { w.Directory = "/tmp" }, "write_probe directory must be a relative path"},
+		{"escaping directory", func(w *WriteProbeConfig) { w.Directory = "../elsewhere" }, "write_probe directory must be a relative path"},
+		{"timeout above interval", func(w *WriteProbeConfig) { w.Timeout = 2 * w.Interval }, "write_probe timeout must be positive and at most interval"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MountPoints = []string{"/data"}
+			config.WriteProbe.MountPoints = []string{"/data"}
+			tt.modify(&config.WriteProbe)
+
+			err := config.Validate()
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing '%s', got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.MountPoints = []string{"/data"}
+	config.WriteProbe.MountPoints = []string{"/data"}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected write probe settings to be valid, got %v", err)
+	}
+}
+
+func TestValidate_LatencyProbe(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*LatencyProbeConfig)
+		errMsg string
+	}{
+		{"relative mount point", func(l *LatencyProbeConfig) { l.MountPoints = []string{"data"} }, "latency_probe mount point must be absolute path"},
+		{"zero interval", func(l *LatencyProbeConfig) { l.Interval = 0 }, "latency_probe interval must be positive"},
+		{"negative bucket", func(l *LatencyProbeConfig) { l.Buckets = []float64{-1} }, "latency_probe buckets must be positive"},
+		{"unsorted buckets", func(l *LatencyProbeConfig) { l.Buckets = []float64{0.1, 0.05} }, "latency_probe buckets must be strictly increasing"},
+		{"canary for unprobed mount", func(l *LatencyProbeConfig) { l.CanaryFiles = map[string]string{"/other": "canary"} }, "which is not probed"},
+		{"escaping canary", func(l *LatencyProbeConfig) { l.CanaryFiles = map[string]string{"/data": "../canary"} }, "latency_probe canary file for /data must be a relative path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MountPoints = []string{"/data"}
+			config.LatencyProbe.MountPoints = []string{"/data"}
+			tt.modify(&config.LatencyProbe)
+
+			err := config.Validate()
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing '%s', got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.MountPoints = []string{"/data"}
+	config.LatencyProbe.MountPoints = []string{"/data"}
+	config.LatencyProbe.Buckets = []float64{0.001, 0.01, 0.1, 1}
+	config.LatencyProbe.CanaryFiles = map[string]string{"/data": ".canary"}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected latency probe settings to be valid, got %v", err)
+	}
+
+	clone := config.Clone()
+	clone.LatencyProbe.CanaryFiles["/data"] = "changed"
+	clone.LatencyProbe.Buckets[0] = 0.5
+	if config.LatencyProbe.CanaryFiles["/data"] != ".canary" || config.LatencyProbe.Buckets[0] != 0.001 {
+		t.Error("Expected Clone to deep copy latency probe settings")
+	}
+}
+
+func TestValidate_Kubernetes(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*KubernetesConfig)
+		errMsg string
+	}{
+		{"relative kubelet root", func(k *KubernetesConfig) { k.KubeletRoot = "kubelet" }, "kubernetes kubelet_root must be absolute path"},
+		{"file and url", func(k *KubernetesConfig) {
+			k.MetadataFile = "/etc/pods.json"
+			k.MetadataURL = "http://localhost/pods"
+		}, "mutually exclusive"},
+		{"invalid url", func(k *KubernetesConfig) { k.MetadataURL = "ftp://pods" }, "kubernetes metadata_url must be an http or https URL"},
+		{"zero refresh", func(k *KubernetesConfig) {
+			k.Discover = true
+			k.RefreshInterval = 0
+		}, "kubernetes refresh_interval must be positive"},
+		{"unknown kind", func(k *KubernetesConfig) { k.ExcludeKinds = []string{"loop"} }, "kubernetes exclude_kinds: unknown mount kind"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MountPoints = []string{"/data"}
+			config.Kubernetes.Enabled = true
+			tt.modify(&config.Kubernetes)
+
+			err := config.Validate()
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing '%s', got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	// Discovery alone is enough to have something to check
+	config := DefaultConfig()
+	config.Kubernetes.Enabled = true
+	config.Kubernetes.Discover = true
+	config.Kubernetes.MetadataURL = "http://127.0.0.1:10255/pods"
+	config.Kubernetes.ExcludeKinds = []string{"tmpfs", "bind"}
+	config.Kubernetes.ExcludeContainerRuntime = true
+	if err := config.Validate(); err != nil {
+		t.Errorf("Expected Kubernetes discovery settings to be valid, got %v", err)
+	}
+
+	filter := config.Kubernetes.MountFilter()
+	if len(filter.Kinds) != 2 || filter.Kinds[0] != system.MountKindTmpfs || filter.Kinds[1] != system.MountKindBind || !filter.ContainerRuntime {
+		t.Errorf("Unexpected mount filter: %+v", filter)
+	}
+}
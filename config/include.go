@@ -0,0 +1,281 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeFragment holds the settings an included file may contain. They
+// are merged into the main configuration; everything else can only be set
+// in the main configuration file.
+type includeFragment struct {
+	MountPoints []string            `yaml:"mount_points"`
+	Mounts      []MountConfig       `yaml:"mounts"`
+	MountGroups map[string][]string `yaml:"mount_groups"`
+	Alerting    struct {
+		Mounts []MountAlertConfig `yaml:"mounts"`
+	} `yaml:"alerting"`
+}
+
+// includeLocation is where a merged definition was made: the included file,
+// its parsed content and the path of the definition within it
+type includeLocation struct {
+	file string
+	node *yaml.Node
+	path string
+}
+
+// locateIncluded returns the position of the setting at path, such as
+// "mounts[3].pid", when it was merged from an included file
+func locateIncluded(locations map[string]includeLocation, path string) (Problem, bool) {
+	// The longest prefix wins, since mount group names may contain dots
+	match := ""
+	for prefix := range locations {
+		rest, ok := strings.CutPrefix(path, prefix)
+		if ok && (rest == "" || rest[0] == '.' || rest[0] == '[') && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	if match == "" {
+		return Problem{}, false
+	}
+
+	loc := locations[match]
+	line, column := locate(loc.node, loc.path+strings.TrimPrefix(path, match))
+	return Problem{File: loc.file, Line: line, Column: column}, true
+}
+
+// mountGroupKey and mountAlertKey identify merged definitions next to
+// MountConfig values
+type (
+	mountGroupKey string
+	mountAlertKey string
+)
+
+// IncludedFiles returns the files matched by the include patterns in merge
+// order. Relative patterns are resolved against the directory of filename,
+// the main configuration file, which is never included again. Without a
+// filename, only absolute patterns are resolved.
+func (c *Config) IncludedFiles(filename string) ([]string, error) {
+	c.mu.RLock()
+	patterns := append([]string(nil), c.Include...)
+	c.mu.RUnlock()
+
+	var files []string
+	seen := make(map[string]bool)
+	if filename != "" {
+		seen[filepath.Clean(filename)] = true
+	}
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			if filename == "" {
+				continue
+			}
+			pattern = filepath.Join(filepath.Dir(filename), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 && !hasGlobMeta(pattern) {
+			return nil, fmt.Errorf("included file %s does not exist", pattern)
+		}
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
+
+// hasGlobMeta reports whether pattern contains glob metacharacters
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// mergeIncludes merges the mounts, mount groups and mount alerts of the
+// included files into c. root is the parsed main configuration file, used
+// to locate earlier definitions. Definitions already made in the main file
+// or an earlier included file are rejected. The returned locations map the
+// paths of merged definitions, such as "mount_points[3]" or
+// "mount_groups.db", to where they were defined.
+func (c *Config) mergeIncludes(filename string, root *yaml.Node) (map[string]includeLocation, []Problem) {
+	files, err := c.IncludedFiles(filename)
+	if err != nil {
+		line, column := locate(root, "include")
+		return nil, []Problem{{Line: line, Column: column, Path: "include", Message: err.Error()}}
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	at := func(file string, node *yaml.Node, path string) Problem {
+		line, column := locate(node, path)
+		return Problem{File: file, Line: line, Column: column, Path: path}
+	}
+
+	// Definitions of the main file; duplicates within it are left to
+	// validation
+	defined := make(map[interface{}]Problem)
+	define := func(key interface{}, p Problem) {
+		if _, ok := defined[key]; !ok {
+			defined[key] = p
+		}
+	}
+	for i, mp := range c.MountPoints {
+		define(MountConfig{Path: mp}, at(filename, root, fmt.Sprintf("mount_points[%d]", i)))
+	}
+	for i, m := range c.Mounts {
+		define(m, at(filename, root, fmt.Sprintf("mounts[%d]", i)))
+	}
+	for name := range c.MountGroups {
+		define(mountGroupKey(name), at(filename, root, "mount_groups."+name))
+	}
+	for i, a := range c.Alerting.Mounts {
+		define(mountAlertKey(a.MountPoint), at(filename, root, fmt.Sprintf("alerting.mounts[%d]", i)))
+	}
+
+	locations := make(map[string]includeLocation)
+	var problems []Problem
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			problems = append(problems, Problem{File: file, Message: fmt.Sprintf("failed to read included file: %v", err)})
+			continue
+		}
+
+		var fragment includeFragment
+		node, fileProblems := decodeStrict(data, &fragment)
+		for _, p := range fileProblems {
+			p.File = file
+			if strings.HasPrefix(p.Message, "unknown key") && configHasPath(p.Path) {
+				p.Message = fmt.Sprintf("%q can only be set in the main configuration file", p.Path)
+				p.Warning = false
+			}
+			problems = append(problems, p)
+		}
+		if node == nil || len(fileProblems) > 0 {
+			continue
+		}
+
+		// add records a definition, reporting it when it was made before
+		add := func(key interface{}, what, path string) bool {
+			here := at(file, node, path)
+			if prev, ok := defined[key]; ok {
+				here.Message = fmt.Sprintf("%s is already defined at %s", what, prev.position())
+				problems = append(problems, here)
+				return false
+			}
+			defined[key] = here
+			return true
+		}
+
+		for i, mp := range fragment.MountPoints {
+			path := fmt.Sprintf("mount_points[%d]", i)
+			if add(MountConfig{Path: mp}, "mount point "+mp, path) {
+				locations[fmt.Sprintf("mount_points[%d]", len(c.MountPoints))] = includeLocation{file, node, path}
+				c.MountPoints = append(c.MountPoints, mp)
+			}
+		}
+		for i, m := range fragment.Mounts {
+			path := fmt.Sprintf("mounts[%d]", i)
+			if add(m, "mount point "+m.Path, path) {
+				locations[fmt.Sprintf("mounts[%d]", len(c.Mounts))] = includeLocation{file, node, path}
+				c.Mounts = append(c.Mounts, m)
+			}
+		}
+		for name, paths := range fragment.MountGroups {
+			path := "mount_groups." + name
+			if add(mountGroupKey(name), "mount group "+name, path) {
+				locations[path] = includeLocation{file, node, path}
+				if c.MountGroups == nil {
+					c.MountGroups = make(map[string][]string)
+				}
+				c.MountGroups[name] = paths
+			}
+		}
+		for i, a := range fragment.Alerting.Mounts {
+			path := fmt.Sprintf("alerting.mounts[%d]", i)
+			if add(mountAlertKey(a.MountPoint), "alerting for mount point "+a.MountPoint, path) {
+				locations[fmt.Sprintf("alerting.mounts[%d]", len(c.Alerting.Mounts))] = includeLocation{file, node, path}
+				c.Alerting.Mounts = append(c.Alerting.Mounts, a)
+			}
+		}
+	}
+
+	sortProblems(problems)
+	return locations, problems
+}
+
+// position formats the file and line of a problem, such as "config.yaml:4"
+func (p Problem) position() string {
+	if p.Line == 0 {
+		return p.File
+	}
+	return p.File + ":" + strconv.Itoa(p.Line)
+}
+
+// configHasPath reports whether path, such as "server.port", is a setting
+// of the main configuration file
+func configHasPath(path string) bool {
+	t := reflect.TypeOf(Config{})
+	for _, segment := range splitPath(path) {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			field, ok := yamlField(t, segment)
+			if !ok {
+				return false
+			}
+			t = field.Type
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// modTimes returns the modification times of a configuration file and the
+// files it includes. A missing configuration file is left out, since the
+// included files still apply without it.
+func modTimes(filename string, config *Config) map[string]time.Time {
+	times := make(map[string]time.Time)
+	if info, err := os.Stat(filename); err == nil {
+		times[filename] = info.ModTime()
+	}
+
+	// Unresolvable includes are reported when the configuration is reloaded
+	files, _ := config.IncludedFiles(filename)
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			times[file] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// modTimesChanged reports whether files were added, removed or modified
+func modTimesChanged(before, after map[string]time.Time) bool {
+	if len(before) != len(after) {
+		return true
+	}
+	for file, t := range after {
+		if prev, ok := before[file]; !ok || !prev.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,335 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfigFiles writes files relative to a temporary directory and
+// returns the path of config.yaml in it
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "config.yaml")
+}
+
+func TestLoadFromFile_Include(t *testing.T) {
+	path := writeConfigFiles(t, map[string]string{
+		"config.yaml": "include: [conf.d/*.yaml]\nmount_points:\n  - /data\nalerting:\n  severity: critical\n",
+		"conf.d/a-db.yaml": `mount_points:
+  - /var/lib/postgresql
+mount_groups:
+  databases: ["/data", "/var/lib/postgresql"]
+alerting:
+  mounts:
+    - mount_point: /var/lib/postgresql
+      severity: page
+`,
+		"conf.d/b-backup.yaml": "mounts:\n  - path: /backup\n    pid: 1\n",
+		"conf.d/ignored.yml":   "mount_points: [/ignored]\n",
+	})
+
+	config, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if expected := []string{"/data", "/var/lib/postgresql"}; !reflect.DeepEqual(config.MountPoints, expected) {
+		t.Errorf("Expected mount points %v, got %v", expected, config.MountPoints)
+	}
+	if len(config.Mounts) != 1 || config.Mounts[0].Path != "/backup" {
+		t.Errorf("Unexpected mounts %+v", config.Mounts)
+	}
+	if len(config.MountGroups["databases"]) != 2 {
+		t.Errorf("Unexpected mount groups %v", config.MountGroups)
+	}
+	if config.Alerting.Severity != "critical" || len(config.Alerting.Mounts) != 1 {
+		t.Errorf("Unexpected alerting %+v", config.Alerting)
+	}
+	if err := config.Validate(); err != nil {
+		t.Errorf("Merged configuration should be valid: %v", err)
+	}
+}
+
+func TestLoadFromFile_IncludeConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		included string
+		errMsg   string
+	}{
+		{
+			name:     "mount point",
+			included: "mount_points:\n  - /srv\n  - /data\n",
+			errMsg:   "line 3, column 5: mount point /data is already defined at ",
+		},
+		{
+			name:     "mount group",
+			included: "mount_groups:\n  shared: [/data]\n",
+			errMsg:   "line 2, column 3: mount group shared is already defined at ",
+		},
+		{
+			name:     "mount alert",
+			included: "alerting:\n  mounts:\n    - mount_point: /data\n",
+			errMsg:   "line 3, column 7: alerting for mount point /data is already defined at ",
+		},
+		{
+			name:     "main file setting",
+			included: "server:\n  port: 9090\n",
+			errMsg:   `line 1, column 1: "server" can only be set in the main configuration file`,
+		},
+		{
+			name:     "unknown key",
+			included: "mount_point: [/srv]\n",
+			errMsg:   `line 1, column 1: unknown key "mount_point"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFiles(t, map[string]string{
+				"config.yaml": `include: [conf.d/*.yaml]
+mount_points:
+  - /data
+mount_groups:
+  shared: [/data]
+alerting:
+  mounts:
+    - mount_point: /data
+`,
+				"conf.d/team.yaml": tt.included,
+			})
+
+			_, err := LoadFromFile(path)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			included := filepath.Join(filepath.Dir(path), "conf.d", "team.yaml")
+			if !strings.Contains(err.Error(), included+": "+tt.errMsg) {
+				t.Errorf("Expected error to contain %q, got %q", included+": "+tt.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestLoadFromFile_IncludeConflictLocation(t *testing.T) {
+	path := writeConfigFiles(t, map[string]string{
+		"config.yaml":   "include: [a.yaml, b.yaml]\n",
+		"a.yaml":        "mounts:\n  - path: /srv\n    pid: 1\n",
+		"b.yaml":        "mounts:\n  - path: /srv\n    pid: 2\n  - path: /srv\n    pid: 1\n",
+		"conf.d/x.yaml": "mount_points: [/x]\n",
+	})
+
+	config, problems, err := CheckFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Dir(path)
+	expected := []Problem{{
+		File:    filepath.Join(dir, "b.yaml"),
+		Line:    4,
+		Column:  5,
+		Path:    "mounts[1]",
+		Message: "mount point /srv is already defined at " + filepath.Join(dir, "a.yaml") + ":2",
+	}}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Unexpected problems:\n%+v\nexpected:\n%+v", problems, expected)
+	}
+	// Only the listed files are included
+	if len(config.Mounts) != 2 || len(config.MountPoints) != 0 {
+		t.Errorf("Unexpected merged mounts %+v %v", config.Mounts, config.MountPoints)
+	}
+}
+
+func TestCheckFile_IncludeValidationLocation(t *testing.T) {
+	path := writeConfigFiles(t, map[string]string{
+		"config.yaml":      "include: [conf.d/*.yaml]\nmount_points:\n  - /data\n",
+		"conf.d/team.yaml": "\nmount_points:\n  - relative\n",
+	})
+
+	_, problems, err := CheckFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 {
+		t.Fatalf("Expected one problem, got %+v", problems)
+	}
+	p := problems[0]
+	if p.File != filepath.Join(filepath.Dir(path), "conf.d", "team.yaml") || p.Line != 3 || p.Path != "mount_points[1]" {
+		t.Errorf("Expected the problem in the included file, got %+v", p)
+	}
+}
+
+func TestCheckFile_IncludeSectionLocations(t *testing.T) {
+	tests := []struct {
+		name     string
+		included string
+		path     string
+		line     int
+		column   int
+	}{
+		{
+			name:     "mounts",
+			included: "mounts:\n  - path: /srv\n    pid: -1\n",
+			path:     "mounts[1]",
+			line:     2,
+			column:   5,
+		},
+		{
+			name:     "mount group",
+			included: "mount_groups:\n  team:\n    - /data\n    - /missing\n",
+			path:     "mount_groups.team[1]",
+			line:     4,
+			column:   7,
+		},
+		{
+			name:     "mount alert",
+			included: "alerting:\n  mounts:\n    - mount_point: /missing\n",
+			path:     "alerting.mounts[0]",
+			line:     3,
+			column:   7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFiles(t, map[string]string{
+				"config.yaml":      "include: [conf.d/*.yaml]\nmount_points: [/data]\nmounts:\n  - path: /backup\n    pid: 1\n",
+				"conf.d/team.yaml": tt.included,
+			})
+
+			_, problems, err := CheckFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(problems) != 1 {
+				t.Fatalf("Expected one problem, got %+v", problems)
+			}
+			p := problems[0]
+			if p.File != filepath.Join(filepath.Dir(path), "conf.d", "team.yaml") || p.Path != tt.path || p.Line != tt.line || p.Column != tt.column {
+				t.Errorf("Expected the problem at %s line %d, column %d of the included file, got %+v", tt.path, tt.line, tt.column, p)
+			}
+		})
+	}
+}
+
+func TestDefaultConfig_Include(t *testing.T) {
+	// The default must not depend on where the configuration file is
+	for _, pattern := range DefaultConfig().Include {
+		if !filepath.IsAbs(pattern) {
+			t.Errorf("Expected an absolute default include pattern, got %s", pattern)
+		}
+	}
+}
+
+func TestLoadFromFile_IncludeOnly(t *testing.T) {
+	main := writeConfigFiles(t, map[string]string{
+		"conf.d/team.yaml":  "mount_points: [/srv]\n",
+		"conf.d/other.yaml": "mounts:\n  - path: /var/lib/kubelet\n    namespace: /proc/1/ns/mnt\n",
+	})
+	defer func(pattern string) { defaultInclude = pattern }(defaultInclude)
+	defaultInclude = filepath.Join(filepath.Dir(main), "conf.d", "*.yaml")
+
+	// Both without a configuration file and with a missing one
+	for _, filename := range []string{"", main} {
+		config, err := LoadFromFile(filename)
+		if err != nil {
+			t.Fatalf("Failed to load config %q: %v", filename, err)
+		}
+		if !reflect.DeepEqual(config.MountPoints, []string{"/srv"}) {
+			t.Errorf("Expected the mount points of conf.d, got %v", config.MountPoints)
+		}
+		if len(config.Mounts) != 1 || config.Mounts[0].Path != "/var/lib/kubelet" {
+			t.Errorf("Expected the mounts of conf.d, got %+v", config.Mounts)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(filepath.Dir(main), "conf.d", "bad.yaml"), []byte("server:\n  port: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFromFile(main); err == nil {
+		t.Error("Expected an error for an included file setting a main file key")
+	}
+}
+
+func TestIncludedFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"config.yaml", "b.yaml", "a.yaml", "extra/c.yaml"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	main := filepath.Join(dir, "config.yaml")
+
+	config := &Config{Include: []string{"*.yaml", "b.yaml", filepath.Join(dir, "extra", "*.yaml"), "none/*.yaml"}}
+	files, err := config.IncludedFiles(main)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yaml"), filepath.Join(dir, "extra", "c.yaml")}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+
+	// Without a configuration file, relative patterns have nothing to resolve against
+	files, err = config.IncludedFiles("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := []string{filepath.Join(dir, "extra", "c.yaml")}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+
+	config.Include = []string{"missing.yaml"}
+	if _, err := config.IncludedFiles(main); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected an error for a missing included file, got %v", err)
+	}
+
+	config.Include = []string{"["}
+	if _, err := config.IncludedFiles(main); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	if err := config.ValidateAll(); len(err) == 0 || err[0].Path != "include[0]" {
+		t.Errorf("Expected an include validation error, got %v", err)
+	}
+}
+
+func TestModTimesChanged(t *testing.T) {
+	now := time.Now()
+	before := map[string]time.Time{"a": now, "b": now}
+
+	tests := []struct {
+		name    string
+		after   map[string]time.Time
+		changed bool
+	}{
+		{"unchanged", map[string]time.Time{"a": now, "b": now}, false},
+		{"modified", map[string]time.Time{"a": now, "b": now.Add(time.Second)}, true},
+		{"added", map[string]time.Time{"a": now, "b": now, "c": now}, true},
+		{"removed", map[string]time.Time{"a": now}, true},
+		{"replaced", map[string]time.Time{"a": now, "c": now}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modTimesChanged(before, tt.after); got != tt.changed {
+				t.Errorf("Expected %v, got %v", tt.changed, got)
+			}
+		})
+	}
+}
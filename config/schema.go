@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/system"
+)
+
+// durationPattern matches the Go duration strings accepted for durations
+const durationPattern = `^(0|-?([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+$`
+
+// schemaEnums restricts settings to fixed values, keyed by path. "[]"
+// stands for the items of a list.
+var schemaEnums = map[string][]string{
+	"logging.level":              logLevels,
+	"logging.format":             logFormats,
+	"kubernetes.exclude_kinds[]": system.MountKindNames(),
+}
+
+// durationType is decoded from duration strings such as "30s"
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Schema returns the JSON Schema of the configuration file, generated from
+// the Config types with DefaultConfig values as defaults
+func Schema() ([]byte, error) {
+	schema := schemaFor(reflect.TypeOf(Config{}), reflect.ValueOf(DefaultConfig()).Elem(), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "mount-exporter configuration"
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaFor returns the schema of values of type t at path. def holds the
+// default value and is invalid when there is none.
+func schemaFor(t reflect.Type, def reflect.Value, path string) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		def = reflect.Value{}
+	}
+
+	schema := make(map[string]interface{})
+	switch {
+	case t == durationType:
+		schema["type"] = "string"
+		schema["pattern"] = durationPattern
+		if def.IsValid() && !def.IsZero() {
+			schema["default"] = time.Duration(def.Int()).String()
+		}
+		return schema
+	case t.Kind() == reflect.Struct:
+		schema["type"] = "object"
+		schema["additionalProperties"] = false
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := yamlName(field)
+			if !field.IsExported() || name == "" {
+				continue
+			}
+			var fieldDef reflect.Value
+			if def.IsValid() {
+				fieldDef = def.Field(i)
+			}
+			properties[name] = schemaFor(field.Type, fieldDef, joinPath(path, name))
+		}
+		schema["properties"] = properties
+		return schema
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		schema["type"] = "array"
+		schema["items"] = schemaFor(t.Elem(), reflect.Value{}, path+"[]")
+	case t.Kind() == reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = schemaFor(t.Elem(), reflect.Value{}, path+".*")
+	case t.Kind() == reflect.Bool:
+		schema["type"] = "boolean"
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		schema["type"] = "integer"
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		schema["type"] = "number"
+	case t.Kind() == reflect.String:
+		schema["type"] = "string"
+	}
+
+	if values := schemaEnums[path]; values != nil {
+		schema["enum"] = values
+	}
+	if def.IsValid() && !def.IsZero() && (def.Kind() != reflect.Slice || def.Len() > 0) {
+		schema["default"] = def.Interface()
+	}
+	return schema
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	if schema["$schema"] != "https://json-schema.org/draft/2020-12/schema" {
+		t.Errorf("Unexpected $schema %v", schema["$schema"])
+	}
+	if schema["additionalProperties"] != false {
+		t.Error("Expected unknown top-level keys to be rejected")
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		name := yamlName(configType.Field(i))
+		if !configType.Field(i).IsExported() || name == "" {
+			continue
+		}
+		if _, ok := properties[name]; !ok {
+			t.Errorf("Expected property %s", name)
+		}
+	}
+	if _, ok := properties["mu"]; ok {
+		t.Error("Unexported fields must not be in the schema")
+	}
+
+	interval := properties["interval"].(map[string]interface{})
+	if interval["type"] != "string" || interval["default"] != "30s" {
+		t.Errorf("Unexpected interval schema %v", interval)
+	}
+
+	server := properties["server"].(map[string]interface{})["properties"].(map[string]interface{})
+	port := server["port"].(map[string]interface{})
+	if port["type"] != "integer" || port["default"] != float64(8080) {
+		t.Errorf("Unexpected port schema %v", port)
+	}
+
+	logging := properties["logging"].(map[string]interface{})["properties"].(map[string]interface{})
+	level := logging["level"].(map[string]interface{})
+	if !reflect.DeepEqual(level["enum"], []interface{}{"debug", "info", "warn", "error", "fatal"}) {
+		t.Errorf("Unexpected log level enum %v", level["enum"])
+	}
+
+	groups := properties["mount_groups"].(map[string]interface{})
+	if groups["type"] != "object" || groups["additionalProperties"].(map[string]interface{})["type"] != "array" {
+		t.Errorf("Unexpected mount_groups schema %v", groups)
+	}
+}
+
+func TestSchema_DurationPattern(t *testing.T) {
+	pattern := regexp.MustCompile(durationPattern)
+	for _, s := range []string{"0", "30s", "1h30m", "1.5s", "250ms", "10µs"} {
+		if !pattern.MatchString(s) {
+			t.Errorf("Expected %q to match", s)
+		}
+	}
+	for _, s := range []string{"", "30", "abc", "s", "1d"} {
+		if pattern.MatchString(s) {
+			t.Errorf("Expected %q not to match", s)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// namespacePathPattern matches /proc/<pid>/ns/mnt
+var namespacePathPattern = regexp.MustCompile(`^/proc/[1-9][0-9]*/ns/mnt$`)
+
+var (
+	// ErrUnknownMountPoint is returned when a requested mount point is not configured
+	ErrUnknownMountPoint = errors.New("mount point is not configured")
+	// ErrUnknownMountGroup is returned when a requested mount group does not exist
+	ErrUnknownMountGroup = errors.New("mount group is not configured")
+)
+
+// ResolveMountPoints returns the mount points selected by the given mount
+// points and group names, in configuration order followed by any unknown
+// mount points allowed by allow_unknown_mounts. Duplicates are removed.
+func (c *Config) ResolveMountPoints(mounts, groups []string) ([]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	configured := make(map[string]bool, len(c.MountPoints)+len(c.Mounts))
+	for _, m := range c.allMounts() {
+		configured[m.Path] = true
+	}
+
+	selected := make(map[string]bool)
+	var extra []string
+
+	for _, group := range groups {
+		members, ok := c.MountGroups[group]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMountGroup, group)
+		}
+		for _, mp := range members {
+			selected[mp] = true
+		}
+	}
+
+	for _, mp := range mounts {
+		if configured[mp] {
+			selected[mp] = true
+			continue
+		}
+		if !c.AllowUnknownMounts {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownMountPoint, mp)
+		}
+		if mp == "" || mp[0] != '/' {
+			return nil, fmt.Errorf("mount point must be absolute path, got %q", mp)
+		}
+		if !selected[mp] {
+			selected[mp] = true
+			extra = append(extra, mp)
+		}
+	}
+
+	result := make([]string, 0, len(selected))
+	for _, m := range c.allMounts() {
+		if selected[m.Path] {
+			result = append(result, m.Path)
+			delete(selected, m.Path)
+		}
+	}
+
+	return append(result, extra...), nil
+}
+
+// validateMountGroups checks that groups are named and only reference
+// configured mount points
+func (c *Config) validateMountGroups() error {
+	configured := make(map[string]bool, len(c.MountPoints)+len(c.Mounts))
+	for _, m := range c.allMounts() {
+		configured[m.Path] = true
+	}
+
+	names := make([]string, 0, len(c.MountGroups))
+	for name := range c.MountGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := "mount_groups." + name
+		if name == "" {
+			return &FieldError{Path: "mount_groups", Err: fmt.Errorf("mount group name cannot be empty")}
+		}
+		if len(c.MountGroups[name]) == 0 {
+			return &FieldError{Path: path, Err: fmt.Errorf("mount group %s must contain at least one mount point", name)}
+		}
+		for i, mp := range c.MountGroups[name] {
+			if !configured[mp] {
+				return &FieldError{Path: fmt.Sprintf("%s[%d]", path, i), Err: fmt.Errorf("mount group %s references unconfigured mount point %s", name, mp)}
+			}
+		}
+	}
+
+	return nil
+}
+
+// cloneMountGroups returns a deep copy of the mount groups
+func cloneMountGroups(groups map[string][]string) map[string][]string {
+	if groups == nil {
+		return nil
+	}
+
+	clone := make(map[string][]string, len(groups))
+	for name, members := range groups {
+		clone[name] = append([]string{}, members...)
+	}
+	return clone
+}
+
+// AllMounts returns mount_points, as mounts in the exporter's namespace,
+// followed by the entries of mounts
+func (c *Config) AllMounts() []MountConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.allMounts()
+}
+
+// allMounts is AllMounts without locking
+func (c *Config) allMounts() []MountConfig {
+	mounts := make([]MountConfig, 0, len(c.MountPoints)+len(c.Mounts))
+	for _, mp := range c.MountPoints {
+		mounts = append(mounts, MountConfig{Path: mp})
+	}
+	return append(mounts, c.Mounts...)
+}
+
+// validateMounts checks mount paths and namespaces, and that no mount point
+// is configured twice for the same namespace
+func (c *Config) validateMounts() error {
+	seen := make(map[MountConfig]bool)
+	for _, mp := range c.MountPoints {
+		seen[MountConfig{Path: mp}] = true
+	}
+
+	for i, m := range c.Mounts {
+		if err := validateMount(m, seen); err != nil {
+			return &FieldError{Path: fmt.Sprintf("mounts[%d]", i), Err: err}
+		}
+		seen[m] = true
+	}
+
+	return nil
+}
+
+// validateMount checks a single mounts entry against the mount points seen
+// before it
+func validateMount(m MountConfig, seen map[MountConfig]bool) error {
+	if m.Path == "" || m.Path[0] != '/' {
+		return fmt.Errorf("mounts path must be absolute path, got %q", m.Path)
+	}
+
+	set := 0
+	if m.PID != 0 {
+		set++
+	}
+	if m.Namespace != "" {
+		set++
+	}
+	if m.Container != "" {
+		set++
+	}
+	if set > 1 {
+		return fmt.Errorf("mounts entry %s: only one of pid, namespace and container may be set", m.Path)
+	}
+
+	if m.PID < 0 {
+		return fmt.Errorf("mounts entry %s: pid must be positive, got %d", m.Path, m.PID)
+	}
+	if m.Namespace != "" && !namespacePathPattern.MatchString(m.Namespace) {
+		return fmt.Errorf("mounts entry %s: namespace must be /proc/<pid>/ns/mnt, got %s", m.Path, m.Namespace)
+	}
+
+	if seen[m] {
+		return fmt.Errorf("mount point %s is configured more than once for the same namespace", m.Path)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func selectionConfig() *Config {
+	config := DefaultConfig()
+	config.MountPoints = []string{"/data", "/var/log", "/backup"}
+	config.MountGroups = map[string][]string{
+		"databases": {"/data", "/backup"},
+		"logs":      {"/var/log"},
+	}
+	return config
+}
+
+func TestResolveMountPoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		mounts   []string
+		groups   []string
+		expected []string
+	}{
+		{"single mount", []string{"/var/log"}, nil, []string{"/var/log"}},
+		{"group", nil, []string{"databases"}, []string{"/data", "/backup"}},
+		{"mounts and groups deduplicated", []string{"/backup", "/var/log"}, []string{"databases"}, []string{"/data", "/var/log", "/backup"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectionConfig().ResolveMountPoints(tt.mounts, tt.groups)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolveMountPoints_Unknown(t *testing.T) {
+	config := selectionConfig()
+
+	if _, err := config.ResolveMountPoints([]string{"/srv"}, nil); !errors.Is(err, ErrUnknownMountPoint) {
+		t.Errorf("Expected ErrUnknownMountPoint, got %v", err)
+	}
+	if _, err := config.ResolveMountPoints(nil, []string{"caches"}); !errors.Is(err, ErrUnknownMountGroup) {
+		t.Errorf("Expected ErrUnknownMountGroup, got %v", err)
+	}
+
+	config.AllowUnknownMounts = true
+	got, err := config.ResolveMountPoints([]string{"/srv", "/data"}, nil)
+	if err != nil {
+		t.Fatalf("Expected unknown mount to be allowed, got %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"/data", "/srv"}) {
+		t.Errorf("Expected [/data /srv], got %v", got)
+	}
+	if _, err := config.ResolveMountPoints([]string{"relative"}, nil); err == nil {
+		t.Error("Expected relative mount point to be rejected")
+	}
+}
+
+func TestValidate_MountGroups(t *testing.T) {
+	config := selectionConfig()
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected mount groups to be valid, got %v", err)
+	}
+
+	config.MountGroups["stray"] = []string{"/srv"}
+	if err := config.Validate(); err == nil || !contains(err.Error(), "mount group stray references unconfigured mount point /srv") {
+		t.Errorf("Expected unconfigured member error, got %v", err)
+	}
+
+	config = selectionConfig()
+	config.MountGroups["empty"] = nil
+	if err := config.Validate(); err == nil || !contains(err.Error(), "mount group empty must contain at least one mount point") {
+		t.Errorf("Expected empty group error, got %v", err)
+	}
+
+	original := selectionConfig()
+	clone := original.Clone()
+	clone.MountGroups["logs"][0] = "/changed"
+	if original.MountGroups["logs"][0] != "/var/log" {
+		t.Error("Expected clone to deep copy mount groups")
+	}
+}
+
+func TestValidate_Mounts(t *testing.T) {
+	tests := []struct {
+		name   string
+		mounts []MountConfig
+		errMsg string
+	}{
+		{"relative path", []MountConfig{{Path: "data", PID: 1}}, "mounts path must be absolute path"},
+		{"two namespaces", []MountConfig{{Path: "/data", PID: 1, Container: "db"}}, "only one of pid, namespace and container may be set"},
+		{"bad namespace path", []MountConfig{{Path: "/data", Namespace: "/proc/1/ns/net"}}, "namespace must be /proc/<pid>/ns/mnt"},
+		{"duplicate of mount_points", []MountConfig{{Path: "/var/log"}}, "configured more than once"},
+		{"duplicate namespace", []MountConfig{{Path: "/srv", PID: 1}, {Path: "/srv", PID: 1}}, "configured more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := selectionConfig()
+			config.Mounts = tt.mounts
+
+			err := config.Validate()
+			if err == nil || !contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing '%s', got %v", tt.errMsg, err)
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.Mounts = []MountConfig{
+		{Path: "/var/lib/kubelet", Namespace: "/proc/1/ns/mnt"},
+		{Path: "/var/lib/kubelet", Container: "kubelet"},
+	}
+	config.MountGroups = map[string][]string{"kubelet": {"/var/lib/kubelet"}}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected mounts without mount_points to be valid, got %v", err)
+	}
+
+	got, err := config.ResolveMountPoints([]string{"/var/lib/kubelet"}, []string{"kubelet"})
+	if err != nil || !reflect.DeepEqual(got, []string{"/var/lib/kubelet"}) {
+		t.Errorf("Expected [/var/lib/kubelet], got %v (%v)", got, err)
+	}
+	if len(config.AllMounts()) != 2 {
+		t.Errorf("Expected 2 mounts, got %v", config.AllMounts())
+	}
+}
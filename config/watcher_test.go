@@ -4,9 +4,11 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/mount-exporter/mount-exporter/recovery"
 	"gopkg.in/yaml.v3"
 )
 
@@ -67,7 +69,7 @@ func TestConfigWatcher(t *testing.T) {
 	}
 
 	// Update config file
-	updatedConfig := *initialConfig
+	updatedConfig := initialConfig.Clone()
 	updatedConfig.Server.Port = 9090
 	updatedConfig.MountPoints = []string{"/test1", "/test2"}
 
@@ -224,6 +226,32 @@ func TestConfigWatcher_DoubleStart(t *testing.T) {
 	watcher.Stop()
 }
 
+func TestConfigWatcher_Supervised(t *testing.T) {
+	supervisor := recovery.NewSupervisor(recovery.SupervisorConfig{Logger: &testLogger{}})
+	defer supervisor.Stop(context.Background())
+
+	watcher := NewConfigWatcher("/tmp/test.yaml", &Config{})
+	watcher.SetSupervisor(supervisor)
+
+	if err := watcher.Watch(100 * time.Millisecond); err != nil {
+		t.Fatalf("Failed to start watcher: %v", err)
+	}
+	defer watcher.Stop()
+
+	statuses := supervisor.Status()
+	if len(statuses) != 1 || statuses[0].Name != "config-watcher" {
+		t.Fatalf("Expected config-watcher worker to be supervised, got %+v", statuses)
+	}
+	if statuses[0].Policy != "on-panic" {
+		t.Errorf("Expected on-panic restart policy, got %s", statuses[0].Policy)
+	}
+}
+
+// testLogger discards log output
+type testLogger struct{}
+
+func (l *testLogger) Printf(format string, args ...interface{}) {}
+
 func TestConfigWatcher_GetConfig(t *testing.T) {
 	config := &Config{
 		Server: ServerConfig{
@@ -290,7 +318,7 @@ func TestConfigWatcher_InvalidConfigReload(t *testing.T) {
 	}
 
 	// Write invalid config (port out of range)
-	invalidConfig := *initialConfig
+	invalidConfig := initialConfig.Clone()
 	invalidConfig.Server.Port = 99999
 
 	data, err = yaml.Marshal(invalidConfig)
@@ -311,4 +339,120 @@ func TestConfigWatcher_InvalidConfigReload(t *testing.T) {
 	}
 
 	watcher.Stop()
-}
\ No newline at end of file
+}
+
+func TestConfigWatcher_IncludedFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	confDir := filepath.Join(tempDir, "conf.d")
+	if err := os.Mkdir(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configPath, []byte("include: [conf.d/*.yaml]\nmount_points: [/data]\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	watcher := NewConfigWatcher(configPath, config)
+	reloaded := make(chan *Config, 10)
+	watcher.AddCallback(func(newConfig *Config) {
+		reloaded <- newConfig
+	})
+
+	if err := watcher.Watch(50 * time.Millisecond); err != nil {
+		t.Fatalf("Failed to start watcher: %v", err)
+	}
+	defer watcher.Stop()
+	// Let the watcher record the initial modification times
+	time.Sleep(100 * time.Millisecond)
+
+	expectMountPoints := func(expected int) {
+		t.Helper()
+		select {
+		case cfg := <-reloaded:
+			if len(cfg.MountPoints) != expected {
+				t.Errorf("Expected %d mount points, got %v", expected, cfg.MountPoints)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Callback was not called within timeout")
+		}
+	}
+
+	// Replace the file in one step, so a poll never sees it half written
+	teamPath := filepath.Join(confDir, "team.yaml")
+	writeTeam := func(content string) {
+		t.Helper()
+		tmp := filepath.Join(confDir, "team.tmp")
+		if err := os.WriteFile(tmp, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, teamPath); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A new file in conf.d triggers a reload
+	writeTeam("mount_points: [/srv]\n")
+	expectMountPoints(2)
+
+	// So does a change of an included file
+	time.Sleep(10 * time.Millisecond)
+	writeTeam("mount_points: [/srv, /backup]\n")
+	expectMountPoints(3)
+
+	// And its removal
+	if err := os.Remove(teamPath); err != nil {
+		t.Fatal(err)
+	}
+	expectMountPoints(1)
+}
+
+func TestConfigWatcher_IncludeOnly(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+	confDir := filepath.Join(tempDir, "conf.d")
+	if err := os.Mkdir(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer func(pattern string) { defaultInclude = pattern }(defaultInclude)
+	defaultInclude = filepath.Join(confDir, "*.yaml")
+
+	// There is no main configuration file, only the default include
+	config, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	watcher := NewConfigWatcher(configPath, config)
+	reloaded := make(chan *Config, 10)
+	watcher.AddCallback(func(newConfig *Config) {
+		reloaded <- newConfig
+	})
+
+	if err := watcher.Watch(50 * time.Millisecond); err != nil {
+		t.Fatalf("Failed to start watcher: %v", err)
+	}
+	defer watcher.Stop()
+	time.Sleep(100 * time.Millisecond)
+
+	tmp := filepath.Join(confDir, "team.tmp")
+	if err := os.WriteFile(tmp, []byte("mount_points: [/srv]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, filepath.Join(confDir, "team.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case cfg := <-reloaded:
+		if !reflect.DeepEqual(cfg.MountPoints, []string{"/srv"}) {
+			t.Errorf("Expected the mount points of conf.d, got %v", cfg.MountPoints)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Callback was not called within timeout")
+	}
+}
@@ -0,0 +1,222 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/metrics"
+)
+
+// Dashboard layout: panels are half the 24 column grid wide
+const (
+	panelWidth  = 12
+	panelHeight = 8
+)
+
+// datasourceRef refers to the dashboard's Prometheus data source variable
+var datasourceRef = map[string]string{"type": "prometheus", "uid": "${datasource}"}
+
+// GrafanaDashboard is the subset of the Grafana dashboard model we generate
+type GrafanaDashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags"`
+	Timezone      string     `json:"timezone"`
+	Refresh       string     `json:"refresh"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          TimeRange  `json:"time"`
+	Templating    Templating `json:"templating"`
+	Panels        []Panel    `json:"panels"`
+}
+
+// TimeRange is the default time range of a dashboard
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Templating holds the dashboard variables
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+// Variable is a dashboard variable
+type Variable struct {
+	Name       string            `json:"name"`
+	Label      string            `json:"label,omitempty"`
+	Type       string            `json:"type"`
+	Query      string            `json:"query"`
+	Datasource map[string]string `json:"datasource,omitempty"`
+	Multi      bool              `json:"multi,omitempty"`
+	IncludeAll bool              `json:"includeAll,omitempty"`
+	Refresh    int               `json:"refresh,omitempty"`
+}
+
+// Panel is a dashboard panel
+type Panel struct {
+	ID          int               `json:"id"`
+	Type        string            `json:"type"`
+	Title       string            `json:"title"`
+	Description string            `json:"description,omitempty"`
+	Datasource  map[string]string `json:"datasource"`
+	GridPos     GridPos           `json:"gridPos"`
+	Targets     []Target          `json:"targets"`
+	FieldConfig FieldConfig       `json:"fieldConfig"`
+}
+
+// GridPos is the position of a panel on the dashboard grid
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Target is a panel query
+type Target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+	Instant      bool   `json:"instant,omitempty"`
+	Format       string `json:"format,omitempty"`
+}
+
+// FieldConfig sets the unit of a panel's values
+type FieldConfig struct {
+	Defaults FieldDefaults `json:"defaults"`
+}
+
+// FieldDefaults are the default field options of a panel
+type FieldDefaults struct {
+	Unit string `json:"unit,omitempty"`
+}
+
+// unit returns a field config displaying values in unit
+func unit(unit string) FieldConfig {
+	return FieldConfig{Defaults: FieldDefaults{Unit: unit}}
+}
+
+// Dashboard returns the Grafana dashboard for cfg as JSON
+func Dashboard(cfg *config.Config, descs []metrics.MetricDescriptor) ([]byte, error) {
+	d, err := BuildDashboard(cfg, descs)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode dashboard: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// BuildDashboard returns the Grafana dashboard for cfg. Like BuildRules, it
+// only queries metrics and labels present in descs, and only adds panels for
+// enabled features.
+func BuildDashboard(cfg *config.Config, descs []metrics.MetricDescriptor) (*GrafanaDashboard, error) {
+	c := newCatalog(descs)
+	selected := re("mount_point", "$mount_point")
+	db := &dashboardBuilder{}
+
+	db.add(Panel{Type: "stat", Title: "Exporter health", Targets: []Target{
+		{Expr: c.series("mount_exporter_up")},
+	}})
+	db.add(Panel{Type: "stat", Title: "Mount points not mounted", Targets: []Target{
+		{Expr: fmt.Sprintf("count(%s == 0) or vector(0)", c.series("mount_exporter_mount_point_status", selected))},
+	}})
+	db.add(Panel{Type: "state-timeline", Title: "Mount point status", Targets: []Target{
+		{Expr: c.series("mount_exporter_mount_point_status", selected), LegendFormat: "{{mount_point}} {{namespace}}"},
+	}})
+	db.add(Panel{Type: "table", Title: "Mounted filesystems", Targets: []Target{
+		{Expr: c.series("mount_exporter_mount_info", selected), Instant: true, Format: "table"},
+	}})
+	db.add(Panel{Type: "timeseries", Title: "Check duration", FieldConfig: unit("s"), Targets: []Target{
+		{Expr: c.series("mount_exporter_scrape_duration_seconds", selected), LegendFormat: "{{mount_point}} {{namespace}}"},
+	}})
+
+	if c.has("mount_exporter_mount_operation_duration_seconds") {
+		db.add(Panel{Type: "timeseries", Title: "Operation latency (p99)", FieldConfig: unit("s"), Targets: []Target{{
+			Expr: fmt.Sprintf("histogram_quantile(0.99, sum by (le, %s, %s) (rate(%s[$__rate_interval])))",
+				c.label("mount_exporter_mount_operation_duration_seconds", "mount_point"),
+				c.label("mount_exporter_mount_operation_duration_seconds", "operation"),
+				c.buckets("mount_exporter_mount_operation_duration_seconds", selected)),
+			LegendFormat: "{{mount_point}} {{operation}}",
+		}}})
+	}
+
+	if c.has("mount_exporter_write_probe_duration_seconds") {
+		db.add(Panel{Type: "timeseries", Title: "Write probe duration (p99)", FieldConfig: unit("s"), Targets: []Target{{
+			Expr: fmt.Sprintf("histogram_quantile(0.99, sum by (le, %s) (rate(%s[$__rate_interval])))",
+				c.label("mount_exporter_write_probe_duration_seconds", "mount_point"),
+				c.buckets("mount_exporter_write_probe_duration_seconds", selected)),
+			LegendFormat: "{{mount_point}}",
+		}}})
+	}
+
+	if cfg.BlockDevices.Enabled {
+		db.add(Panel{Type: "timeseries", Title: "Block device throughput", FieldConfig: unit("Bps"), Targets: []Target{
+			{Expr: fmt.Sprintf("rate(%s[$__rate_interval])", c.series("mount_exporter_block_device_read_bytes_total", selected)), LegendFormat: "{{mount_point}} read"},
+			{Expr: fmt.Sprintf("rate(%s[$__rate_interval])", c.series("mount_exporter_block_device_written_bytes_total", selected)), LegendFormat: "{{mount_point}} write"},
+		}})
+	}
+
+	if cfg.NFSStats.Enabled {
+		db.add(Panel{Type: "timeseries", Title: "NFS RPC retransmissions", FieldConfig: unit("ops"), Targets: []Target{
+			{Expr: fmt.Sprintf("rate(%s[$__rate_interval])", c.series("mount_exporter_nfs_rpc_retransmissions_total", selected)), LegendFormat: "{{mount_point}}"},
+		}})
+	}
+
+	if cfg.Drift.Enabled {
+		db.add(Panel{Type: "table", Title: "Declared mounts not mounted", Targets: []Target{
+			{Expr: fmt.Sprintf("%s == 0", c.series("mount_exporter_declared_mount_mounted", selected)), Instant: true, Format: "table"},
+		}})
+	}
+
+	mountPoints := Variable{
+		Name:       "mount_point",
+		Label:      "Mount point",
+		Type:       "query",
+		Query:      fmt.Sprintf("label_values(%s, %s)", c.series("mount_exporter_mount_point_status"), c.label("mount_exporter_mount_point_status", "mount_point")),
+		Datasource: datasourceRef,
+		Multi:      true,
+		IncludeAll: true,
+		Refresh:    2,
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return &GrafanaDashboard{
+		UID:           "mount-exporter",
+		Title:         "Mount Exporter",
+		Tags:          []string{"mount-exporter"},
+		Timezone:      "browser",
+		Refresh:       "30s",
+		SchemaVersion: 39,
+		Time:          TimeRange{From: "now-6h", To: "now"},
+		Templating: Templating{List: []Variable{
+			{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"},
+			mountPoints,
+		}},
+		Panels: db.panels,
+	}, nil
+}
+
+// dashboardBuilder lays panels out two per row
+type dashboardBuilder struct {
+	panels []Panel
+}
+
+// add places p after the previous panel and numbers its queries
+func (db *dashboardBuilder) add(p Panel) {
+	n := len(db.panels)
+	p.ID = n + 1
+	p.Datasource = datasourceRef
+	p.GridPos = GridPos{H: panelHeight, W: panelWidth, X: (n % 2) * panelWidth, Y: (n / 2) * panelHeight}
+	for i := range p.Targets {
+		p.Targets[i].RefID = string(rune('A' + i))
+	}
+	db.panels = append(db.panels, p)
+}
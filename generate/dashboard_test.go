@@ -0,0 +1,70 @@
+package generate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/metrics"
+)
+
+func TestDashboard(t *testing.T) {
+	cfg := testConfig()
+	descs := Descriptors(cfg)
+
+	data, err := Dashboard(cfg, descs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var dashboard GrafanaDashboard
+	if err := json.Unmarshal(data, &dashboard); err != nil {
+		t.Fatalf("Generated dashboard is not valid JSON: %v", err)
+	}
+
+	if len(dashboard.Panels) != 10 {
+		t.Errorf("Expected 10 panels, got %d", len(dashboard.Panels))
+	}
+
+	ids := make(map[int]bool)
+	for i, panel := range dashboard.Panels {
+		if ids[panel.ID] {
+			t.Errorf("Duplicate panel id %d", panel.ID)
+		}
+		ids[panel.ID] = true
+
+		if panel.GridPos.X != (i%2)*panelWidth || panel.GridPos.Y != (i/2)*panelHeight {
+			t.Errorf("Panel %q at unexpected position %+v", panel.Title, panel.GridPos)
+		}
+		for j, target := range panel.Targets {
+			if target.RefID != string(rune('A'+j)) {
+				t.Errorf("Panel %q target %d has refId %q", panel.Title, j, target.RefID)
+			}
+			checkSeries(t, descs, target.Expr)
+		}
+	}
+
+	for _, v := range dashboard.Templating.List {
+		checkSeries(t, descs, v.Query)
+	}
+}
+
+func TestBuildDashboard_OptionalPanels(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MountPoints = []string{"/data"}
+	descs := Descriptors(cfg)
+
+	dashboard, err := BuildDashboard(cfg, descs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(dashboard.Panels) != 5 {
+		t.Errorf("Expected only the base panels, got %d", len(dashboard.Panels))
+	}
+
+	if _, err := BuildDashboard(cfg, []metrics.MetricDescriptor{{Name: "mount_exporter_up"}}); err == nil ||
+		!strings.Contains(err.Error(), "mount_exporter_mount_point_status is not exported") {
+		t.Errorf("Expected missing metric error, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+// Package generate builds Prometheus rules and Grafana dashboards from the
+// configuration and the metric descriptors of the exporter's collectors.
+package generate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/metrics"
+	"github.com/mount-exporter/mount-exporter/system"
+	"github.com/prometheus/common/model"
+)
+
+// defaultSeverity is used when the alerting severity is not configured
+const defaultSeverity = "warning"
+
+// Descriptors returns the descriptors of the collectors the exporter
+// registers for cfg
+func Descriptors(cfg *config.Config) []metrics.MetricDescriptor {
+	collector := metrics.NewCollector(cfg)
+	collectors := []metrics.Describer{collector}
+	if len(cfg.WriteProbe.MountPoints) > 0 {
+		collectors = append(collectors, metrics.NewWriteProbeCollector(cfg.WriteProbe))
+	}
+	if len(cfg.LatencyProbe.MountPoints) > 0 {
+		collectors = append(collectors, metrics.NewLatencyProbeCollector(cfg.LatencyProbe, collector.GetFindmntWrapper()))
+	}
+	return metrics.Descriptors(collectors...)
+}
+
+// matcher is a PromQL label matcher
+type matcher struct {
+	label string
+	op    string
+	value string
+}
+
+// eq returns an equality matcher
+func eq(label, value string) matcher {
+	return matcher{label: label, op: "=", value: value}
+}
+
+// ne returns an inequality matcher
+func ne(label, value string) matcher {
+	return matcher{label: label, op: "!=", value: value}
+}
+
+// re returns a regular expression matcher
+func re(label, value string) matcher {
+	return matcher{label: label, op: "=~", value: value}
+}
+
+// catalog builds series selectors, recording the first reference to a
+// metric or label that no collector exports
+type catalog struct {
+	descs map[string]metrics.MetricDescriptor
+	err   error
+}
+
+// newCatalog indexes descs by name
+func newCatalog(descs []metrics.MetricDescriptor) *catalog {
+	c := &catalog{descs: make(map[string]metrics.MetricDescriptor, len(descs))}
+	for _, d := range descs {
+		c.descs[d.Name] = d
+	}
+	return c
+}
+
+// has reports whether a metric is exported, for optional rules and panels
+func (c *catalog) has(name string, labels ...string) bool {
+	d, ok := c.descs[name]
+	if !ok {
+		return false
+	}
+	for _, l := range labels {
+		if !d.HasLabel(l) {
+			return false
+		}
+	}
+	return true
+}
+
+// series returns a selector for metric name
+func (c *catalog) series(name string, matchers ...matcher) string {
+	return c.selector(name, name, matchers)
+}
+
+// buckets returns a selector for the buckets of histogram name
+func (c *catalog) buckets(name string, matchers ...matcher) string {
+	return c.selector(name, name+"_bucket", matchers)
+}
+
+// selector checks name and the matcher labels, and formats the selector for
+// series
+func (c *catalog) selector(name, series string, matchers []matcher) string {
+	d, ok := c.descs[name]
+	if !ok {
+		c.fail(fmt.Errorf("metric %s is not exported", name))
+	}
+
+	parts := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		if ok && !d.HasLabel(m.label) {
+			c.fail(fmt.Errorf("metric %s has no label %s", name, m.label))
+		}
+		parts = append(parts, m.label+m.op+strconv.Quote(m.value))
+	}
+
+	if len(parts) == 0 {
+		return series
+	}
+	return series + "{" + strings.Join(parts, ",") + "}"
+}
+
+// label checks that metric name has label and returns the label name
+func (c *catalog) label(name, label string) string {
+	if !c.has(name, label) {
+		c.fail(fmt.Errorf("metric %s has no label %s", name, label))
+	}
+	return label
+}
+
+// fail records err unless an error was recorded already
+func (c *catalog) fail(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// mountMatchers returns the matchers selecting a configured mount
+func mountMatchers(m config.MountConfig) []matcher {
+	return []matcher{eq("mount_point", m.Path), eq("namespace", mountNamespace(m))}
+}
+
+// mountNamespace returns the namespace label value of a configured mount
+func mountNamespace(m config.MountConfig) string {
+	return system.MountNamespace{PID: m.PID, Path: m.Namespace, Container: m.Container}.Label()
+}
+
+// severity returns the configured default severity
+func severity(a config.AlertingConfig) string {
+	if a.Severity == "" {
+		return defaultSeverity
+	}
+	return a.Severity
+}
+
+// mergeLabels returns the union of label maps, later maps taking precedence
+func mergeLabels(maps ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, m := range maps {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// promDuration formats d the way Prometheus writes durations, e.g. "5m" or
+// "1s500ms". Prometheus durations have millisecond precision, so d is
+// rounded to the nearest millisecond.
+func promDuration(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return model.Duration(max(d.Round(time.Millisecond), time.Millisecond)).String()
+}
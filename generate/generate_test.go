@@ -0,0 +1,129 @@
+package generate
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/metrics"
+)
+
+// seriesPattern matches metric names in generated queries; recording rule
+// names contain a colon and are not matched
+var seriesPattern = regexp.MustCompile(`\bmount_exporter_[a-z_]+\b`)
+
+// testConfig returns a valid configuration with every optional feature
+// that adds rules or panels enabled
+func testConfig() *config.Config {
+	readOnly := false
+	cfg := config.DefaultConfig()
+	cfg.MountPoints = []string{"/data", "/tmp"}
+	cfg.Mounts = []config.MountConfig{{Path: "/var/lib/postgresql", Container: "db"}}
+	cfg.Drift.Enabled = true
+	cfg.Drift.FstabPath = "/etc/fstab"
+	cfg.NFSStats.Enabled = true
+	cfg.BlockDevices.Enabled = true
+	cfg.WriteProbe.MountPoints = []string{"/data"}
+	cfg.LatencyProbe.MountPoints = []string{"/data"}
+	cfg.Kubernetes.Enabled = true
+	cfg.Kubernetes.Discover = true
+	cfg.Alerting.Labels = map[string]string{"team": "storage"}
+	cfg.Alerting.Mounts = []config.MountAlertConfig{
+		{MountPoint: "/tmp", Severity: "critical", Options: []string{"noexec", "nosuid"}},
+		{MountPoint: "/data", FSType: "xfs", ReadOnly: &readOnly, Labels: map[string]string{"team": "db"}},
+	}
+	return cfg
+}
+
+// checkSeries fails t when expr references a series no descriptor covers
+func checkSeries(t *testing.T, descs []metrics.MetricDescriptor, expr string) {
+	t.Helper()
+	names := make(map[string]bool, len(descs))
+	for _, d := range descs {
+		names[d.Name] = true
+	}
+	for _, series := range seriesPattern.FindAllString(expr, -1) {
+		if !names[series] && !names[strings.TrimSuffix(series, "_bucket")] {
+			t.Errorf("Query %q references unknown series %s", expr, series)
+		}
+	}
+}
+
+func TestDescriptors(t *testing.T) {
+	cfg := testConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Invalid test configuration: %v", err)
+	}
+
+	descs := Descriptors(cfg)
+
+	c := newCatalog(descs)
+	for _, name := range []string{
+		"mount_exporter_mount_point_status",
+		"mount_exporter_write_probe_success",
+		"mount_exporter_mount_operation_duration_seconds",
+	} {
+		if !c.has(name) {
+			t.Errorf("Expected descriptor %s", name)
+		}
+	}
+	if !c.has("mount_exporter_mount_point_status", "pod_uid") {
+		t.Error("Expected Kubernetes labels on mount_point_status")
+	}
+
+	descs = Descriptors(config.DefaultConfig())
+	if newCatalog(descs).has("mount_exporter_write_probe_success") {
+		t.Error("Expected no write probe descriptor when write probes are disabled")
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	c := newCatalog([]metrics.MetricDescriptor{{Name: "m", Labels: []string{"mount_point", "namespace"}}})
+
+	if got := c.series("m"); got != "m" {
+		t.Errorf("Expected bare selector, got %s", got)
+	}
+	if got := c.series("m", eq("mount_point", `/mnt/a "b"`), re("namespace", ".*")); got != `m{mount_point="/mnt/a \"b\"",namespace=~".*"}` {
+		t.Errorf("Unexpected selector %s", got)
+	}
+	if got := c.buckets("m", ne("namespace", "")); got != `m_bucket{namespace!=""}` {
+		t.Errorf("Unexpected bucket selector %s", got)
+	}
+	if c.err != nil {
+		t.Fatalf("Unexpected error: %v", c.err)
+	}
+
+	c.series("m", eq("pod", "x"))
+	if c.err == nil || !strings.Contains(c.err.Error(), "metric m has no label pod") {
+		t.Errorf("Expected unknown label error, got %v", c.err)
+	}
+
+	c = newCatalog(nil)
+	c.series("missing")
+	c.label("other", "mount_point")
+	if c.err == nil || c.err.Error() != "metric missing is not exported" {
+		t.Errorf("Expected the first error to be kept, got %v", c.err)
+	}
+}
+
+func TestPromDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                       "",
+		30 * time.Second:        "30s",
+		5 * time.Minute:         "5m",
+		90 * time.Second:        "1m30s",
+		time.Hour:               "1h",
+		time.Hour + time.Minute: "1h1m",
+		24 * time.Hour:          "1d",
+		1500 * time.Millisecond: "1s500ms",
+		1499 * time.Microsecond: "1ms",
+		time.Microsecond:        "1ms",
+	}
+	for d, expected := range tests {
+		if got := promDuration(d); got != expected {
+			t.Errorf("promDuration(%v) = %q, expected %q", d, got, expected)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package generate
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/metrics"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleFile is a Prometheus rule file
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a named group of recording and alerting rules
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a recording rule (Record set) or an alerting rule (Alert set)
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Rules returns the Prometheus rule file for cfg as YAML
+func Rules(cfg *config.Config, descs []metrics.MetricDescriptor) ([]byte, error) {
+	file, err := BuildRules(cfg, descs)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode rules: %w", err)
+	}
+	return data, nil
+}
+
+// BuildRules returns the recording and alerting rules for cfg. Rules only
+// reference metrics and labels present in descs; optional rules are left out
+// when the feature they cover is disabled.
+func BuildRules(cfg *config.Config, descs []metrics.MetricDescriptor) (*RuleFile, error) {
+	c := newCatalog(descs)
+	a := cfg.Alerting
+	rb := &ruleBuilder{catalog: c, alerting: a}
+
+	records := []Rule{{
+		Record: "mount_exporter:mount_points_not_mounted:count",
+		Expr:   fmt.Sprintf("count(%s == 0) or vector(0)", c.series("mount_exporter_mount_point_status")),
+	}}
+	if c.has("mount_exporter_mount_operation_duration_seconds") {
+		records = append(records, Rule{
+			Record: "mount_exporter:mount_operation_duration_seconds:p99_5m",
+			Expr: fmt.Sprintf("histogram_quantile(0.99, sum by (le, %s, %s) (rate(%s[5m])))",
+				c.label("mount_exporter_mount_operation_duration_seconds", "mount_point"),
+				c.label("mount_exporter_mount_operation_duration_seconds", "operation"),
+				c.buckets("mount_exporter_mount_operation_duration_seconds")),
+		})
+	}
+
+	alerts := []Rule{rb.alert("MountExporterDown",
+		fmt.Sprintf("%s == 0", c.series("mount_exporter_up")),
+		nil,
+		"Mount exporter is unhealthy",
+		"Mount exporter on {{ $labels.instance }} failed to check one or more mount points.")}
+
+	for _, m := range cfg.AllMounts() {
+		mount, _ := a.MountAlert(m.Path)
+		alerts = append(alerts, rb.alert("MountPointNotMounted",
+			fmt.Sprintf("%s == 0", c.series("mount_exporter_mount_point_status", mountMatchers(m)...)),
+			&mount,
+			"Mount point {{ $labels.mount_point }} is not mounted",
+			"{{ $labels.mount_point }} is not mounted on {{ $labels.instance }}: {{ $labels.error }}"))
+		alerts = append(alerts, rb.expectations(m, mount)...)
+	}
+
+	if cfg.Kubernetes.Enabled && cfg.Kubernetes.Discover {
+		alerts = append(alerts, rb.alert("KubeletVolumeNotMounted",
+			fmt.Sprintf("%s == 0", c.series("mount_exporter_mount_point_status", ne("pod_uid", ""))),
+			nil,
+			"Volume {{ $labels.volume }} of pod {{ $labels.pod_namespace }}/{{ $labels.pod }} is not mounted",
+			"{{ $labels.mount_point }} is not mounted on {{ $labels.instance }}."))
+	}
+
+	if cfg.Drift.Enabled {
+		alerts = append(alerts,
+			rb.alert("DeclaredMountNotMounted",
+				fmt.Sprintf("%s == 0", c.series("mount_exporter_declared_mount_mounted", eq("noauto", "false"))),
+				nil,
+				"Declared mount {{ $labels.mount_point }} is not mounted",
+				"{{ $labels.mount_point }} is declared in {{ $labels.origin }} but not mounted on {{ $labels.instance }}."),
+			rb.alert("DeclaredMountDrifted",
+				fmt.Sprintf("%s == 0", c.series("mount_exporter_declared_mount_match")),
+				nil,
+				"Mount {{ $labels.mount_point }} differs from its declaration",
+				"The {{ $labels.field }} of {{ $labels.mount_point }} differs from {{ $labels.origin }} on {{ $labels.instance }}."))
+	}
+
+	if c.has("mount_exporter_write_probe_success") {
+		alerts = append(alerts, rb.alert("MountWriteProbeFailing",
+			fmt.Sprintf("%s == 0", c.series("mount_exporter_write_probe_success")),
+			nil,
+			"Writes to {{ $labels.mount_point }} are failing",
+			"The write probe of {{ $labels.mount_point }} failed with {{ $labels.errno_class }} on {{ $labels.instance }}."))
+	}
+
+	if c.has("mount_exporter_mount_operation_failures_total") {
+		alerts = append(alerts, rb.alert("MountOperationsFailing",
+			fmt.Sprintf("increase(%s[5m]) > 0", c.series("mount_exporter_mount_operation_failures_total")),
+			nil,
+			"{{ $labels.operation }} on {{ $labels.mount_point }} is failing",
+			"Latency probes of {{ $labels.mount_point }} failed with {{ $labels.errno_class }} on {{ $labels.instance }}."))
+	}
+
+	if cfg.NFSStats.Enabled {
+		alerts = append(alerts, rb.alert("NFSRetransmissions",
+			fmt.Sprintf("rate(%s[5m]) > 0", c.series("mount_exporter_nfs_rpc_retransmissions_total")),
+			nil,
+			"NFS mount {{ $labels.mount_point }} is retransmitting requests",
+			"The NFS client retransmits RPC requests to {{ $labels.export }} on {{ $labels.instance }}."))
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return &RuleFile{Groups: []RuleGroup{
+		{Name: "mount-exporter.rules", Rules: records},
+		{Name: "mount-exporter.alerts", Rules: alerts},
+	}}, nil
+}
+
+// ruleBuilder creates alerting rules with the configured severities and labels
+type ruleBuilder struct {
+	*catalog
+	alerting config.AlertingConfig
+}
+
+// alert creates an alerting rule, applying the per-mount overrides of mount
+// when it is not nil
+func (rb *ruleBuilder) alert(name, expr string, mount *config.MountAlertConfig, summary, description string) Rule {
+	labels := mergeLabels(rb.alerting.Labels)
+	sev := severity(rb.alerting)
+	if mount != nil {
+		labels = mergeLabels(labels, mount.Labels)
+		if mount.Severity != "" {
+			sev = mount.Severity
+		}
+	}
+	labels["severity"] = sev
+
+	return Rule{
+		Alert:  name,
+		Expr:   expr,
+		For:    promDuration(rb.alerting.For),
+		Labels: labels,
+		Annotations: map[string]string{
+			"summary":     summary,
+			"description": description,
+		},
+	}
+}
+
+// expectations returns the alerts checking the expected filesystem type,
+// read-only state and mount options of a mount
+func (rb *ruleBuilder) expectations(m config.MountConfig, mount config.MountAlertConfig) []Rule {
+	var alerts []Rule
+	matchers := mountMatchers(m)
+
+	if mount.FSType != "" {
+		alerts = append(alerts, rb.alert("MountPointUnexpectedFSType",
+			rb.series("mount_exporter_mount_info", append(matchers, ne("fs_type", mount.FSType))...),
+			&mount,
+			"Mount point {{ $labels.mount_point }} has an unexpected filesystem type",
+			fmt.Sprintf("{{ $labels.mount_point }} is {{ $labels.fs_type }} on {{ $labels.instance }}, expected %s.", mount.FSType)))
+	}
+
+	if mount.ReadOnly != nil {
+		alerts = append(alerts, rb.alert("MountPointReadOnlyMismatch",
+			rb.series("mount_exporter_mount_info", append(matchers, ne("read_only", strconv.FormatBool(*mount.ReadOnly)))...),
+			&mount,
+			"Mount point {{ $labels.mount_point }} has an unexpected read-only state",
+			fmt.Sprintf("{{ $labels.mount_point }} has read_only={{ $labels.read_only }} on {{ $labels.instance }}, expected %t.", *mount.ReadOnly)))
+	}
+
+	for _, opt := range mount.Options {
+		if !metrics.IsExportedMountOption(opt) {
+			rb.fail(fmt.Errorf("mount option %s of %s is not exported by mount_option", opt, m.Path))
+			continue
+		}
+		alerts = append(alerts, rb.alert("MountPointOptionMissing",
+			fmt.Sprintf("%s unless on(mount_point, namespace) %s",
+				rb.series("mount_exporter_mount_info", matchers...),
+				rb.series("mount_exporter_mount_option", append(matchers, eq("option", opt))...)),
+			&mount,
+			fmt.Sprintf("Mount point {{ $labels.mount_point }} is missing option %s", opt),
+			fmt.Sprintf("{{ $labels.mount_point }} is mounted without %s on {{ $labels.instance }}.", opt)))
+	}
+
+	return alerts
+}
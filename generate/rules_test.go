@@ -0,0 +1,123 @@
+package generate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/metrics"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRules(t *testing.T) {
+	cfg := testConfig()
+	descs := Descriptors(cfg)
+
+	data, err := Rules(cfg, descs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var file RuleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		t.Fatalf("Generated rules are not valid YAML: %v", err)
+	}
+	if len(file.Groups) != 2 {
+		t.Fatalf("Expected 2 rule groups, got %d", len(file.Groups))
+	}
+
+	alerts := make(map[string][]Rule)
+	for _, group := range file.Groups {
+		for _, rule := range group.Rules {
+			checkSeries(t, descs, rule.Expr)
+			if rule.Alert != "" {
+				alerts[rule.Alert] = append(alerts[rule.Alert], rule)
+			}
+		}
+	}
+
+	for _, name := range []string{
+		"MountExporterDown", "KubeletVolumeNotMounted", "DeclaredMountNotMounted", "DeclaredMountDrifted",
+		"MountWriteProbeFailing", "MountOperationsFailing", "NFSRetransmissions",
+		"MountPointUnexpectedFSType", "MountPointReadOnlyMismatch",
+	} {
+		if len(alerts[name]) != 1 {
+			t.Errorf("Expected one %s alert, got %d", name, len(alerts[name]))
+		}
+	}
+
+	notMounted := alerts["MountPointNotMounted"]
+	if len(notMounted) != 3 {
+		t.Fatalf("Expected an alert per configured mount, got %d", len(notMounted))
+	}
+	expected := []struct {
+		expr     string
+		severity string
+		team     string
+	}{
+		{`mount_exporter_mount_point_status{mount_point="/data",namespace=""} == 0`, "warning", "db"},
+		{`mount_exporter_mount_point_status{mount_point="/tmp",namespace=""} == 0`, "critical", "storage"},
+		{`mount_exporter_mount_point_status{mount_point="/var/lib/postgresql",namespace="db"} == 0`, "warning", "storage"},
+	}
+	for i, e := range expected {
+		rule := notMounted[i]
+		if rule.Expr != e.expr || rule.Labels["severity"] != e.severity || rule.Labels["team"] != e.team || rule.For != "5m" {
+			t.Errorf("Unexpected rule %+v, expected %+v", rule, e)
+		}
+	}
+
+	options := alerts["MountPointOptionMissing"]
+	if len(options) != 2 || !strings.Contains(options[0].Expr, `option="noexec"`) || !strings.Contains(options[1].Expr, `option="nosuid"`) {
+		t.Errorf("Unexpected option alerts %+v", options)
+	}
+
+	readOnly := alerts["MountPointReadOnlyMismatch"][0]
+	if readOnly.Expr != `mount_exporter_mount_info{mount_point="/data",namespace="",read_only!="false"}` {
+		t.Errorf("Unexpected read-only expression %s", readOnly.Expr)
+	}
+}
+
+func TestBuildRules_OptionalRules(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MountPoints = []string{"/data"}
+	cfg.Alerting.Severity = ""
+	descs := Descriptors(cfg)
+
+	file, err := BuildRules(cfg, descs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	alerts := file.Groups[1].Rules
+	if len(alerts) != 2 || alerts[0].Alert != "MountExporterDown" || alerts[1].Alert != "MountPointNotMounted" {
+		t.Errorf("Expected only the exporter and mount point alerts, got %+v", alerts)
+	}
+	if alerts[0].Labels["severity"] != "warning" {
+		t.Errorf("Expected default severity, got %q", alerts[0].Labels["severity"])
+	}
+	if len(file.Groups[0].Rules) != 1 {
+		t.Errorf("Expected no latency recording rule, got %+v", file.Groups[0].Rules)
+	}
+}
+
+func TestBuildRules_Errors(t *testing.T) {
+	cfg := testConfig()
+	cfg.Alerting.Mounts[0].Options = []string{"noatime"}
+	descs := Descriptors(cfg)
+
+	if _, err := BuildRules(cfg, descs); err == nil || !strings.Contains(err.Error(), "mount option noatime of /tmp is not exported") {
+		t.Errorf("Expected unexported option error, got %v", err)
+	}
+
+	// Rules never reference metrics the collectors do not describe
+	var withoutInfo []metrics.MetricDescriptor
+	for _, d := range descs {
+		if d.Name != "mount_exporter_mount_info" {
+			withoutInfo = append(withoutInfo, d)
+		}
+	}
+	cfg = testConfig()
+	if _, err := BuildRules(cfg, withoutInfo); err == nil || !strings.Contains(err.Error(), "mount_exporter_mount_info is not exported") {
+		t.Errorf("Expected missing metric error, got %v", err)
+	}
+}
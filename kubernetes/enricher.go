@@ -0,0 +1,146 @@
+package kubernetes
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/system"
+)
+
+// Logger is used to report failed refreshes
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// VolumeLabels are the Kubernetes labels of a mount point. PodUID and Volume
+// come from the kubelet path; the rest is empty until metadata is known.
+type VolumeLabels struct {
+	PodUID    string
+	Volume    string
+	Namespace string
+	Pod       string
+	PVC       string
+}
+
+// Enricher maps kubelet volume mounts to pod and PVC labels. Metadata and
+// discovered mounts are refreshed in the background so lookups never block
+// a scrape on the metadata source.
+type Enricher struct {
+	kubeletRoot string
+	procRoot    string
+	discover    bool
+	filter      system.MountFilter
+	source      MetadataSource
+	interval    time.Duration
+	logger      Logger
+
+	mu     sync.RWMutex
+	pods   map[string]PodMetadata
+	mounts []string
+}
+
+// NewEnricher creates an enricher for cfg. The metadata source is a file or
+// HTTP endpoint when configured, otherwise only path-derived labels are set.
+func NewEnricher(cfg config.KubernetesConfig, logger Logger) *Enricher {
+	e := &Enricher{
+		kubeletRoot: filepath.Clean(cfg.KubeletRoot),
+		procRoot:    "/proc",
+		discover:    cfg.Discover,
+		filter:      cfg.MountFilter(),
+		interval:    cfg.RefreshInterval,
+		logger:      logger,
+		pods:        make(map[string]PodMetadata),
+	}
+
+	switch {
+	case cfg.MetadataFile != "":
+		e.source = NewFileSource(cfg.MetadataFile)
+	case cfg.MetadataURL != "":
+		e.source = NewHTTPSource(cfg.MetadataURL, cfg.Timeout)
+	}
+	return e
+}
+
+// NeedsRefresh reports whether the enricher has anything to refresh
+func (e *Enricher) NeedsRefresh() bool {
+	return e.discover || e.source != nil
+}
+
+// Run refreshes discovered mounts and metadata every interval until ctx is done
+func (e *Enricher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.Refresh(ctx); err != nil && e.logger != nil {
+			e.logger.Printf("Failed to refresh Kubernetes metadata: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh rediscovers kubelet volume mounts and reloads pod metadata. On
+// failure the previous state is kept.
+func (e *Enricher) Refresh(ctx context.Context) error {
+	if e.discover {
+		entries, err := system.ReadMountInfo(filepath.Join(e.procRoot, "self", "mountinfo"))
+		if err != nil {
+			return err
+		}
+		mounts := DiscoverVolumeMounts(entries, e.kubeletRoot, e.filter)
+
+		e.mu.Lock()
+		e.mounts = mounts
+		e.mu.Unlock()
+	}
+
+	if e.source != nil {
+		pods, err := e.source.Pods(ctx)
+		if err != nil {
+			return err
+		}
+
+		e.mu.Lock()
+		e.pods = pods
+		e.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Mounts returns the kubelet volume mounts found by the last refresh
+func (e *Enricher) Mounts() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]string(nil), e.mounts...)
+}
+
+// Lookup returns the labels of mountPoint, or false when it is not a
+// kubelet volume mount
+func (e *Enricher) Lookup(mountPoint string) (VolumeLabels, bool) {
+	volume, ok := ParseKubeletPath(e.kubeletRoot, mountPoint)
+	if !ok {
+		return VolumeLabels{}, false
+	}
+
+	labels := VolumeLabels{PodUID: volume.PodUID, Volume: volume.Volume}
+
+	e.mu.RLock()
+	pod, found := e.pods[volume.PodUID]
+	e.mu.RUnlock()
+
+	if found {
+		labels.Namespace = pod.Namespace
+		labels.Pod = pod.Name
+		labels.PVC = pod.Volumes[volume.Volume]
+	}
+	return labels, true
+}
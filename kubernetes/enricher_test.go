@@ -0,0 +1,72 @@
+package kubernetes
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+)
+
+func TestEnricher_RefreshAndLookup(t *testing.T) {
+	root := t.TempDir()
+	metadata := filepath.Join(root, "pods.json")
+	os.WriteFile(metadata, []byte(testPodList), 0o644)
+	os.MkdirAll(filepath.Join(root, "proc", "self"), 0o755)
+	os.WriteFile(filepath.Join(root, "proc", "self", "mountinfo"),
+		[]byte("31 22 8:17 / /var/lib/kubelet/pods/u1/volumes/kubernetes.io~csi/pvc-1/mount rw - xfs /dev/sdb rw\n"), 0o644)
+
+	e := NewEnricher(config.KubernetesConfig{
+		Enabled:         true,
+		KubeletRoot:     "/var/lib/kubelet",
+		Discover:        true,
+		MetadataFile:    metadata,
+		RefreshInterval: time.Minute,
+	}, nil)
+	e.procRoot = filepath.Join(root, "proc")
+
+	mountPoint := "/var/lib/kubelet/pods/u1/volumes/kubernetes.io~csi/pvc-1/mount"
+
+	// Before the first refresh only path-derived labels are known
+	labels, ok := e.Lookup(mountPoint)
+	if !ok || labels != (VolumeLabels{PodUID: "u1", Volume: "pvc-1"}) {
+		t.Errorf("Unexpected labels before refresh: %+v, %v", labels, ok)
+	}
+
+	if err := e.Refresh(context.Background()); err != nil {
+		t.Fatalf("Unexpected refresh error: %v", err)
+	}
+
+	if mounts := e.Mounts(); len(mounts) != 1 || mounts[0] != mountPoint {
+		t.Errorf("Unexpected discovered mounts: %v", mounts)
+	}
+
+	expected := VolumeLabels{PodUID: "u1", Volume: "pvc-1", Namespace: "shop", Pod: "db-0", PVC: "data-db-0"}
+	if labels, _ := e.Lookup(mountPoint); labels != expected {
+		t.Errorf("Expected %+v, got %+v", expected, labels)
+	}
+
+	if _, ok := e.Lookup("/data"); ok {
+		t.Error("Expected no labels for a non-kubelet mount")
+	}
+
+	// A failed refresh keeps the previous metadata
+	os.Remove(metadata)
+	if err := e.Refresh(context.Background()); err == nil {
+		t.Error("Expected refresh error for missing metadata file")
+	}
+	if labels, _ := e.Lookup(mountPoint); labels != expected {
+		t.Errorf("Expected stale labels to be kept, got %+v", labels)
+	}
+}
+
+func TestEnricher_NeedsRefresh(t *testing.T) {
+	if NewEnricher(config.KubernetesConfig{KubeletRoot: "/var/lib/kubelet"}, nil).NeedsRefresh() {
+		t.Error("Expected no refresh without discovery or metadata source")
+	}
+	if !NewEnricher(config.KubernetesConfig{KubeletRoot: "/var/lib/kubelet", MetadataURL: "http://localhost/pods", Timeout: time.Second}, nil).NeedsRefresh() {
+		t.Error("Expected refresh with a metadata source")
+	}
+}
@@ -0,0 +1,63 @@
+package kubernetes
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/mount-exporter/mount-exporter/system"
+)
+
+// KubeletVolume identifies a pod volume from its kubelet mount path
+type KubeletVolume struct {
+	PodUID string
+	// Plugin is the volume plugin directory, e.g. "kubernetes.io~csi";
+	// empty for subPath mounts
+	Plugin string
+	// Volume is the volume directory name: the pod volume name, or the
+	// PersistentVolume name for volumes backed by one
+	Volume string
+}
+
+// ParseKubeletPath maps a mount path under kubeletRoot to its pod volume.
+// It recognises <root>/pods/<uid>/volumes/<plugin>/<volume>[/...] and
+// <root>/pods/<uid>/volume-subpaths/<volume>/<container>/<index>.
+func ParseKubeletPath(kubeletRoot, path string) (KubeletVolume, bool) {
+	rel, err := filepath.Rel(filepath.Join(kubeletRoot, "pods"), path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return KubeletVolume{}, false
+	}
+
+	parts := strings.Split(rel, string(filepath.Separator))
+	if len(parts) < 4 || parts[0] == "" {
+		return KubeletVolume{}, false
+	}
+
+	switch parts[1] {
+	case "volumes":
+		return KubeletVolume{PodUID: parts[0], Plugin: parts[2], Volume: parts[3]}, true
+	case "volume-subpaths":
+		return KubeletVolume{PodUID: parts[0], Volume: parts[2]}, true
+	default:
+		return KubeletVolume{}, false
+	}
+}
+
+// DiscoverVolumeMounts returns the mount points of pod volumes under
+// kubeletRoot, in mount table order and without duplicates. Mounts whose
+// class is excluded by filter are skipped.
+func DiscoverVolumeMounts(entries []system.MountInfo, kubeletRoot string, filter system.MountFilter) []string {
+	classes := system.ClassifyMountTable(entries)
+
+	var mounts []string
+	seen := make(map[string]bool)
+	for i, entry := range entries {
+		if seen[entry.MountPoint] || filter.Excludes(classes[i]) {
+			continue
+		}
+		if _, ok := ParseKubeletPath(kubeletRoot, entry.MountPoint); ok {
+			seen[entry.MountPoint] = true
+			mounts = append(mounts, entry.MountPoint)
+		}
+	}
+	return mounts
+}
@@ -0,0 +1,79 @@
+package kubernetes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mount-exporter/mount-exporter/system"
+)
+
+func TestParseKubeletPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected KubeletVolume
+		ok       bool
+	}{
+		{
+			"/var/lib/kubelet/pods/1234-abcd/volumes/kubernetes.io~csi/pvc-5678/mount",
+			KubeletVolume{PodUID: "1234-abcd", Plugin: "kubernetes.io~csi", Volume: "pvc-5678"}, true,
+		},
+		{
+			"/var/lib/kubelet/pods/1234-abcd/volumes/kubernetes.io~empty-dir/cache",
+			KubeletVolume{PodUID: "1234-abcd", Plugin: "kubernetes.io~empty-dir", Volume: "cache"}, true,
+		},
+		{
+			"/var/lib/kubelet/pods/1234-abcd/volume-subpaths/config/app/0",
+			KubeletVolume{PodUID: "1234-abcd", Volume: "config"}, true,
+		},
+		{"/var/lib/kubelet/pods/1234-abcd/volumes", KubeletVolume{}, false},
+		{"/var/lib/kubelet/pods/1234-abcd/etc-hosts/x/y", KubeletVolume{}, false},
+		{"/var/lib/kubelet/plugins/kubernetes.io/csi/pv/x/globalmount", KubeletVolume{}, false},
+		{"/data", KubeletVolume{}, false},
+	}
+
+	for _, tt := range tests {
+		volume, ok := ParseKubeletPath("/var/lib/kubelet", tt.path)
+		if ok != tt.ok || volume != tt.expected {
+			t.Errorf("ParseKubeletPath(%s) = %+v, %v; expected %+v, %v", tt.path, volume, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestDiscoverVolumeMounts(t *testing.T) {
+	entries, err := system.ParseMountInfo(strings.NewReader(`22 1 8:1 / / rw - ext4 /dev/sda1 rw
+30 22 0:40 / /var/lib/kubelet/pods/u1/volumes/kubernetes.io~projected/token rw - tmpfs tmpfs rw
+31 22 8:17 / /var/lib/kubelet/pods/u2/volumes/kubernetes.io~csi/pvc-1/mount rw - xfs /dev/sdb rw
+32 22 8:17 / /var/lib/kubelet/plugins/kubernetes.io/csi/driver/x/globalmount rw - xfs /dev/sdb rw
+33 22 8:17 / /var/lib/kubelet/pods/u2/volumes/kubernetes.io~csi/pvc-1/mount rw - xfs /dev/sdb rw
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse mountinfo: %v", err)
+	}
+
+	mounts := DiscoverVolumeMounts(entries, "/var/lib/kubelet", system.MountFilter{})
+	expected := []string{
+		"/var/lib/kubelet/pods/u1/volumes/kubernetes.io~projected/token",
+		"/var/lib/kubelet/pods/u2/volumes/kubernetes.io~csi/pvc-1/mount",
+	}
+	if strings.Join(mounts, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, mounts)
+	}
+}
+
+func TestDiscoverVolumeMounts_Filter(t *testing.T) {
+	entries, err := system.ParseMountInfo(strings.NewReader(`22 1 8:1 / / rw - ext4 /dev/sda1 rw
+30 22 0:40 / /var/lib/kubelet/pods/u1/volumes/kubernetes.io~projected/token rw - tmpfs tmpfs rw
+31 22 8:1 /srv/data /var/lib/kubelet/pods/u2/volumes/kubernetes.io~host-path/data rw - ext4 /dev/sda1 rw
+32 22 8:17 / /var/lib/kubelet/pods/u3/volumes/kubernetes.io~csi/pvc-1/mount rw - xfs /dev/sdb rw
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse mountinfo: %v", err)
+	}
+
+	filter := system.MountFilter{Kinds: []system.MountKind{system.MountKindTmpfs, system.MountKindBind}}
+	mounts := DiscoverVolumeMounts(entries, "/var/lib/kubelet", filter)
+	expected := []string{"/var/lib/kubelet/pods/u3/volumes/kubernetes.io~csi/pvc-1/mount"}
+	if strings.Join(mounts, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, mounts)
+	}
+}
@@ -0,0 +1,104 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// PodMetadata describes a pod running on the node
+type PodMetadata struct {
+	UID       string `json:"uid"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Volumes maps a volume directory name (the pod volume name, or the
+	// PersistentVolume name for volumes backed by one) to its PVC name
+	Volumes map[string]string `json:"volumes,omitempty"`
+}
+
+// podList is the document served by metadata sources
+type podList struct {
+	Pods []PodMetadata `json:"pods"`
+}
+
+// MetadataSource provides the metadata of the pods on the node, by UID
+type MetadataSource interface {
+	Pods(ctx context.Context) (map[string]PodMetadata, error)
+}
+
+// FileSource reads pod metadata from a JSON file, e.g. one maintained by a
+// sidecar watching the API server
+type FileSource struct {
+	path string
+}
+
+// NewFileSource creates a metadata source reading path
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+// Pods implements MetadataSource
+func (s *FileSource) Pods(ctx context.Context) (map[string]PodMetadata, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pod metadata: %w", err)
+	}
+	return parsePodList(data)
+}
+
+// HTTPSource fetches pod metadata as JSON from an HTTP endpoint
+type HTTPSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSource creates a metadata source fetching url with the given timeout
+func NewHTTPSource(url string, timeout time.Duration) *HTTPSource {
+	return &HTTPSource{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Pods implements MetadataSource
+func (s *HTTPSource) Pods(ctx context.Context) (map[string]PodMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pod metadata request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pod metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch pod metadata: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pod metadata: %w", err)
+	}
+	return parsePodList(data)
+}
+
+// parsePodList decodes a pod list document and indexes it by UID
+func parsePodList(data []byte) (map[string]PodMetadata, error) {
+	var list podList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse pod metadata: %w", err)
+	}
+
+	pods := make(map[string]PodMetadata, len(list.Pods))
+	for _, pod := range list.Pods {
+		if pod.UID == "" {
+			return nil, fmt.Errorf("pod %s/%s has no uid", pod.Namespace, pod.Name)
+		}
+		pods[pod.UID] = pod
+	}
+	return pods, nil
+}
@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testPodList = `{"pods": [
+	{"uid": "u1", "namespace": "shop", "name": "db-0", "volumes": {"pvc-1": "data-db-0"}},
+	{"uid": "u2", "namespace": "shop", "name": "web-7f9c"}
+]}`
+
+func TestFileSource_Pods(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pods.json")
+	if err := os.WriteFile(path, []byte(testPodList), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pods, err := NewFileSource(path).Pods(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(pods) != 2 || pods["u1"].Name != "db-0" || pods["u1"].Volumes["pvc-1"] != "data-db-0" {
+		t.Errorf("Unexpected pods: %+v", pods)
+	}
+
+	if _, err := NewFileSource(filepath.Join(t.TempDir(), "missing")).Pods(context.Background()); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestHTTPSource_Pods(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pods" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testPodList))
+	}))
+	defer server.Close()
+
+	pods, err := NewHTTPSource(server.URL+"/pods", time.Second).Pods(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if pods["u2"].Namespace != "shop" {
+		t.Errorf("Unexpected pods: %+v", pods)
+	}
+
+	if _, err := NewHTTPSource(server.URL+"/other", time.Second).Pods(context.Background()); err == nil {
+		t.Error("Expected error for non-200 response")
+	}
+}
+
+func TestParsePodList_Invalid(t *testing.T) {
+	for _, input := range []string{`{"pods": [`, `{"pods": [{"name": "no-uid"}]}`} {
+		if _, err := parsePodList([]byte(input)); err == nil {
+			t.Errorf("Expected error for %s", input)
+		}
+	}
+}
@@ -50,6 +50,11 @@ func main() {
 
 // runApplication contains the main application logic
 func runApplication() error {
+	// Subcommands come before any flags, e.g. "mount-exporter generate rules"
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		return runCommand(os.Args[1:])
+	}
+
 	flag.Parse()
 
 	if *showHelp {
@@ -65,6 +70,12 @@ func runApplication() error {
 	// Initialize logging
 	logger := log.New(os.Stderr, "[mount-exporter] ", log.LstdFlags)
 
+	// Load configuration
+	cfg, err := loadConfiguration(*configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
 	// Initialize panic recovery with custom logger
 	panicHandler := recovery.NewPanicHandler(recovery.PanicRecoveryConfig{
 		Enabled: true,
@@ -75,13 +86,17 @@ func runApplication() error {
 				logger.Printf("APPLICATION PANIC: %v at %s", info.PanicValue, info.Timestamp.Format(time.RFC3339))
 			},
 		},
+		CrashReportDir:  cfg.Recovery.CrashReportDir,
+		MaxCrashReports: cfg.Recovery.MaxCrashReports,
+		BuildInfo: map[string]string{
+			"version":    version,
+			"git_commit": gitCommit,
+			"build_time": buildTime,
+		},
+		MaxPanics:   cfg.Recovery.MaxPanics,
+		PanicWindow: cfg.Recovery.PanicWindow,
 	})
-
-	// Load configuration
-	cfg, err := loadConfiguration(*configFile)
-	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
-	}
+	panicHandler.SetConfigHash(cfg.Hash())
 
 	// Override log level if specified
 	if *logLevel != "" {
@@ -116,6 +131,11 @@ func runApplication() error {
 	// Set server version
 	server.SetVersion(version)
 
+	// Export panic metrics
+	if err := srv.SetPanicHandler(panicHandler); err != nil {
+		return fmt.Errorf("failed to register panic handler: %w", err)
+	}
+
 	// Start the server with panic recovery
 	if err := panicHandler.RecoverWithFunc(func() error {
 		return srv.Start()
@@ -202,6 +222,17 @@ A Prometheus exporter for monitoring filesystem mount point availability.
 
 USAGE:
     mount-exporter [OPTIONS]
+    mount-exporter COMMAND [OPTIONS]
+
+COMMANDS:
+    generate rules       Print Prometheus alerting and recording rules
+    generate dashboard   Print a Grafana dashboard
+        Both read the alerting section of the configuration and accept
+        -config and -output (default: standard output)
+    config check FILE    Report every error, and unknown keys as warnings, in FILE;
+        with -dry-run, also collect once and print what would be exported
+    config schema        Print the JSON Schema of the configuration file;
+        accepts -output (default: standard output)
 
 OPTIONS:
     -config string
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/system"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// blockDeviceDescs are the descriptors of the block device I/O metrics
+type blockDeviceDescs struct {
+	readsCompleted  *prometheus.Desc
+	writesCompleted *prometheus.Desc
+	readBytes       *prometheus.Desc
+	writtenBytes    *prometheus.Desc
+	readTime        *prometheus.Desc
+	writeTime       *prometheus.Desc
+	ioTime          *prometheus.Desc
+	timeInQueue     *prometheus.Desc
+	inFlight        *prometheus.Desc
+}
+
+// newBlockDeviceDescs creates the block device metric descriptors
+func newBlockDeviceDescs(descs *descriptorSet) *blockDeviceDescs {
+	labels := []string{"mount_point", "device", "dm_name"}
+	desc := func(name, help string) *prometheus.Desc {
+		return descs.newDesc(prometheus.BuildFQName(namespace, "block_device", name), help, labels)
+	}
+
+	return &blockDeviceDescs{
+		readsCompleted:  desc("reads_completed_total", "Total number of reads completed by the device backing the mount point"),
+		writesCompleted: desc("writes_completed_total", "Total number of writes completed by the device backing the mount point"),
+		readBytes:       desc("read_bytes_total", "Total number of bytes read from the device backing the mount point"),
+		writtenBytes:    desc("written_bytes_total", "Total number of bytes written to the device backing the mount point"),
+		readTime:        desc("read_time_seconds_total", "Total time spent on reads by the device backing the mount point"),
+		writeTime:       desc("write_time_seconds_total", "Total time spent on writes by the device backing the mount point"),
+		ioTime:          desc("io_time_seconds_total", "Total time the device backing the mount point had I/O in progress"),
+		timeInQueue:     desc("io_time_weighted_seconds_total", "Total time I/O requests spent queued or in progress, weighted by the number of requests"),
+		inFlight:        desc("io_now", "Number of I/O requests currently in progress on the device backing the mount point"),
+	}
+}
+
+// describe sends all block device descriptors to ch
+func (d *blockDeviceDescs) describe(ch chan<- *prometheus.Desc) {
+	ch <- d.readsCompleted
+	ch <- d.writesCompleted
+	ch <- d.readBytes
+	ch <- d.writtenBytes
+	ch <- d.readTime
+	ch <- d.writeTime
+	ch <- d.ioTime
+	ch <- d.timeInQueue
+	ch <- d.inFlight
+}
+
+// newBlockDeviceReader returns a block device reader for cfg, or nil when disabled
+func newBlockDeviceReader(cfg *config.Config) *system.BlockDeviceReader {
+	if !cfg.BlockDevices.Enabled {
+		return nil
+	}
+	return system.NewBlockDeviceReader(system.BlockDeviceReaderConfig{})
+}
+
+// collectBlockDevices exports I/O statistics of the devices backing the
+// collected mount points. Mounts in other namespaces and mounts without a
+// block device (NFS, tmpfs, overlay, ...) are skipped.
+func (c *Collector) collectBlockDevices(ch chan<- prometheus.Metric, mounts []config.MountConfig) bool {
+	var mountPoints []string
+	seen := make(map[string]bool)
+	for _, m := range mounts {
+		if mountNamespace(m).IsHost() && !seen[m.Path] {
+			seen[m.Path] = true
+			mountPoints = append(mountPoints, m.Path)
+		}
+	}
+
+	stats, err := c.blockDevices.Stats(mountPoints)
+
+	d := c.blockDeviceDescs
+	for _, s := range stats {
+		labels := []string{s.MountPoint, s.Device, s.DMName}
+		counter := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, labels...)
+		}
+
+		counter(d.readsCompleted, float64(s.Stat.ReadsCompleted))
+		counter(d.writesCompleted, float64(s.Stat.WritesCompleted))
+		counter(d.readBytes, float64(s.Stat.ReadBytes()))
+		counter(d.writtenBytes, float64(s.Stat.WrittenBytes()))
+		counter(d.readTime, s.Stat.ReadTime.Seconds())
+		counter(d.writeTime, s.Stat.WriteTime.Seconds())
+		counter(d.ioTime, s.Stat.IOTime.Seconds())
+		counter(d.timeInQueue, s.Stat.TimeInQueue.Seconds())
+		ch <- prometheus.MustNewConstMetric(d.inFlight, prometheus.GaugeValue, float64(s.Stat.InFlight), labels...)
+	}
+
+	return err == nil
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/system"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector_BlockDevices(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "proc", "self"), 0o755)
+	os.WriteFile(filepath.Join(root, "proc", "self", "mountinfo"),
+		[]byte("36 22 253:2 / /data rw - xfs /dev/mapper/vg-data rw\n37 22 0:50 / /mnt/nfs rw - nfs4 nas:/export rw\n"), 0o644)
+	dm := filepath.Join(root, "sys", "devices", "virtual", "block", "dm-2")
+	os.MkdirAll(filepath.Join(dm, "dm"), 0o755)
+	os.WriteFile(filepath.Join(dm, "stat"), []byte("10 0 16 1500 20 0 32 2500 3 4000 5000\n"), 0o644)
+	os.WriteFile(filepath.Join(dm, "dm", "name"), []byte("vg-data\n"), 0o644)
+	os.MkdirAll(filepath.Join(root, "sys", "dev", "block"), 0o755)
+	os.Symlink(dm, filepath.Join(root, "sys", "dev", "block", "253:2"))
+
+	cfg := &config.Config{
+		MountPoints:  []string{"/data", "/mnt/nfs"},
+		Interval:     5 * time.Second,
+		BlockDevices: config.BlockDevicesConfig{Enabled: true},
+	}
+	collector := NewCollector(cfg)
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusMounted}
+	}
+	collector.blockDevices = system.NewBlockDeviceReader(system.BlockDeviceReaderConfig{
+		ProcRoot: filepath.Join(root, "proc"),
+		SysRoot:  filepath.Join(root, "sys"),
+	})
+
+	expected := `
+# HELP mount_exporter_block_device_reads_completed_total Total number of reads completed by the device backing the mount point
+# TYPE mount_exporter_block_device_reads_completed_total counter
+mount_exporter_block_device_reads_completed_total{device="dm-2",dm_name="vg-data",mount_point="/data"} 10
+# HELP mount_exporter_block_device_written_bytes_total Total number of bytes written to the device backing the mount point
+# TYPE mount_exporter_block_device_written_bytes_total counter
+mount_exporter_block_device_written_bytes_total{device="dm-2",dm_name="vg-data",mount_point="/data"} 16384
+# HELP mount_exporter_block_device_io_time_weighted_seconds_total Total time I/O requests spent queued or in progress, weighted by the number of requests
+# TYPE mount_exporter_block_device_io_time_weighted_seconds_total counter
+mount_exporter_block_device_io_time_weighted_seconds_total{device="dm-2",dm_name="vg-data",mount_point="/data"} 5
+# HELP mount_exporter_block_device_io_now Number of I/O requests currently in progress on the device backing the mount point
+# TYPE mount_exporter_block_device_io_now gauge
+mount_exporter_block_device_io_now{device="dm-2",dm_name="vg-data",mount_point="/data"} 3
+# HELP mount_exporter_up Whether the mount exporter is healthy (1=healthy, 0=unhealthy)
+# TYPE mount_exporter_up gauge
+mount_exporter_up 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"mount_exporter_block_device_reads_completed_total",
+		"mount_exporter_block_device_written_bytes_total",
+		"mount_exporter_block_device_io_time_weighted_seconds_total",
+		"mount_exporter_block_device_io_now",
+		"mount_exporter_up"); err != nil {
+		t.Errorf("Unexpected block device metrics: %v", err)
+	}
+}
@@ -2,10 +2,14 @@ package metrics
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/kubernetes"
+	"github.com/mount-exporter/mount-exporter/recovery"
 	"github.com/mount-exporter/mount-exporter/system"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -15,49 +19,157 @@ const (
 	subsystem = ""
 )
 
+// ScrapeGuard admits collections, e.g. to enforce resource limits. The
+// returned function is called when the collection ends.
+type ScrapeGuard interface {
+	BeginScrape() (func(), error)
+}
+
 // Collector collects mount point metrics
 type Collector struct {
-	config     *config.Config
-	findmnt    *system.FindmntWrapper
-	mu         sync.RWMutex
+	config       *config.Config
+	findmnt      *system.FindmntWrapper
+	drift        *system.DriftDetector
+	blockDevices *system.BlockDeviceReader
+	panicHandler *recovery.PanicHandler
+	guard        ScrapeGuard
+	kube         *kubernetes.Enricher
+	mu           sync.RWMutex
+
+	// mountPoints restricts collection to a subset; nil means all configured
+	mountPoints []string
+	// mountStatsPath is the NFS mountstats file; empty disables NFS metrics
+	mountStatsPath string
+	// kubeLabels adds Kubernetes labels to mount_point_status; fixed at
+	// construction because registered descriptors cannot change
+	kubeLabels bool
+
+	// checkFunc overrides the findmnt check (for testing)
+	checkFunc func(ctx context.Context, mountPoint string) *system.FindmntResult
+	// autofsFunc overrides the autofs check (for testing)
+	autofsFunc func(ctx context.Context, mountPoint string) *system.AutofsResult
+	// classesFunc overrides the host mount table classification (for testing)
+	classesFunc func() (map[string]system.MountClass, error)
 
 	// Metrics
 	mountPointStatus *prometheus.Desc
 	scrapeDuration   *prometheus.Desc
 	scrapeSuccess    *prometheus.Desc
 	up               *prometheus.Desc
+	totalDuration    *prometheus.Desc
+	autofsState      *prometheus.Desc
+	mountInfo        *prometheus.Desc
+	mountOption      *prometheus.Desc
+
+	// Drift detection metrics
+	declaredMounted    *prometheus.Desc
+	declaredMatch      *prometheus.Desc
+	mountPointDeclared *prometheus.Desc
+
+	// Block device and NFS metrics
+	blockDeviceDescs *blockDeviceDescs
+	nfsDescs         *nfsDescs
+
+	// descs lists the metric families above
+	descs *descriptorSet
 }
 
 // NewCollector creates a new metrics collector
 func NewCollector(cfg *config.Config) *Collector {
+	descs := &descriptorSet{}
 	return &Collector{
-		config:  cfg,
-		findmnt: system.NewFindmntWrapper(cfg.Interval),
-		mountPointStatus: prometheus.NewDesc(
+		config:       cfg,
+		findmnt:      system.NewFindmntWrapper(cfg.Interval),
+		drift:        newDriftDetector(cfg),
+		blockDevices: newBlockDeviceReader(cfg),
+		panicHandler: recovery.NewPanicHandler(recovery.PanicRecoveryConfig{Enabled: true}),
+		mountPointStatus: descs.newDesc(
 			prometheus.BuildFQName(namespace, subsystem, "mount_point_status"),
 			"Mount point availability status (1=mounted, 0=not mounted)",
-			[]string{"mount_point", "target", "fs_type", "source", "error"},
-			nil,
+			mountPointStatusLabels(cfg),
 		),
-		scrapeDuration: prometheus.NewDesc(
+		scrapeDuration: descs.newDesc(
 			prometheus.BuildFQName(namespace, subsystem, "scrape_duration_seconds"),
 			"Time spent scraping mount point status",
-			[]string{"mount_point"},
-			nil,
+			[]string{"mount_point", "namespace"},
 		),
-		scrapeSuccess: prometheus.NewDesc(
+		scrapeSuccess: descs.newDesc(
 			prometheus.BuildFQName(namespace, subsystem, "scrape_success_total"),
 			"Total number of successful scrapes",
-			[]string{"mount_point"},
-			nil,
+			[]string{"mount_point", "namespace"},
 		),
-		up: prometheus.NewDesc(
+		up: descs.newDesc(
 			prometheus.BuildFQName(namespace, subsystem, "up"),
 			"Whether the mount exporter is healthy (1=healthy, 0=unhealthy)",
 			nil,
+		),
+		totalDuration: descs.newDesc(
+			prometheus.BuildFQName(namespace, subsystem, "total_scrape_duration_seconds"),
+			"Total time spent scraping all mount points",
 			nil,
 		),
+		autofsState: descs.newDesc(
+			prometheus.BuildFQName(namespace, subsystem, "autofs_state"),
+			"State of an autofs-managed mount point (1 for the current state)",
+			[]string{"mount_point", "state"},
+		),
+		mountInfo: descs.newDesc(
+			prometheus.BuildFQName(namespace, subsystem, "mount_info"),
+			"Information about a mounted mount point (always 1)",
+			[]string{"mount_point", "namespace", "fs_type", "source", "propagation", "read_only", "mount_kind"},
+		),
+		mountOption: descs.newDesc(
+			prometheus.BuildFQName(namespace, subsystem, "mount_option"),
+			"Security and behavior relevant mount options set on a mount point (always 1)",
+			[]string{"mount_point", "namespace", "option"},
+		),
+		declaredMounted: descs.newDesc(
+			prometheus.BuildFQName(namespace, subsystem, "declared_mount_mounted"),
+			"Whether a mount declared in fstab or a systemd mount unit is mounted (1=mounted, 0=not mounted)",
+			[]string{"mount_point", "origin", "source", "fs_type", "noauto"},
+		),
+		declaredMatch: descs.newDesc(
+			prometheus.BuildFQName(namespace, subsystem, "declared_mount_match"),
+			"Whether a mounted declared mount matches its declaration (1=matches, 0=drifted)",
+			[]string{"mount_point", "origin", "noauto", "field"},
+		),
+		mountPointDeclared: descs.newDesc(
+			prometheus.BuildFQName(namespace, subsystem, "mount_point_declared"),
+			"Whether a configured mount point is declared in fstab or a systemd mount unit (1=declared, 0=not declared)",
+			[]string{"mount_point"},
+		),
+		mountStatsPath:   newMountStatsPath(cfg),
+		kubeLabels:       cfg.Kubernetes.Enabled,
+		blockDeviceDescs: newBlockDeviceDescs(descs),
+		nfsDescs:         newNFSDescs(descs),
+		descs:            descs,
+	}
+}
+
+// kubernetesLabels are added to mount_point_status when Kubernetes
+// enrichment is enabled
+var kubernetesLabels = []string{"pod_uid", "volume", "pod_namespace", "pod", "pvc"}
+
+// mountPointStatusLabels returns the mount_point_status labels for cfg
+func mountPointStatusLabels(cfg *config.Config) []string {
+	labels := []string{"mount_point", "namespace", "target", "fs_type", "source", "error"}
+	if cfg.Kubernetes.Enabled {
+		labels = append(labels, kubernetesLabels...)
 	}
+	return labels
+}
+
+// newDriftDetector returns a drift detector for cfg, or nil when disabled
+func newDriftDetector(cfg *config.Config) *system.DriftDetector {
+	if !cfg.Drift.Enabled {
+		return nil
+	}
+
+	detector := system.DriftDetectorConfig{FstabPath: cfg.Drift.FstabPath}
+	if cfg.Drift.SystemdUnits {
+		detector.UnitDirs = cfg.Drift.SystemdUnitDirs
+	}
+	return system.NewDriftDetector(detector)
 }
 
 // Describe implements prometheus.Collector interface
@@ -66,6 +178,20 @@ func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.scrapeDuration
 	ch <- c.scrapeSuccess
 	ch <- c.up
+	ch <- c.totalDuration
+	ch <- c.autofsState
+	ch <- c.mountInfo
+	ch <- c.mountOption
+	ch <- c.declaredMounted
+	ch <- c.declaredMatch
+	ch <- c.mountPointDeclared
+	c.blockDeviceDescs.describe(ch)
+	c.nfsDescs.describe(ch)
+}
+
+// Descriptors implements Describer interface
+func (c *Collector) Descriptors() []MetricDescriptor {
+	return c.descs.list()
 }
 
 // Collect implements prometheus.Collector interface
@@ -76,10 +202,27 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 	start := time.Now()
 	healthy := 1
 
-	// Check all mount points
-	for _, mountPoint := range c.config.MountPoints {
+	// Refuse the collection while the guard rejects it, reporting unhealthy
+	if c.guard != nil {
+		end, err := c.guard.BeginScrape()
+		if err != nil {
+			ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+			return
+		}
+		defer end()
+	}
+
+	// Host mounts are classified from one read of the mount table per scrape
+	var hostClasses map[string]system.MountClass
+	hostClassified := false
+
+	// Check all selected mount points
+	mounts := c.selectedMounts()
+	for _, mount := range mounts {
+		mountPoint := mount.Path
+		namespace := mountNamespace(mount)
 		scrapeStart := time.Now()
-		result := c.findmnt.CheckMountPoint(context.Background(), mountPoint)
+		result := c.checkMountPoint(context.Background(), mountPoint, namespace)
 		scrapeDuration := time.Since(scrapeStart).Seconds()
 
 		var value float64
@@ -105,20 +248,52 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 		fsType = result.FSType
 		source = result.Source
 
+		// An autofs trigger looks mounted; report the real filesystem instead
+		if c.config.Autofs.Enabled && namespace.IsHost() && result.Error == nil {
+			if autofs := c.checkAutofs(context.Background(), mountPoint); autofs != nil && autofs.State != system.AutofsNone {
+				switch autofs.State {
+				case system.AutofsMounted:
+					fsType = autofs.FSType
+					source = autofs.Source
+				case system.AutofsTriggerFailed:
+					healthy = 0
+					value = 0
+					errorMsg = autofs.Error.Error()
+				}
+				c.collectAutofsState(ch, mountPoint, autofs.State)
+			}
+		}
+
 		// Export mount point status metric
+		labels := []string{mountPoint, namespace.Label(), target, fsType, source, errorMsg}
+		if c.kubeLabels {
+			labels = append(labels, c.kubernetesLabelValues(mount)...)
+		}
 		ch <- prometheus.MustNewConstMetric(
 			c.mountPointStatus,
 			prometheus.GaugeValue,
 			value,
-			mountPoint, target, fsType, source, errorMsg,
+			labels...,
 		)
 
+		if value == 1 {
+			kind := result.Kind
+			if namespace.IsHost() {
+				if !hostClassified {
+					hostClasses = c.hostMountClasses()
+					hostClassified = true
+				}
+				kind = hostClasses[mountPoint].Kind
+			}
+			c.collectMountInfo(ch, result, namespace.Label(), fsType, source, kind)
+		}
+
 		// Export scrape duration metric
 		ch <- prometheus.MustNewConstMetric(
 			c.scrapeDuration,
 			prometheus.GaugeValue,
 			scrapeDuration,
-			mountPoint,
+			mountPoint, namespace.Label(),
 		)
 
 		// Export scrape success metric (increment on success)
@@ -127,11 +302,23 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 				c.scrapeSuccess,
 				prometheus.CounterValue,
 				1,
-				mountPoint,
+				mountPoint, namespace.Label(),
 			)
 		}
 	}
 
+	if c.drift != nil && !c.collectDrift(ch, mounts) {
+		healthy = 0
+	}
+
+	if c.blockDevices != nil && !c.collectBlockDevices(ch, mounts) {
+		healthy = 0
+	}
+
+	if c.mountStatsPath != "" && !c.collectNFS(ch, mounts) {
+		healthy = 0
+	}
+
 	// Export overall health metric
 	ch <- prometheus.MustNewConstMetric(
 		c.up,
@@ -141,24 +328,331 @@ func (c *Collector) Collect(ch chan<- prometheus.Metric) {
 
 	// Export total scrape duration
 	ch <- prometheus.MustNewConstMetric(
-		prometheus.NewDesc(
-			prometheus.BuildFQName(namespace, subsystem, "total_scrape_duration_seconds"),
-			"Total time spent scraping all mount points",
-			nil,
-			nil,
-		),
+		c.totalDuration,
 		prometheus.GaugeValue,
 		time.Since(start).Seconds(),
 	)
 }
 
+// infoMountOptions are the options exported by mount_option. Entries ending
+// in "=" match any value, which is kept in the label.
+var infoMountOptions = []string{"ro", "noexec", "nosuid", "nodev", "relatime", "vers=", "sec="}
+
+// collectMountInfo exports mount_info and the allow-listed mount options of
+// a mounted mount point
+func (c *Collector) collectMountInfo(ch chan<- prometheus.Metric, result *system.FindmntResult, namespace, fsType, source string, kind system.MountKind) {
+	ch <- prometheus.MustNewConstMetric(
+		c.mountInfo,
+		prometheus.GaugeValue,
+		1,
+		result.MountPoint, namespace, fsType, source, result.Propagation, strconv.FormatBool(result.ReadOnly()), kind.String(),
+	)
+
+	for _, opt := range result.OptionList() {
+		if IsExportedMountOption(opt) {
+			ch <- prometheus.MustNewConstMetric(c.mountOption, prometheus.GaugeValue, 1, result.MountPoint, namespace, opt)
+		}
+	}
+}
+
+// IsExportedMountOption reports whether opt is exported by mount_option
+func IsExportedMountOption(opt string) bool {
+	for _, allowed := range infoMountOptions {
+		if opt == allowed || (strings.HasSuffix(allowed, "=") && strings.HasPrefix(opt, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostMountClasses classifies the exporter's own mount table by mount point.
+// Classification is best effort: on failure mounts are reported as unknown.
+func (c *Collector) hostMountClasses() map[string]system.MountClass {
+	var classes map[string]system.MountClass
+	var err error
+	if c.classesFunc != nil {
+		classes, err = c.classesFunc()
+	} else {
+		classes, err = c.findmnt.MountClasses()
+	}
+	if err != nil {
+		return nil
+	}
+	return classes
+}
+
+// collectDrift exports how declared mounts compare to the mount table, and
+// whether the collected mount points are declared at all. In a restricted
+// collection only the selected mount points are reported.
+func (c *Collector) collectDrift(ch chan<- prometheus.Metric, mounts []config.MountConfig) bool {
+	report, err := c.drift.Check()
+	if err != nil {
+		return false
+	}
+
+	selected := make(map[string]bool, len(mounts))
+	for _, m := range mounts {
+		selected[m.Path] = true
+	}
+
+	for _, result := range report.Results {
+		decl := result.Declared
+		if c.mountPoints != nil && !selected[decl.MountPoint] {
+			continue
+		}
+		noauto := strconv.FormatBool(decl.NoAuto())
+
+		ch <- prometheus.MustNewConstMetric(
+			c.declaredMounted,
+			prometheus.GaugeValue,
+			boolToFloat(result.Mounted),
+			decl.MountPoint, decl.Origin, decl.Source, decl.FSType, noauto,
+		)
+
+		if !result.Mounted {
+			continue
+		}
+		for field, match := range map[string]bool{
+			"source":  result.SourceMatch,
+			"fs_type": result.FSTypeMatch,
+			"options": result.OptionsMatch,
+		} {
+			ch <- prometheus.MustNewConstMetric(
+				c.declaredMatch,
+				prometheus.GaugeValue,
+				boolToFloat(match),
+				decl.MountPoint, decl.Origin, noauto, field,
+			)
+		}
+	}
+
+	// Only mounts in the exporter's own namespace can be declared here
+	seen := make(map[string]bool)
+	for _, m := range mounts {
+		if !mountNamespace(m).IsHost() || seen[m.Path] {
+			continue
+		}
+		seen[m.Path] = true
+
+		ch <- prometheus.MustNewConstMetric(
+			c.mountPointDeclared,
+			prometheus.GaugeValue,
+			boolToFloat(report.IsDeclared(m.Path)),
+			m.Path,
+		)
+	}
+
+	return true
+}
+
+// checkAutofs checks whether a mount point is an autofs trigger, triggering
+// it when configured. A panic or unreadable mount table yields nil.
+func (c *Collector) checkAutofs(ctx context.Context, mountPoint string) *system.AutofsResult {
+	var result *system.AutofsResult
+
+	ctx = recovery.WithComponent(ctx, "collector")
+	err := c.panicHandler.RecoverWithContext(ctx, func(ctx context.Context) error {
+		if c.autofsFunc != nil {
+			result = c.autofsFunc(ctx, mountPoint)
+		} else {
+			result = c.findmnt.CheckAutofs(ctx, mountPoint, c.config.Autofs.Trigger, c.config.Autofs.TriggerTimeout)
+		}
+		return nil
+	})
+	if err != nil || (result.State == system.AutofsNone && result.Error != nil) {
+		return nil
+	}
+
+	return result
+}
+
+// collectAutofsState exports one series per autofs state, 1 for the current one
+func (c *Collector) collectAutofsState(ch chan<- prometheus.Metric, mountPoint string, state system.AutofsState) {
+	for _, s := range system.AutofsStates {
+		if s == system.AutofsNone {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(
+			c.autofsState,
+			prometheus.GaugeValue,
+			boolToFloat(s == state),
+			mountPoint, s.String(),
+		)
+	}
+}
+
+// boolToFloat converts a bool to a metric value
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// selectedMounts returns the mounts to collect. A selected path that is
+// configured in several namespaces is collected in each of them; one that is
+// not configured at all is checked in the exporter's own namespace.
+func (c *Collector) selectedMounts() []config.MountConfig {
+	all := c.config.AllMounts()
+	if c.mountPoints == nil {
+		return c.withDiscoveredMounts(all)
+	}
+
+	byPath := make(map[string][]config.MountConfig, len(all))
+	for _, m := range all {
+		byPath[m.Path] = append(byPath[m.Path], m)
+	}
+
+	var selected []config.MountConfig
+	for _, mp := range c.mountPoints {
+		if mounts, ok := byPath[mp]; ok {
+			selected = append(selected, mounts...)
+		} else {
+			selected = append(selected, config.MountConfig{Path: mp})
+		}
+	}
+	return selected
+}
+
+// kubernetesLabelValues returns the Kubernetes label values of a mount, empty
+// for mounts that are not kubelet volume mounts in the exporter's namespace
+func (c *Collector) kubernetesLabelValues(m config.MountConfig) []string {
+	if c.kube != nil && mountNamespace(m).IsHost() {
+		if l, ok := c.kube.Lookup(m.Path); ok {
+			return []string{l.PodUID, l.Volume, l.Namespace, l.Pod, l.PVC}
+		}
+	}
+	return make([]string, len(kubernetesLabels))
+}
+
+// withDiscoveredMounts appends the discovered kubelet volume mounts that are
+// not configured already
+func (c *Collector) withDiscoveredMounts(mounts []config.MountConfig) []config.MountConfig {
+	if c.kube == nil {
+		return mounts
+	}
+
+	configured := make(map[string]bool, len(mounts))
+	for _, m := range mounts {
+		if mountNamespace(m).IsHost() {
+			configured[m.Path] = true
+		}
+	}
+	for _, mp := range c.kube.Mounts() {
+		if !configured[mp] {
+			mounts = append(mounts, config.MountConfig{Path: mp})
+		}
+	}
+	return mounts
+}
+
+// mountNamespace returns the mount namespace a configured mount is checked in
+func mountNamespace(m config.MountConfig) system.MountNamespace {
+	return system.MountNamespace{PID: m.PID, Path: m.Namespace, Container: m.Container}
+}
+
+// checkMountPoint checks a single mount point, turning a panic into an
+// unknown status so the remaining mount points are still exported
+func (c *Collector) checkMountPoint(ctx context.Context, mountPoint string, namespace system.MountNamespace) *system.FindmntResult {
+	var result *system.FindmntResult
+
+	ctx = recovery.WithComponent(ctx, "collector")
+	err := c.panicHandler.RecoverWithContext(ctx, func(ctx context.Context) error {
+		switch {
+		case c.checkFunc != nil:
+			result = c.checkFunc(ctx, mountPoint)
+		case namespace.IsHost():
+			result = c.findmnt.CheckMountPoint(ctx, mountPoint)
+		default:
+			result = c.findmnt.CheckMountPointInNamespace(ctx, mountPoint, namespace)
+		}
+		return nil
+	})
+	if err != nil {
+		return &system.FindmntResult{
+			MountPoint: mountPoint,
+			Status:     system.MountStatusUnknown,
+			Error:      err,
+		}
+	}
+
+	return result
+}
+
+// ForMountPoints returns a collector that shares this collector's checks,
+// panic handler and scrape guard but only collects the given mount points
+func (c *Collector) ForMountPoints(mountPoints []string) *Collector {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return &Collector{
+		config:           c.config,
+		findmnt:          c.findmnt,
+		drift:            c.drift,
+		blockDevices:     c.blockDevices,
+		mountStatsPath:   c.mountStatsPath,
+		panicHandler:     c.panicHandler,
+		guard:            c.guard,
+		kube:             c.kube,
+		kubeLabels:       c.kubeLabels,
+		checkFunc:        c.checkFunc,
+		mountPoints:      append([]string{}, mountPoints...),
+		mountPointStatus: c.mountPointStatus,
+		scrapeDuration:   c.scrapeDuration,
+		scrapeSuccess:    c.scrapeSuccess,
+		up:               c.up,
+		totalDuration:    c.totalDuration,
+		autofsState:      c.autofsState,
+		mountInfo:        c.mountInfo,
+		mountOption:      c.mountOption,
+		autofsFunc:       c.autofsFunc,
+		classesFunc:      c.classesFunc,
+
+		declaredMounted:    c.declaredMounted,
+		declaredMatch:      c.declaredMatch,
+		mountPointDeclared: c.mountPointDeclared,
+		blockDeviceDescs:   c.blockDeviceDescs,
+		nfsDescs:           c.nfsDescs,
+	}
+}
+
+// SetPanicHandler sets the panic handler used to isolate mount point checks
+func (c *Collector) SetPanicHandler(ph *recovery.PanicHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.panicHandler = ph
+}
+
+// SetKubernetesEnricher sets the enricher providing Kubernetes labels and
+// discovered kubelet volume mounts
+func (c *Collector) SetKubernetesEnricher(e *kubernetes.Enricher) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.kube = e
+}
+
+// SetScrapeGuard sets the guard consulted before each collection
+func (c *Collector) SetScrapeGuard(guard ScrapeGuard) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.guard = guard
+}
+
 // UpdateConfig updates the collector configuration
 func (c *Collector) UpdateConfig(cfg *config.Config) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.config = cfg
-	c.findmnt = system.NewFindmntWrapper(cfg.Interval)
+	// Update the wrapper in place: the latency probe and the health check
+	// hold on to it
+	c.findmnt.SetTimeout(cfg.Interval)
+	c.drift = newDriftDetector(cfg)
+	c.blockDevices = newBlockDeviceReader(cfg)
+	c.mountStatsPath = newMountStatsPath(cfg)
 }
 
 // GetFindmntWrapper returns the findmnt wrapper for external use
@@ -1,11 +1,24 @@
 package metrics
 
 import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/kubernetes"
+	"github.com/mount-exporter/mount-exporter/recovery"
+	"github.com/mount-exporter/mount-exporter/system"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 )
 
 func TestNewCollector(t *testing.T) {
@@ -42,7 +55,7 @@ func TestCollector_Describe(t *testing.T) {
 
 	collector := NewCollector(cfg)
 
-	ch := make(chan *prometheus.Desc, 10)
+	ch := make(chan *prometheus.Desc, 40)
 	collector.Describe(ch)
 
 	close(ch)
@@ -55,9 +68,11 @@ func TestCollector_Describe(t *testing.T) {
 		descCount++
 	}
 
-	// Should have 4 descriptors: mount_point_status, scrape_duration, scrape_success, up
-	if descCount != 4 {
-		t.Errorf("Expected 4 descriptors, got %d", descCount)
+	// Should have 28 descriptors: mount_point_status, scrape_duration, scrape_success, up,
+	// total_scrape_duration, autofs_state, mount_info, mount_option, the three drift metrics
+	// the nine block device metrics and the eight NFS metrics
+	if descCount != 28 {
+		t.Errorf("Expected 28 descriptors, got %d", descCount)
 	}
 }
 
@@ -105,6 +120,7 @@ func TestCollector_UpdateConfig(t *testing.T) {
 	}
 
 	collector := NewCollector(cfg1)
+	findmnt := collector.GetFindmntWrapper()
 
 	// Verify initial config
 	if len(collector.config.MountPoints) != 1 || collector.config.MountPoints[0] != "/test1" {
@@ -122,6 +138,14 @@ func TestCollector_UpdateConfig(t *testing.T) {
 	if collector.config.Interval != 60*time.Second {
 		t.Error("Interval not updated correctly")
 	}
+
+	// Users of the wrapper, such as the latency probe, see the new timeout
+	if collector.GetFindmntWrapper() != findmnt {
+		t.Error("Expected the findmnt wrapper to be kept")
+	}
+	if findmnt.Timeout() != 60*time.Second {
+		t.Errorf("Expected timeout to be updated, got %v", findmnt.Timeout())
+	}
 }
 
 func TestCollector_GetFindmntWrapper(t *testing.T) {
@@ -333,4 +357,349 @@ func BenchmarkCollect(b *testing.B) {
 		for range ch {
 		}
 	}
-}
\ No newline at end of file
+}
+func TestCollector_PanicInOneMountPointIsIsolated(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/healthy", "/explodes", "/also-healthy"},
+		Interval:    5 * time.Second,
+	}
+
+	collector := NewCollector(cfg)
+	collector.SetPanicHandler(recovery.NewPanicHandler(recovery.PanicRecoveryConfig{
+		Enabled: true,
+		Logger:  log.New(io.Discard, "", 0),
+	}))
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		if mountPoint == "/explodes" {
+			panic("check exploded")
+		}
+		return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusMounted}
+	}
+
+	ch := make(chan prometheus.Metric, 50)
+	collector.Collect(ch)
+	close(ch)
+
+	values := make(map[string]float64)
+	errorsByMount := make(map[string]string)
+	for metric := range ch {
+		if metric.Desc().String() != collector.mountPointStatus.String() {
+			continue
+		}
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+		labels := make(map[string]string)
+		for _, lp := range m.GetLabel() {
+			labels[lp.GetName()] = lp.GetValue()
+		}
+		values[labels["mount_point"]] = m.GetGauge().GetValue()
+		errorsByMount[labels["mount_point"]] = labels["error"]
+	}
+
+	if len(values) != 3 {
+		t.Fatalf("Expected status metrics for 3 mount points, got %d", len(values))
+	}
+	if values["/healthy"] != 1 || values["/also-healthy"] != 1 {
+		t.Errorf("Expected healthy mount points to be exported as mounted, got %v", values)
+	}
+	if values["/explodes"] != 0 {
+		t.Errorf("Expected panicking mount point to be exported as 0, got %v", values["/explodes"])
+	}
+	if !strings.Contains(errorsByMount["/explodes"], "panic recovered") {
+		t.Errorf("Expected panic error label, got %q", errorsByMount["/explodes"])
+	}
+}
+
+// refusingGuard rejects every collection
+type refusingGuard struct{}
+
+func (refusingGuard) BeginScrape() (func(), error) {
+	return nil, errors.New("limit exceeded")
+}
+
+func TestCollector_ScrapeGuardRefusesCollection(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/test"},
+		Interval:    5 * time.Second,
+	}
+
+	collector := NewCollector(cfg)
+	collector.SetScrapeGuard(refusingGuard{})
+	checked := false
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		checked = true
+		return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusMounted}
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	collector.Collect(ch)
+	close(ch)
+
+	if checked {
+		t.Error("Expected refused collection to skip mount point checks")
+	}
+
+	var metrics []prometheus.Metric
+	for metric := range ch {
+		metrics = append(metrics, metric)
+	}
+	if len(metrics) != 1 || metrics[0].Desc().String() != collector.up.String() {
+		t.Fatalf("Expected only the up metric, got %d metrics", len(metrics))
+	}
+
+	var m dto.Metric
+	if err := metrics[0].Write(&m); err != nil {
+		t.Fatalf("Failed to write metric: %v", err)
+	}
+	if m.GetGauge().GetValue() != 0 {
+		t.Errorf("Expected up to be 0, got %v", m.GetGauge().GetValue())
+	}
+}
+
+func TestCollector_ForMountPoints(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/data", "/var/log", "/backup"},
+		Interval:    5 * time.Second,
+	}
+
+	var mu sync.Mutex
+	var checked []string
+	collector := NewCollector(cfg)
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		mu.Lock()
+		checked = append(checked, mountPoint)
+		mu.Unlock()
+		return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusMounted}
+	}
+
+	view := collector.ForMountPoints([]string{"/var/log"})
+
+	ch := make(chan prometheus.Metric, 50)
+	view.Collect(ch)
+	close(ch)
+
+	statusMetrics := 0
+	for metric := range ch {
+		if metric.Desc().String() == collector.mountPointStatus.String() {
+			statusMetrics++
+		}
+	}
+
+	if statusMetrics != 1 {
+		t.Errorf("Expected 1 mount point status metric, got %d", statusMetrics)
+	}
+	if len(checked) != 1 || checked[0] != "/var/log" {
+		t.Errorf("Expected only /var/log to be checked, got %v", checked)
+	}
+}
+
+func TestCollector_NamespaceLabel(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/data"},
+		Mounts: []config.MountConfig{
+			{Path: "/data", PID: 1},
+			{Path: "/data", Container: "postgres"},
+		},
+		Interval: 5 * time.Second,
+	}
+
+	collector := NewCollector(cfg)
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusMounted}
+	}
+
+	ch := make(chan prometheus.Metric, 50)
+	collector.Collect(ch)
+	close(ch)
+
+	namespaces := make(map[string]bool)
+	for metric := range ch {
+		if metric.Desc().String() != collector.mountPointStatus.String() {
+			continue
+		}
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+		for _, lp := range m.GetLabel() {
+			if lp.GetName() == "namespace" {
+				namespaces[lp.GetValue()] = true
+			}
+		}
+	}
+
+	for _, expected := range []string{"", "pid:1", "postgres"} {
+		if !namespaces[expected] {
+			t.Errorf("Expected a series with namespace %q, got %v", expected, namespaces)
+		}
+	}
+}
+
+func TestCollector_Drift(t *testing.T) {
+	root := t.TempDir()
+	fstab := filepath.Join(root, "fstab")
+	os.WriteFile(fstab, []byte("/dev/sdb1 /data xfs noatime 0 2\nnas:/b /backup nfs noauto 0 0\n"), 0o644)
+	os.MkdirAll(filepath.Join(root, "self"), 0o755)
+	os.WriteFile(filepath.Join(root, "self", "mountinfo"),
+		[]byte("36 22 8:17 / /data rw,relatime - xfs /dev/sdb1 rw\n37 22 8:33 / /scratch rw - ext4 /dev/sdc1 rw\n"), 0o644)
+
+	cfg := &config.Config{
+		MountPoints: []string{"/data", "/scratch"},
+		Interval:    5 * time.Second,
+	}
+	collector := NewCollector(cfg)
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusMounted}
+	}
+	collector.drift = system.NewDriftDetector(system.DriftDetectorConfig{FstabPath: fstab, ProcRoot: root})
+
+	expected := `
+# HELP mount_exporter_declared_mount_mounted Whether a mount declared in fstab or a systemd mount unit is mounted (1=mounted, 0=not mounted)
+# TYPE mount_exporter_declared_mount_mounted gauge
+mount_exporter_declared_mount_mounted{fs_type="nfs",mount_point="/backup",noauto="true",origin="fstab",source="nas:/b"} 0
+mount_exporter_declared_mount_mounted{fs_type="xfs",mount_point="/data",noauto="false",origin="fstab",source="/dev/sdb1"} 1
+# HELP mount_exporter_declared_mount_match Whether a mounted declared mount matches its declaration (1=matches, 0=drifted)
+# TYPE mount_exporter_declared_mount_match gauge
+mount_exporter_declared_mount_match{field="fs_type",mount_point="/data",noauto="false",origin="fstab"} 1
+mount_exporter_declared_mount_match{field="options",mount_point="/data",noauto="false",origin="fstab"} 0
+mount_exporter_declared_mount_match{field="source",mount_point="/data",noauto="false",origin="fstab"} 1
+# HELP mount_exporter_mount_point_declared Whether a configured mount point is declared in fstab or a systemd mount unit (1=declared, 0=not declared)
+# TYPE mount_exporter_mount_point_declared gauge
+mount_exporter_mount_point_declared{mount_point="/data"} 1
+mount_exporter_mount_point_declared{mount_point="/scratch"} 0
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"mount_exporter_declared_mount_mounted", "mount_exporter_declared_mount_match", "mount_exporter_mount_point_declared"); err != nil {
+		t.Errorf("Unexpected drift metrics: %v", err)
+	}
+}
+
+func TestCollector_Autofs(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/net/home", "/net/data", "/net/broken"},
+		Interval:    5 * time.Second,
+		Autofs:      config.AutofsConfig{Enabled: true, Trigger: true, TriggerTimeout: time.Second},
+	}
+
+	collector := NewCollector(cfg)
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusMounted, Target: mountPoint, FSType: "autofs", Source: "systemd-1"}
+	}
+	collector.autofsFunc = func(ctx context.Context, mountPoint string) *system.AutofsResult {
+		switch mountPoint {
+		case "/net/data":
+			return &system.AutofsResult{MountPoint: mountPoint, State: system.AutofsMounted, FSType: "nfs4", Source: "nas:/data"}
+		case "/net/broken":
+			return &system.AutofsResult{MountPoint: mountPoint, State: system.AutofsTriggerFailed, Error: errors.New("autofs trigger on /net/broken timed out")}
+		default:
+			return &system.AutofsResult{MountPoint: mountPoint, State: system.AutofsTriggerPresent}
+		}
+	}
+
+	expected := `
+# HELP mount_exporter_mount_point_status Mount point availability status (1=mounted, 0=not mounted)
+# TYPE mount_exporter_mount_point_status gauge
+mount_exporter_mount_point_status{error="",fs_type="autofs",mount_point="/net/home",namespace="",source="systemd-1",target="/net/home"} 1
+mount_exporter_mount_point_status{error="",fs_type="nfs4",mount_point="/net/data",namespace="",source="nas:/data",target="/net/data"} 1
+mount_exporter_mount_point_status{error="autofs trigger on /net/broken timed out",fs_type="autofs",mount_point="/net/broken",namespace="",source="systemd-1",target="/net/broken"} 0
+# HELP mount_exporter_autofs_state State of an autofs-managed mount point (1 for the current state)
+# TYPE mount_exporter_autofs_state gauge
+mount_exporter_autofs_state{mount_point="/net/broken",state="mounted"} 0
+mount_exporter_autofs_state{mount_point="/net/broken",state="trigger_failed"} 1
+mount_exporter_autofs_state{mount_point="/net/broken",state="trigger_present"} 0
+mount_exporter_autofs_state{mount_point="/net/data",state="mounted"} 1
+mount_exporter_autofs_state{mount_point="/net/data",state="trigger_failed"} 0
+mount_exporter_autofs_state{mount_point="/net/data",state="trigger_present"} 0
+mount_exporter_autofs_state{mount_point="/net/home",state="mounted"} 0
+mount_exporter_autofs_state{mount_point="/net/home",state="trigger_failed"} 0
+mount_exporter_autofs_state{mount_point="/net/home",state="trigger_present"} 1
+# HELP mount_exporter_up Whether the mount exporter is healthy (1=healthy, 0=unhealthy)
+# TYPE mount_exporter_up gauge
+mount_exporter_up 0
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"mount_exporter_mount_point_status", "mount_exporter_autofs_state", "mount_exporter_up"); err != nil {
+		t.Errorf("Unexpected autofs metrics: %v", err)
+	}
+}
+
+func TestCollector_MountInfo(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/mnt/nfs", "/missing"},
+		Interval:    5 * time.Second,
+	}
+
+	collector := NewCollector(cfg)
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		if mountPoint == "/missing" {
+			return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusNotMounted}
+		}
+		return &system.FindmntResult{
+			MountPoint:  mountPoint,
+			Status:      system.MountStatusMounted,
+			Target:      mountPoint,
+			FSType:      "nfs4",
+			Source:      "nas:/export",
+			Options:     "ro,nosuid,nodev,noatime,vers=4.2,rsize=1048576,sec=krb5p",
+			Propagation: "shared",
+		}
+	}
+	collector.classesFunc = func() (map[string]system.MountClass, error) {
+		return map[string]system.MountClass{"/mnt/nfs": {Kind: system.MountKindNetwork}}, nil
+	}
+
+	expected := `
+# HELP mount_exporter_mount_info Information about a mounted mount point (always 1)
+# TYPE mount_exporter_mount_info gauge
+mount_exporter_mount_info{fs_type="nfs4",mount_kind="network",mount_point="/mnt/nfs",namespace="",propagation="shared",read_only="true",source="nas:/export"} 1
+# HELP mount_exporter_mount_option Security and behavior relevant mount options set on a mount point (always 1)
+# TYPE mount_exporter_mount_option gauge
+mount_exporter_mount_option{mount_point="/mnt/nfs",namespace="",option="nodev"} 1
+mount_exporter_mount_option{mount_point="/mnt/nfs",namespace="",option="nosuid"} 1
+mount_exporter_mount_option{mount_point="/mnt/nfs",namespace="",option="ro"} 1
+mount_exporter_mount_option{mount_point="/mnt/nfs",namespace="",option="sec=krb5p"} 1
+mount_exporter_mount_option{mount_point="/mnt/nfs",namespace="",option="vers=4.2"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"mount_exporter_mount_info", "mount_exporter_mount_option"); err != nil {
+		t.Errorf("Unexpected mount info metrics: %v", err)
+	}
+}
+
+func TestCollector_KubernetesLabels(t *testing.T) {
+	root := t.TempDir()
+	metadata := filepath.Join(root, "pods.json")
+	os.WriteFile(metadata, []byte(`{"pods": [{"uid": "u1", "namespace": "shop", "name": "db-0", "volumes": {"pvc-1": "data-db-0"}}]}`), 0o644)
+
+	cfg := &config.Config{
+		MountPoints: []string{"/data", "/var/lib/kubelet/pods/u1/volumes/kubernetes.io~csi/pvc-1/mount"},
+		Interval:    5 * time.Second,
+		Kubernetes: config.KubernetesConfig{
+			Enabled:         true,
+			KubeletRoot:     "/var/lib/kubelet",
+			MetadataFile:    metadata,
+			RefreshInterval: time.Minute,
+		},
+	}
+	collector := NewCollector(cfg)
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusMounted, Target: mountPoint}
+	}
+	enricher := kubernetes.NewEnricher(cfg.Kubernetes, nil)
+	if err := enricher.Refresh(context.Background()); err != nil {
+		t.Fatalf("Failed to refresh metadata: %v", err)
+	}
+	collector.SetKubernetesEnricher(enricher)
+
+	expected := `
+# HELP mount_exporter_mount_point_status Mount point availability status (1=mounted, 0=not mounted)
+# TYPE mount_exporter_mount_point_status gauge
+mount_exporter_mount_point_status{error="",fs_type="",mount_point="/data",namespace="",pod="",pod_namespace="",pod_uid="",pvc="",source="",target="/data",volume=""} 1
+mount_exporter_mount_point_status{error="",fs_type="",mount_point="/var/lib/kubelet/pods/u1/volumes/kubernetes.io~csi/pvc-1/mount",namespace="",pod="db-0",pod_namespace="shop",pod_uid="u1",pvc="data-db-0",source="",target="/var/lib/kubelet/pods/u1/volumes/kubernetes.io~csi/pvc-1/mount",volume="pvc-1"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "mount_exporter_mount_point_status"); err != nil {
+		t.Errorf("Unexpected Kubernetes labels: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricDescriptor describes a metric family exported by a collector
+type MetricDescriptor struct {
+	Name   string
+	Help   string
+	Labels []string
+}
+
+// HasLabel reports whether the metric has the variable label name
+func (d MetricDescriptor) HasLabel(name string) bool {
+	for _, l := range d.Labels {
+		if l == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Describer is a collector that can list the metric families it exports
+type Describer interface {
+	prometheus.Collector
+	Descriptors() []MetricDescriptor
+}
+
+// descriptorSet records the metric families a collector creates, since
+// prometheus.Desc does not expose its name, help and labels
+type descriptorSet struct {
+	descs []MetricDescriptor
+}
+
+// add records a metric family
+func (s *descriptorSet) add(name, help string, labels []string) {
+	s.descs = append(s.descs, MetricDescriptor{Name: name, Help: help, Labels: append([]string(nil), labels...)})
+}
+
+// newDesc creates and records a descriptor
+func (s *descriptorSet) newDesc(name, help string, labels []string) *prometheus.Desc {
+	s.add(name, help, labels)
+	return prometheus.NewDesc(name, help, labels, nil)
+}
+
+// newHistogramVec creates and records a histogram vector
+func (s *descriptorSet) newHistogramVec(opts prometheus.HistogramOpts, labels []string) *prometheus.HistogramVec {
+	s.add(prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name), opts.Help, labels)
+	return prometheus.NewHistogramVec(opts, labels)
+}
+
+// newCounterVec creates and records a counter vector
+func (s *descriptorSet) newCounterVec(opts prometheus.CounterOpts, labels []string) *prometheus.CounterVec {
+	s.add(prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name), opts.Help, labels)
+	return prometheus.NewCounterVec(opts, labels)
+}
+
+// list returns a copy of the recorded descriptors
+func (s *descriptorSet) list() []MetricDescriptor {
+	return append([]MetricDescriptor(nil), s.descs...)
+}
+
+// Descriptors returns the descriptors of the given collectors, sorted by name
+func Descriptors(describers ...Describer) []MetricDescriptor {
+	var descs []MetricDescriptor
+	for _, d := range describers {
+		descs = append(descs, d.Descriptors()...)
+	}
+	sort.Slice(descs, func(i, j int) bool { return descs[i].Name < descs[j].Name })
+	return descs
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestDescriptors(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/data"},
+		Interval:    5 * time.Second,
+	}
+	probe := NewWriteProbeCollector(config.WriteProbeConfig{MountPoints: []string{"/data"}, Interval: time.Minute})
+
+	descs := Descriptors(NewCollector(cfg), probe)
+
+	byName := make(map[string]MetricDescriptor, len(descs))
+	for i, d := range descs {
+		if i > 0 && descs[i-1].Name >= d.Name {
+			t.Errorf("Descriptors not sorted: %s before %s", descs[i-1].Name, d.Name)
+		}
+		byName[d.Name] = d
+	}
+
+	status, ok := byName["mount_exporter_mount_point_status"]
+	if !ok {
+		t.Fatal("Expected mount_point_status descriptor")
+	}
+	if status.Help != "Mount point availability status (1=mounted, 0=not mounted)" {
+		t.Errorf("Unexpected help %q", status.Help)
+	}
+	if !status.HasLabel("mount_point") || !status.HasLabel("error") || status.HasLabel("pod_uid") {
+		t.Errorf("Unexpected labels %v", status.Labels)
+	}
+
+	if up := byName["mount_exporter_up"]; len(up.Labels) != 0 {
+		t.Errorf("Expected no labels on up, got %v", up.Labels)
+	}
+	if _, ok := byName["mount_exporter_write_probe_duration_seconds"]; !ok {
+		t.Error("Expected write probe histogram descriptor")
+	}
+}
+
+func TestDescriptors_MatchDescribe(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/data"},
+		Interval:    5 * time.Second,
+		Kubernetes:  config.KubernetesConfig{Enabled: true},
+	}
+	collector := NewCollector(cfg)
+	describers := []Describer{
+		collector,
+		NewWriteProbeCollector(config.WriteProbeConfig{MountPoints: []string{"/data"}, Interval: time.Minute}),
+		NewLatencyProbeCollector(config.LatencyProbeConfig{MountPoints: []string{"/data"}, Interval: time.Minute}, collector.GetFindmntWrapper()),
+	}
+
+	// Every described metric family is listed, in the same order
+	for _, d := range describers {
+		ch := make(chan *prometheus.Desc, 100)
+		d.Describe(ch)
+		close(ch)
+
+		descs := d.Descriptors()
+		i := 0
+		for desc := range ch {
+			if i >= len(descs) {
+				t.Fatalf("%T describes more metrics than the %d it lists", d, len(descs))
+			}
+			expected := prometheus.NewDesc(descs[i].Name, descs[i].Help, descs[i].Labels, nil)
+			if desc.String() != expected.String() {
+				t.Errorf("%T lists %+v for %s", d, descs[i], desc)
+			}
+			i++
+		}
+		if i != len(descs) {
+			t.Errorf("%T lists %d metrics, describes %d", d, len(descs), i)
+		}
+	}
+}
+
+func TestIsExportedMountOption(t *testing.T) {
+	for opt, expected := range map[string]bool{
+		"noexec": true, "vers=4.2": true, "sec=krb5p": true, "vers": false, "rsize=1048576": false, "noatime": false,
+	} {
+		if got := IsExportedMountOption(opt); got != expected {
+			t.Errorf("IsExportedMountOption(%q) = %v, expected %v", opt, got, expected)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// DryRun collects once and writes the series that would be exported,
+// grouped by mount point and followed by the exporter-wide series
+func (c *Collector) DryRun(w io.Writer) error {
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(c); err != nil {
+		return fmt.Errorf("failed to register collector: %w", err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		return fmt.Errorf("failed to collect metrics: %w", err)
+	}
+
+	// Configured mount points first, in configuration order
+	var order []string
+	seen := make(map[string]bool)
+	c.mu.RLock()
+	for _, m := range c.selectedMounts() {
+		if !seen[m.Path] {
+			seen[m.Path] = true
+			order = append(order, m.Path)
+		}
+	}
+	c.mu.RUnlock()
+
+	byMount := make(map[string][]string)
+	var global []string
+	var others []string
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			line, ok := formatSeries(family, m)
+			if !ok {
+				continue
+			}
+
+			mountPoint, found := labelValue(m, "mount_point")
+			if !found {
+				global = append(global, line)
+				continue
+			}
+			if !seen[mountPoint] {
+				seen[mountPoint] = true
+				others = append(others, mountPoint)
+			}
+			byMount[mountPoint] = append(byMount[mountPoint], line)
+		}
+	}
+	sort.Strings(others)
+
+	for _, mountPoint := range append(order, others...) {
+		if _, err := fmt.Fprintf(w, "%s:\n", mountPoint); err != nil {
+			return err
+		}
+		for _, line := range byMount[mountPoint] {
+			if _, err := fmt.Fprintf(w, "  %s\n", line); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := fmt.Fprintln(w, "exporter:"); err != nil {
+		return err
+	}
+	for _, line := range global {
+		if _, err := fmt.Fprintf(w, "  %s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatSeries formats a gauge, counter or untyped sample in the text
+// exposition format
+func formatSeries(family *dto.MetricFamily, m *dto.Metric) (string, bool) {
+	var value float64
+	switch {
+	case m.Gauge != nil:
+		value = m.Gauge.GetValue()
+	case m.Counter != nil:
+		value = m.Counter.GetValue()
+	case m.Untyped != nil:
+		value = m.Untyped.GetValue()
+	default:
+		return "", false
+	}
+
+	var b strings.Builder
+	b.WriteString(family.GetName())
+	if len(m.GetLabel()) > 0 {
+		pairs := make([]string, 0, len(m.GetLabel()))
+		for _, l := range m.GetLabel() {
+			pairs = append(pairs, l.GetName()+"="+strconv.Quote(l.GetValue()))
+		}
+		b.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	b.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64))
+	return b.String(), true
+}
+
+// labelValue returns the value of label name on m
+func labelValue(m *dto.Metric, name string) (string, bool) {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue(), true
+		}
+	}
+	return "", false
+}
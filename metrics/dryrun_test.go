@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/system"
+)
+
+func TestCollector_DryRun(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/mnt/b", "/mnt/a"},
+		Interval:    5 * time.Second,
+	}
+
+	collector := NewCollector(cfg)
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		if mountPoint == "/mnt/a" {
+			return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusNotMounted}
+		}
+		return &system.FindmntResult{
+			MountPoint: mountPoint,
+			Status:     system.MountStatusMounted,
+			Target:     mountPoint,
+			FSType:     "xfs",
+			Source:     "/dev/sdb",
+			Options:    "rw,nodev",
+		}
+	}
+	collector.classesFunc = func() (map[string]system.MountClass, error) {
+		return map[string]system.MountClass{"/mnt/b": {Kind: system.MountKindDevice}}, nil
+	}
+
+	var buf bytes.Buffer
+	if err := collector.DryRun(&buf); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output := buf.String()
+
+	// Mount points appear in configuration order, exporter series last
+	b := strings.Index(output, "/mnt/b:\n")
+	a := strings.Index(output, "/mnt/a:\n")
+	exporter := strings.Index(output, "exporter:\n")
+	if b < 0 || a < b || exporter < a {
+		t.Fatalf("Unexpected grouping:\n%s", output)
+	}
+
+	for _, line := range []string{
+		`  mount_exporter_mount_point_status{error="",fs_type="xfs",mount_point="/mnt/b",namespace="",source="/dev/sdb",target="/mnt/b"} 1`,
+		`  mount_exporter_mount_option{mount_point="/mnt/b",namespace="",option="nodev"} 1`,
+		`  mount_exporter_mount_point_status{error="",fs_type="",mount_point="/mnt/a",namespace="",source="",target=""} 0`,
+		`  mount_exporter_up 1`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected line %q in:\n%s", line, output)
+		}
+	}
+
+	if strings.Contains(output[a:exporter], "mount_exporter_mount_info") {
+		t.Errorf("Expected no mount_info for an unmounted mount point:\n%s", output)
+	}
+}
@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/system"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LatencyProbeCollector times metadata operations on mount points in the
+// background and exports them as histograms, so slow mounts show up before
+// they hang a scrape
+type LatencyProbeCollector struct {
+	findmnt     *system.FindmntWrapper
+	mountPoints []string
+	canaries    map[string]string
+	interval    time.Duration
+
+	// probeFunc overrides the latency probe (for testing)
+	probeFunc func(ctx context.Context, mountPoint, canary string) []system.LatencySample
+
+	duration *prometheus.HistogramVec
+	failures *prometheus.CounterVec
+	descs    *descriptorSet
+}
+
+// NewLatencyProbeCollector creates a latency probe collector for cfg which
+// shares the timeout of findmnt
+func NewLatencyProbeCollector(cfg config.LatencyProbeConfig, findmnt *system.FindmntWrapper) *LatencyProbeCollector {
+	buckets := prometheus.DefBuckets
+	if len(cfg.Buckets) > 0 {
+		buckets = append([]float64(nil), cfg.Buckets...)
+	}
+
+	canaries := make(map[string]string, len(cfg.CanaryFiles))
+	for mp, canary := range cfg.CanaryFiles {
+		canaries[mp] = canary
+	}
+
+	descs := &descriptorSet{}
+	return &LatencyProbeCollector{
+		findmnt:     findmnt,
+		mountPoints: append([]string(nil), cfg.MountPoints...),
+		canaries:    canaries,
+		interval:    cfg.Interval,
+		duration: descs.newHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "mount_operation_duration_seconds",
+			Help:      "Time taken by stat, readdir and canary_read operations on mount points, including failed ones",
+			Buckets:   buckets,
+		}, []string{"mount_point", "operation"}),
+		failures: descs.newCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "mount_operation_failures_total",
+			Help:      "Total number of failed mount point operations by errno class",
+		}, []string{"mount_point", "operation", "errno_class"}),
+		descs: descs,
+	}
+}
+
+// Run probes all mount points every interval until ctx is done
+func (p *LatencyProbeCollector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.ProbeAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProbeAll probes all mount points concurrently and records the samples
+func (p *LatencyProbeCollector) ProbeAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, mountPoint := range p.mountPoints {
+		wg.Add(1)
+		go func(mountPoint string) {
+			defer wg.Done()
+			p.record(p.probe(ctx, mountPoint))
+		}(mountPoint)
+	}
+	wg.Wait()
+}
+
+// probe runs the latency probes of a single mount point
+func (p *LatencyProbeCollector) probe(ctx context.Context, mountPoint string) []system.LatencySample {
+	if p.probeFunc != nil {
+		return p.probeFunc(ctx, mountPoint, p.canaries[mountPoint])
+	}
+	return p.findmnt.ProbeLatency(ctx, mountPoint, p.canaries[mountPoint])
+}
+
+// record updates the histogram and failure counters from samples.
+// Operations that did not run are only counted as failures.
+func (p *LatencyProbeCollector) record(samples []system.LatencySample) {
+	for _, sample := range samples {
+		if sample.Ran() {
+			p.duration.WithLabelValues(sample.MountPoint, sample.Operation).Observe(sample.Duration.Seconds())
+		}
+		if sample.Error != nil {
+			p.failures.WithLabelValues(sample.MountPoint, sample.Operation, system.ErrnoClass(sample.Error)).Inc()
+		}
+	}
+}
+
+// Describe implements prometheus.Collector interface
+func (p *LatencyProbeCollector) Describe(ch chan<- *prometheus.Desc) {
+	p.duration.Describe(ch)
+	p.failures.Describe(ch)
+}
+
+// Descriptors implements Describer interface
+func (p *LatencyProbeCollector) Descriptors() []MetricDescriptor {
+	return p.descs.list()
+}
+
+// Collect implements prometheus.Collector interface
+func (p *LatencyProbeCollector) Collect(ch chan<- prometheus.Metric) {
+	p.duration.Collect(ch)
+	p.failures.Collect(ch)
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/reliability"
+	"github.com/mount-exporter/mount-exporter/system"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestLatencyProbeCollector_ProbeAll(t *testing.T) {
+	collector := NewLatencyProbeCollector(config.LatencyProbeConfig{
+		MountPoints: []string{"/data", "/stale"},
+		Interval:    time.Minute,
+		Buckets:     []float64{0.01, 0.1},
+		CanaryFiles: map[string]string{"/data": ".canary"},
+	}, system.NewFindmntWrapper(time.Second))
+
+	var (
+		mu       sync.Mutex
+		canaries []string
+	)
+	collector.probeFunc = func(ctx context.Context, mountPoint, canary string) []system.LatencySample {
+		if canary != "" {
+			mu.Lock()
+			defer mu.Unlock()
+			canaries = append(canaries, mountPoint+"/"+canary)
+		}
+		sample := system.LatencySample{MountPoint: mountPoint, Operation: system.OperationStat, Duration: 50 * time.Millisecond}
+		if mountPoint == "/stale" {
+			sample.Error = fmt.Errorf("stat failed: %w", syscall.ESTALE)
+		}
+		return []system.LatencySample{sample}
+	}
+
+	collector.ProbeAll(context.Background())
+
+	if len(canaries) != 1 || canaries[0] != "/data/.canary" {
+		t.Errorf("Expected canary to be passed for /data only, got %v", canaries)
+	}
+
+	expected := `
+# HELP mount_exporter_mount_operation_duration_seconds Time taken by stat, readdir and canary_read operations on mount points, including failed ones
+# TYPE mount_exporter_mount_operation_duration_seconds histogram
+mount_exporter_mount_operation_duration_seconds_bucket{mount_point="/data",operation="stat",le="0.01"} 0
+mount_exporter_mount_operation_duration_seconds_bucket{mount_point="/data",operation="stat",le="0.1"} 1
+mount_exporter_mount_operation_duration_seconds_bucket{mount_point="/data",operation="stat",le="+Inf"} 1
+mount_exporter_mount_operation_duration_seconds_sum{mount_point="/data",operation="stat"} 0.05
+mount_exporter_mount_operation_duration_seconds_count{mount_point="/data",operation="stat"} 1
+mount_exporter_mount_operation_duration_seconds_bucket{mount_point="/stale",operation="stat",le="0.01"} 0
+mount_exporter_mount_operation_duration_seconds_bucket{mount_point="/stale",operation="stat",le="0.1"} 1
+mount_exporter_mount_operation_duration_seconds_bucket{mount_point="/stale",operation="stat",le="+Inf"} 1
+mount_exporter_mount_operation_duration_seconds_sum{mount_point="/stale",operation="stat"} 0.05
+mount_exporter_mount_operation_duration_seconds_count{mount_point="/stale",operation="stat"} 1
+# HELP mount_exporter_mount_operation_failures_total Total number of failed mount point operations by errno class
+# TYPE mount_exporter_mount_operation_failures_total counter
+mount_exporter_mount_operation_failures_total{errno_class="estale",mount_point="/stale",operation="stat"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Errorf("Unexpected latency probe metrics: %v", err)
+	}
+}
+
+func TestLatencyProbeCollector_SkippedOperations(t *testing.T) {
+	collector := NewLatencyProbeCollector(config.LatencyProbeConfig{
+		MountPoints: []string{"/hung"},
+		Interval:    time.Minute,
+	}, system.NewFindmntWrapper(time.Second))
+
+	collector.probeFunc = func(ctx context.Context, mountPoint, canary string) []system.LatencySample {
+		return []system.LatencySample{
+			{MountPoint: mountPoint, Operation: system.OperationStat, Error: system.ErrStillPending},
+			{MountPoint: mountPoint, Operation: system.OperationReadDir, Error: fmt.Errorf("latency probes are temporarily disabled: %w", reliability.ErrOpen)},
+		}
+	}
+	collector.ProbeAll(context.Background())
+
+	// Operations that did not run must not pull the histogram down
+	if count := testutil.CollectAndCount(collector, "mount_exporter_mount_operation_duration_seconds"); count != 0 {
+		t.Errorf("Expected no duration observations, got %d series", count)
+	}
+	if count := testutil.CollectAndCount(collector, "mount_exporter_mount_operation_failures_total"); count != 2 {
+		t.Errorf("Expected both operations to be counted as failures, got %d series", count)
+	}
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/system"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// defaultMountStatsPath is where the kernel reports per-mount NFS statistics
+const defaultMountStatsPath = "/proc/self/mountstats"
+
+// nfsDescs are the descriptors of the NFS client metrics
+type nfsDescs struct {
+	readBytes       *prometheus.Desc
+	writtenBytes    *prometheus.Desc
+	retransmissions *prometheus.Desc
+	requests        *prometheus.Desc
+	majorTimeouts   *prometheus.Desc
+	rtt             *prometheus.Desc
+	execute         *prometheus.Desc
+	errors          *prometheus.Desc
+}
+
+// newNFSDescs creates the NFS client metric descriptors
+func newNFSDescs(descs *descriptorSet) *nfsDescs {
+	mountLabels := []string{"mount_point", "export"}
+	opLabels := []string{"mount_point", "export", "operation"}
+	desc := func(name, help string, labels []string) *prometheus.Desc {
+		return descs.newDesc(prometheus.BuildFQName(namespace, "nfs", name), help, labels)
+	}
+
+	return &nfsDescs{
+		readBytes:       desc("read_bytes_total", "Total number of bytes read by applications from the NFS mount", mountLabels),
+		writtenBytes:    desc("written_bytes_total", "Total number of bytes written by applications to the NFS mount", mountLabels),
+		retransmissions: desc("rpc_retransmissions_total", "Total number of RPC retransmissions on the NFS mount", mountLabels),
+		requests:        desc("operation_requests_total", "Total number of RPC requests per NFS operation", opLabels),
+		majorTimeouts:   desc("operation_major_timeouts_total", "Total number of major timeouts per NFS operation", opLabels),
+		rtt:             desc("operation_rtt_seconds_total", "Total round trip time of RPC requests per NFS operation", opLabels),
+		execute:         desc("operation_execute_seconds_total", "Total time from queueing to completion of RPC requests per NFS operation", opLabels),
+		errors:          desc("operation_errors_total", "Total number of RPC requests that failed per NFS operation", opLabels),
+	}
+}
+
+// describe sends all NFS descriptors to ch
+func (d *nfsDescs) describe(ch chan<- *prometheus.Desc) {
+	ch <- d.readBytes
+	ch <- d.writtenBytes
+	ch <- d.retransmissions
+	ch <- d.requests
+	ch <- d.majorTimeouts
+	ch <- d.rtt
+	ch <- d.execute
+	ch <- d.errors
+}
+
+// newMountStatsPath returns the mountstats path for cfg, or "" when disabled
+func newMountStatsPath(cfg *config.Config) string {
+	if !cfg.NFSStats.Enabled {
+		return ""
+	}
+	return defaultMountStatsPath
+}
+
+// collectNFS exports NFS client statistics of the collected mount points
+// that are NFS mounts in the exporter's own namespace. Operations that were
+// never used are omitted to keep the NFSv4 operation list short.
+func (c *Collector) collectNFS(ch chan<- prometheus.Metric, mounts []config.MountConfig) bool {
+	stats, err := system.ReadMountStats(c.mountStatsPath)
+	if err != nil {
+		return false
+	}
+
+	selected := make(map[string]bool, len(mounts))
+	for _, m := range mounts {
+		if mountNamespace(m).IsHost() {
+			selected[m.Path] = true
+		}
+	}
+
+	// When NFS mounts are stacked on a path, the last one is visible
+	visible := make(map[string]*system.NFSMountStats)
+	for _, s := range stats {
+		if selected[s.MountPoint] {
+			visible[s.MountPoint] = s
+		}
+	}
+
+	d := c.nfsDescs
+	for _, s := range stats {
+		if visible[s.MountPoint] != s {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(d.readBytes, prometheus.CounterValue, float64(s.Bytes.Read()), s.MountPoint, s.Device)
+		ch <- prometheus.MustNewConstMetric(d.writtenBytes, prometheus.CounterValue, float64(s.Bytes.Written()), s.MountPoint, s.Device)
+		ch <- prometheus.MustNewConstMetric(d.retransmissions, prometheus.CounterValue, float64(s.Retransmissions()), s.MountPoint, s.Device)
+
+		for _, op := range s.Operations {
+			if op.Requests == 0 {
+				continue
+			}
+			labels := []string{s.MountPoint, s.Device, op.Operation}
+			ch <- prometheus.MustNewConstMetric(d.requests, prometheus.CounterValue, float64(op.Requests), labels...)
+			ch <- prometheus.MustNewConstMetric(d.majorTimeouts, prometheus.CounterValue, float64(op.MajorTimeouts), labels...)
+			ch <- prometheus.MustNewConstMetric(d.rtt, prometheus.CounterValue, op.RTT.Seconds(), labels...)
+			ch <- prometheus.MustNewConstMetric(d.execute, prometheus.CounterValue, op.ExecuteTime.Seconds(), labels...)
+			ch <- prometheus.MustNewConstMetric(d.errors, prometheus.CounterValue, float64(op.Errors), labels...)
+		}
+	}
+
+	return true
+}
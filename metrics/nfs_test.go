@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/system"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector_NFS(t *testing.T) {
+	mountStats := filepath.Join(t.TempDir(), "mountstats")
+	os.WriteFile(mountStats, []byte(`device nas:/export mounted on /mnt/nas with fstype nfs4 statvers=1.1
+	age:	3600
+	bytes:	1000 2000 300 400 1500 2500 10 20
+	per-op statistics
+	        NULL: 0 0 0 0 0 0 0 0 0
+	        READ: 100 103 1 12000 1048576 5 250 300 2
+device nas:/other mounted on /mnt/other with fstype nfs4 statvers=1.1
+	bytes:	1 2 3 4 5 6 7 8
+`), 0o644)
+
+	cfg := &config.Config{
+		MountPoints: []string{"/mnt/nas", "/data"},
+		Interval:    5 * time.Second,
+		NFSStats:    config.NFSStatsConfig{Enabled: true},
+	}
+	collector := NewCollector(cfg)
+	collector.checkFunc = func(ctx context.Context, mountPoint string) *system.FindmntResult {
+		return &system.FindmntResult{MountPoint: mountPoint, Status: system.MountStatusMounted}
+	}
+	collector.mountStatsPath = mountStats
+
+	// Unconfigured NFS mounts and unused operations are not exported
+	expected := `
+# HELP mount_exporter_nfs_read_bytes_total Total number of bytes read by applications from the NFS mount
+# TYPE mount_exporter_nfs_read_bytes_total counter
+mount_exporter_nfs_read_bytes_total{export="nas:/export",mount_point="/mnt/nas"} 1300
+# HELP mount_exporter_nfs_rpc_retransmissions_total Total number of RPC retransmissions on the NFS mount
+# TYPE mount_exporter_nfs_rpc_retransmissions_total counter
+mount_exporter_nfs_rpc_retransmissions_total{export="nas:/export",mount_point="/mnt/nas"} 3
+# HELP mount_exporter_nfs_operation_requests_total Total number of RPC requests per NFS operation
+# TYPE mount_exporter_nfs_operation_requests_total counter
+mount_exporter_nfs_operation_requests_total{export="nas:/export",mount_point="/mnt/nas",operation="READ"} 100
+# HELP mount_exporter_nfs_operation_rtt_seconds_total Total round trip time of RPC requests per NFS operation
+# TYPE mount_exporter_nfs_operation_rtt_seconds_total counter
+mount_exporter_nfs_operation_rtt_seconds_total{export="nas:/export",mount_point="/mnt/nas",operation="READ"} 0.25
+# HELP mount_exporter_nfs_operation_errors_total Total number of RPC requests that failed per NFS operation
+# TYPE mount_exporter_nfs_operation_errors_total counter
+mount_exporter_nfs_operation_errors_total{export="nas:/export",mount_point="/mnt/nas",operation="READ"} 2
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"mount_exporter_nfs_read_bytes_total",
+		"mount_exporter_nfs_rpc_retransmissions_total",
+		"mount_exporter_nfs_operation_requests_total",
+		"mount_exporter_nfs_operation_rtt_seconds_total",
+		"mount_exporter_nfs_operation_errors_total"); err != nil {
+		t.Errorf("Unexpected NFS metrics: %v", err)
+	}
+
+	// An unreadable mountstats file marks the exporter unhealthy
+	collector.mountStatsPath = filepath.Join(t.TempDir(), "missing")
+	expected = `
+# HELP mount_exporter_up Whether the mount exporter is healthy (1=healthy, 0=unhealthy)
+# TYPE mount_exporter_up gauge
+mount_exporter_up 0
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "mount_exporter_up"); err != nil {
+		t.Errorf("Expected unhealthy exporter: %v", err)
+	}
+}
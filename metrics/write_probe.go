@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/system"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// WriteProbeCollector runs write probes in the background and exports their
+// results, so a hung write never stalls a scrape
+type WriteProbeCollector struct {
+	prober      *system.WriteProber
+	mountPoints []string
+	interval    time.Duration
+	mu          sync.RWMutex
+	results     map[string]*system.WriteProbeResult
+
+	// probeFunc overrides the write probe (for testing)
+	probeFunc func(ctx context.Context, mountPoint string) *system.WriteProbeResult
+
+	success  *prometheus.Desc
+	duration *prometheus.HistogramVec
+	failures *prometheus.CounterVec
+	descs    *descriptorSet
+}
+
+// NewWriteProbeCollector creates a write probe collector for cfg
+func NewWriteProbeCollector(cfg config.WriteProbeConfig) *WriteProbeCollector {
+	descs := &descriptorSet{}
+	return &WriteProbeCollector{
+		prober:      system.NewWriteProber(cfg.Directory, cfg.Timeout),
+		mountPoints: append([]string(nil), cfg.MountPoints...),
+		interval:    cfg.Interval,
+		results:     make(map[string]*system.WriteProbeResult),
+		success: descs.newDesc(
+			prometheus.BuildFQName(namespace, subsystem, "write_probe_success"),
+			"Whether the last write probe succeeded (1=success, 0=failure)",
+			[]string{"mount_point", "errno_class"},
+		),
+		duration: descs.newHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "write_probe_duration_seconds",
+			Help:      "Time taken by write probes, including failed ones",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"mount_point"}),
+		failures: descs.newCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "write_probe_failures_total",
+			Help:      "Total number of failed write probes by errno class",
+		}, []string{"mount_point", "errno_class"}),
+		descs: descs,
+	}
+}
+
+// Run probes all mount points every interval until ctx is done
+func (p *WriteProbeCollector) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.ProbeAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProbeAll probes all mount points concurrently and records the results
+func (p *WriteProbeCollector) ProbeAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, mountPoint := range p.mountPoints {
+		wg.Add(1)
+		go func(mountPoint string) {
+			defer wg.Done()
+			p.record(p.probe(ctx, mountPoint))
+		}(mountPoint)
+	}
+	wg.Wait()
+}
+
+// probe runs a single write probe
+func (p *WriteProbeCollector) probe(ctx context.Context, mountPoint string) *system.WriteProbeResult {
+	if p.probeFunc != nil {
+		return p.probeFunc(ctx, mountPoint)
+	}
+	return p.prober.Probe(ctx, mountPoint)
+}
+
+// record stores a probe result and updates the histogram and counters.
+// Probes that did not run are not observed in the histogram.
+func (p *WriteProbeCollector) record(result *system.WriteProbeResult) {
+	if result.Ran() {
+		p.duration.WithLabelValues(result.MountPoint).Observe(result.Duration.Seconds())
+	}
+	if result.Error != nil {
+		p.failures.WithLabelValues(result.MountPoint, result.ErrorClass()).Inc()
+	}
+
+	p.mu.Lock()
+	p.results[result.MountPoint] = result
+	p.mu.Unlock()
+}
+
+// Describe implements prometheus.Collector interface
+func (p *WriteProbeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.success
+	p.duration.Describe(ch)
+	p.failures.Describe(ch)
+}
+
+// Descriptors implements Describer interface
+func (p *WriteProbeCollector) Descriptors() []MetricDescriptor {
+	return p.descs.list()
+}
+
+// Collect implements prometheus.Collector interface
+func (p *WriteProbeCollector) Collect(ch chan<- prometheus.Metric) {
+	p.mu.RLock()
+	for mountPoint, result := range p.results {
+		ch <- prometheus.MustNewConstMetric(
+			p.success,
+			prometheus.GaugeValue,
+			boolToFloat(result.Error == nil),
+			mountPoint, result.ErrorClass(),
+		)
+	}
+	p.mu.RUnlock()
+
+	p.duration.Collect(ch)
+	p.failures.Collect(ch)
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/system"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestWriteProbeCollector_ProbeAll(t *testing.T) {
+	collector := NewWriteProbeCollector(config.WriteProbeConfig{
+		MountPoints: []string{"/data", "/full"},
+		Directory:   ".mount-exporter",
+		Interval:    time.Minute,
+		Timeout:     time.Second,
+	})
+	collector.probeFunc = func(ctx context.Context, mountPoint string) *system.WriteProbeResult {
+		result := &system.WriteProbeResult{MountPoint: mountPoint, Duration: 10 * time.Millisecond}
+		if mountPoint == "/full" {
+			result.Error = fmt.Errorf("failed to write probe file: %w", syscall.ENOSPC)
+		}
+		return result
+	}
+
+	collector.ProbeAll(context.Background())
+
+	expected := `
+# HELP mount_exporter_write_probe_success Whether the last write probe succeeded (1=success, 0=failure)
+# TYPE mount_exporter_write_probe_success gauge
+mount_exporter_write_probe_success{errno_class="",mount_point="/data"} 1
+mount_exporter_write_probe_success{errno_class="enospc",mount_point="/full"} 0
+# HELP mount_exporter_write_probe_failures_total Total number of failed write probes by errno class
+# TYPE mount_exporter_write_probe_failures_total counter
+mount_exporter_write_probe_failures_total{errno_class="enospc",mount_point="/full"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"mount_exporter_write_probe_success", "mount_exporter_write_probe_failures_total"); err != nil {
+		t.Errorf("Unexpected write probe metrics: %v", err)
+	}
+
+	if got := testutil.CollectAndCount(collector, "mount_exporter_write_probe_duration_seconds"); got != 2 {
+		t.Errorf("Expected 2 latency histograms, got %d", got)
+	}
+}
+
+func TestWriteProbeCollector_SkippedProbes(t *testing.T) {
+	collector := NewWriteProbeCollector(config.WriteProbeConfig{
+		MountPoints: []string{"/unmounted", "/stuck"},
+		Directory:   ".mount-exporter",
+		Interval:    time.Minute,
+		Timeout:     time.Second,
+	})
+	collector.probeFunc = func(ctx context.Context, mountPoint string) *system.WriteProbeResult {
+		result := &system.WriteProbeResult{MountPoint: mountPoint}
+		if mountPoint == "/unmounted" {
+			result.Error = fmt.Errorf("skipped write probe on %s: %w", mountPoint, system.ErrNotMounted)
+		} else {
+			result.Error = fmt.Errorf("write probe on %s: %w: %w", mountPoint, system.ErrStillPending, context.DeadlineExceeded)
+		}
+		return result
+	}
+
+	collector.ProbeAll(context.Background())
+
+	// Probes that never wrote must not show up as fast writes
+	if got := testutil.CollectAndCount(collector, "mount_exporter_write_probe_duration_seconds"); got != 0 {
+		t.Errorf("Expected no latency histograms, got %d", got)
+	}
+
+	expected := `
+# HELP mount_exporter_write_probe_failures_total Total number of failed write probes by errno class
+# TYPE mount_exporter_write_probe_failures_total counter
+mount_exporter_write_probe_failures_total{errno_class="not_mounted",mount_point="/unmounted"} 1
+mount_exporter_write_probe_failures_total{errno_class="timeout",mount_point="/stuck"} 1
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "mount_exporter_write_probe_failures_total"); err != nil {
+		t.Errorf("Unexpected write probe failures: %v", err)
+	}
+}
+
+func TestWriteProbeCollector_RunOffScrapePath(t *testing.T) {
+	release := make(chan struct{})
+	collector := NewWriteProbeCollector(config.WriteProbeConfig{
+		MountPoints: []string{"/hung"},
+		Directory:   ".mount-exporter",
+		Interval:    time.Minute,
+		Timeout:     time.Second,
+	})
+	collector.probeFunc = func(ctx context.Context, mountPoint string) *system.WriteProbeResult {
+		<-release
+		return &system.WriteProbeResult{MountPoint: mountPoint}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- collector.Run(ctx) }()
+
+	// A hanging probe does not block collection
+	collected := make(chan int)
+	go func() { collected <- testutil.CollectAndCount(collector) }()
+	select {
+	case <-collected:
+	case <-time.After(time.Second):
+		t.Fatal("Collection blocked on a hanging write probe")
+	}
+
+	close(release)
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected Run to stop cleanly, got %v", err)
+	}
+}
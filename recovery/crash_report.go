@@ -0,0 +1,170 @@
+package recovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	crashReportPrefix = "crash-"
+	crashReportSuffix = ".json"
+)
+
+// CrashReport is the JSON document written for each recovered panic
+type CrashReport struct {
+	Timestamp   time.Time         `json:"timestamp"`
+	Component   string            `json:"component,omitempty"`
+	Method      string            `json:"method,omitempty"`
+	Route       string            `json:"route,omitempty"`
+	RequestID   string            `json:"request_id,omitempty"`
+	GoroutineID string            `json:"goroutine_id"`
+	PanicValue  string            `json:"panic_value"`
+	Message     string            `json:"message"`
+	Stack       string            `json:"stack"`
+	GoVersion   string            `json:"go_version"`
+	BuildInfo   map[string]string `json:"build_info,omitempty"`
+	ConfigHash  string            `json:"config_hash,omitempty"`
+}
+
+// crashReportWriter writes crash reports to a directory and rotates old ones
+type crashReportWriter struct {
+	mu         sync.Mutex
+	dir        string
+	maxReports int
+	buildInfo  map[string]string
+	configHash string
+	logger     Logger
+
+	// removeFunc overrides the removal of old reports (for testing)
+	removeFunc func(name string) error
+}
+
+// newCrashReportWriter creates a writer keeping at most maxReports reports
+// in dir, logging rotation failures to logger
+func newCrashReportWriter(dir string, maxReports int, buildInfo map[string]string, logger Logger) *crashReportWriter {
+	return &crashReportWriter{
+		dir:        dir,
+		maxReports: maxReports,
+		buildInfo:  buildInfo,
+		logger:     logger,
+	}
+}
+
+// setConfigHash sets the configuration fingerprint included in reports
+func (w *crashReportWriter) setConfigHash(hash string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.configHash = hash
+}
+
+// write stores a crash report for info and returns its path. Failing to
+// remove old reports is logged, since the report itself was written.
+func (w *crashReportWriter) write(info *PanicInfo) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	timestamp := info.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	report := CrashReport{
+		Timestamp:   timestamp,
+		Component:   info.Component,
+		Method:      info.Method,
+		Route:       info.Route,
+		RequestID:   info.RequestID,
+		GoroutineID: info.GoroutineID,
+		PanicValue:  fmt.Sprintf("%v", info.PanicValue),
+		Message:     info.Message,
+		Stack:       string(info.Stack),
+		GoVersion:   runtime.Version(),
+		BuildInfo:   w.buildInfo,
+		ConfigHash:  w.configHash,
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode crash report: %w", err)
+	}
+
+	if err := os.MkdirAll(w.dir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create crash report directory %s: %w", w.dir, err)
+	}
+
+	// Timestamps sort lexicographically, which rotation relies on
+	name := fmt.Sprintf("%s%s-%s%s", crashReportPrefix,
+		timestamp.UTC().Format("20060102T150405.000000000Z"), info.GoroutineID, crashReportSuffix)
+	path := filepath.Join(w.dir, name)
+
+	// Write to a temporary file first so a crash never leaves a partial report
+	tmp, err := os.CreateTemp(w.dir, ".crash-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create crash report: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write crash report: %w", err)
+	}
+
+	if err := w.rotate(); err != nil {
+		w.logger.Printf("Failed to rotate crash reports: %v", err)
+	}
+	return path, nil
+}
+
+// rotate removes the oldest reports beyond maxReports
+func (w *crashReportWriter) rotate() error {
+	reports, err := listCrashReports(w.dir)
+	if err != nil {
+		return err
+	}
+
+	remove := os.Remove
+	if w.removeFunc != nil {
+		remove = w.removeFunc
+	}
+
+	for len(reports) > w.maxReports {
+		if err := remove(reports[0]); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove old crash report: %w", err)
+		}
+		reports = reports[1:]
+	}
+
+	return nil
+}
+
+// listCrashReports returns the crash report paths in dir, oldest first
+func listCrashReports(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list crash reports: %w", err)
+	}
+
+	var reports []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, crashReportPrefix) && strings.HasSuffix(name, crashReportSuffix) {
+			reports = append(reports, filepath.Join(dir, name))
+		}
+	}
+
+	sort.Strings(reports)
+	return reports, nil
+}
@@ -0,0 +1,140 @@
+package recovery
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCrashReportWriter_Write(t *testing.T) {
+	dir := t.TempDir()
+	writer := newCrashReportWriter(dir, 5, map[string]string{"version": "1.2.3"}, &TestLogger{})
+	writer.setConfigHash("abc123")
+
+	path, err := writer.write(&PanicInfo{
+		Timestamp:   time.Now(),
+		GoroutineID: "42",
+		Component:   "collector",
+		PanicValue:  "boom",
+		Stack:       []byte("test stack"),
+		Message:     "Panic recovered: boom",
+	})
+	if err != nil {
+		t.Fatalf("Failed to write crash report: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read crash report: %v", err)
+	}
+
+	var report CrashReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to decode crash report: %v", err)
+	}
+
+	if report.PanicValue != "boom" {
+		t.Errorf("Expected panic value 'boom', got %s", report.PanicValue)
+	}
+
+	if report.GoroutineID != "42" {
+		t.Errorf("Expected goroutine ID '42', got %s", report.GoroutineID)
+	}
+
+	if report.Component != "collector" {
+		t.Errorf("Expected component 'collector', got %s", report.Component)
+	}
+
+	if report.Stack != "test stack" {
+		t.Errorf("Expected stack 'test stack', got %s", report.Stack)
+	}
+
+	if report.BuildInfo["version"] != "1.2.3" {
+		t.Errorf("Expected build version '1.2.3', got %s", report.BuildInfo["version"])
+	}
+
+	if report.ConfigHash != "abc123" {
+		t.Errorf("Expected config hash 'abc123', got %s", report.ConfigHash)
+	}
+
+	if report.GoVersion == "" {
+		t.Error("Expected Go version to be recorded")
+	}
+}
+
+func TestCrashReportWriter_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	writer := newCrashReportWriter(dir, 3, nil, &TestLogger{})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := writer.write(&PanicInfo{
+			Timestamp:   start.Add(time.Duration(i) * time.Second),
+			GoroutineID: "1",
+			PanicValue:  i,
+		})
+		if err != nil {
+			t.Fatalf("Failed to write crash report %d: %v", i, err)
+		}
+	}
+
+	reports, err := listCrashReports(dir)
+	if err != nil {
+		t.Fatalf("Failed to list crash reports: %v", err)
+	}
+
+	if len(reports) != 3 {
+		t.Fatalf("Expected 3 crash reports after rotation, got %d", len(reports))
+	}
+
+	// The newest reports are kept
+	data, err := os.ReadFile(reports[0])
+	if err != nil {
+		t.Fatalf("Failed to read crash report: %v", err)
+	}
+
+	var report CrashReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to decode crash report: %v", err)
+	}
+
+	if report.PanicValue != "2" {
+		t.Errorf("Expected oldest kept report to be panic 2, got %s", report.PanicValue)
+	}
+
+	// No temporary files are left behind
+	leftovers, _ := filepath.Glob(filepath.Join(dir, ".crash-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("Expected no temporary files, got %v", leftovers)
+	}
+}
+
+func TestCrashReportWriter_RotationFailure(t *testing.T) {
+	dir := t.TempDir()
+	logger := &TestLogger{}
+	writer := newCrashReportWriter(dir, 1, nil, logger)
+	writer.removeFunc = func(string) error { return os.ErrPermission }
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		path, err := writer.write(&PanicInfo{
+			Timestamp:   start.Add(time.Duration(i) * time.Second),
+			GoroutineID: "1",
+			PanicValue:  i,
+		})
+		if err != nil {
+			t.Fatalf("Expected crash report %d to be written despite the rotation failure, got %v", i, err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected crash report at %s: %v", path, err)
+		}
+	}
+
+	messages := logger.GetMessages()
+	if len(messages) != 1 || !strings.Contains(messages[0], "Failed to rotate crash reports") {
+		t.Errorf("Expected the rotation failure to be logged, got %v", messages)
+	}
+}
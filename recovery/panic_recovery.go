@@ -4,25 +4,45 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// ExitCodeTooManyPanics is the exit code used when the panic limit is exceeded
+const ExitCodeTooManyPanics = 70
+
+// unknownComponent labels panics recovered without a component
+const unknownComponent = "unknown"
+
 // PanicHandler handles panic recovery
 type PanicHandler struct {
-	mu           sync.RWMutex
-	recovered    map[string]int64
-	handlers     []PanicHandlerFunc
-	logger       Logger
-	enabled      bool
+	mu             sync.RWMutex
+	recovered      map[string]int64
+	handlers       []PanicHandlerFunc
+	logger         Logger
+	enabled        bool
 	maxStackFrames int
+	panicsTotal    *prometheus.CounterVec
+	crashReports   *crashReportWriter
+	maxPanics      int
+	panicWindow    time.Duration
+	recentPanics   []time.Time
+	exitFunc       func(code int)
 }
 
 // PanicInfo contains information about a recovered panic
 type PanicInfo struct {
 	Timestamp   time.Time
 	GoroutineID string
+	Component   string
+	Method      string
+	Route       string
+	RequestID   string
 	PanicValue  interface{}
 	Stack       []byte
 	Message     string
@@ -45,11 +65,23 @@ func (l *DefaultLogger) Printf(format string, args ...interface{}) {
 
 // PanicRecoveryConfig holds configuration for panic recovery
 type PanicRecoveryConfig struct {
-	Enabled         bool
-	Logger          Logger
-	Handlers        []PanicHandlerFunc
-	MaxStackFrames  int
-	LogLevel        string
+	Enabled        bool
+	Logger         Logger
+	Handlers       []PanicHandlerFunc
+	MaxStackFrames int
+	LogLevel       string
+	// CrashReportDir enables writing a JSON crash report per recovered panic
+	CrashReportDir string
+	// MaxCrashReports is the number of crash reports kept (default 10)
+	MaxCrashReports int
+	// BuildInfo is included in crash reports (e.g. version, commit)
+	BuildInfo map[string]string
+	// MaxPanics is the number of panics tolerated within PanicWindow before
+	// the process exits with ExitCodeTooManyPanics; zero disables the limit
+	MaxPanics   int
+	PanicWindow time.Duration
+	// ExitFunc is called when the panic limit is exceeded (default os.Exit)
+	ExitFunc func(code int)
 }
 
 // NewPanicHandler creates a new panic handler
@@ -62,12 +94,58 @@ func NewPanicHandler(config PanicRecoveryConfig) *PanicHandler {
 		config.MaxStackFrames = 50
 	}
 
-	return &PanicHandler{
+	if config.MaxCrashReports <= 0 {
+		config.MaxCrashReports = 10
+	}
+
+	if config.PanicWindow <= 0 {
+		config.PanicWindow = time.Minute
+	}
+
+	if config.ExitFunc == nil {
+		config.ExitFunc = os.Exit
+	}
+
+	ph := &PanicHandler{
 		recovered:      make(map[string]int64),
 		handlers:       config.Handlers,
 		logger:         config.Logger,
 		enabled:        config.Enabled,
 		maxStackFrames: config.MaxStackFrames,
+		panicsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "mount_exporter",
+				Name:      "panics_recovered_total",
+				Help:      "Total number of recovered panics",
+			},
+			[]string{"component"},
+		),
+		maxPanics:   config.MaxPanics,
+		panicWindow: config.PanicWindow,
+		exitFunc:    config.ExitFunc,
+	}
+
+	if config.CrashReportDir != "" {
+		ph.crashReports = newCrashReportWriter(config.CrashReportDir, config.MaxCrashReports, config.BuildInfo, config.Logger)
+	}
+
+	return ph
+}
+
+// Describe implements prometheus.Collector interface
+func (ph *PanicHandler) Describe(ch chan<- *prometheus.Desc) {
+	ph.panicsTotal.Describe(ch)
+}
+
+// Collect implements prometheus.Collector interface
+func (ph *PanicHandler) Collect(ch chan<- prometheus.Metric) {
+	ph.panicsTotal.Collect(ch)
+}
+
+// SetConfigHash sets the configuration fingerprint recorded in crash reports
+func (ph *PanicHandler) SetConfigHash(hash string) {
+	if ph.crashReports != nil {
+		ph.crashReports.setConfigHash(hash)
 	}
 }
 
@@ -77,27 +155,74 @@ func (ph *PanicHandler) Recover(info *PanicInfo) {
 		return
 	}
 
+	component := info.Component
+	if component == "" {
+		component = unknownComponent
+	}
+
 	ph.mu.Lock()
 	ph.recovered[info.GoroutineID]++
+	handlers := append([]PanicHandlerFunc{}, ph.handlers...)
+	limitExceeded := ph.recordPanic(info.Timestamp)
 	ph.mu.Unlock()
 
+	ph.panicsTotal.WithLabelValues(component).Inc()
+
 	// Log the panic
 	ph.logPanic(info)
 
+	if ph.crashReports != nil {
+		if path, err := ph.crashReports.write(info); err != nil {
+			ph.logger.Printf("Failed to write crash report: %v", err)
+		} else {
+			ph.logger.Printf("Crash report written to %s", path)
+		}
+	}
+
 	// Call all handlers
-	for _, handler := range ph.handlers {
+	for _, handler := range handlers {
 		if handler != nil {
-			// Run handlers in separate goroutines to avoid panics in handlers
-			go func(h PanicHandlerFunc) {
-				defer func() {
-					if r := recover(); r != nil {
-						ph.logger.Printf("Panic in panic handler: %v", r)
-					}
-				}()
-				h(*info)
-			}(handler)
+			ph.runHandler(handler, *info)
 		}
 	}
+
+	if limitExceeded {
+		ph.logger.Printf("Too many panics (more than %d within %v), exiting", ph.maxPanics, ph.panicWindow)
+		ph.exitFunc(ExitCodeTooManyPanics)
+	}
+}
+
+// recordPanic tracks a panic in the sliding window and reports whether the
+// panic limit has been exceeded. Callers must hold ph.mu for writing.
+func (ph *PanicHandler) recordPanic(at time.Time) bool {
+	if ph.maxPanics <= 0 {
+		return false
+	}
+
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	cutoff := at.Add(-ph.panicWindow)
+	recent := ph.recentPanics[:0]
+	for _, t := range ph.recentPanics {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	ph.recentPanics = append(recent, at)
+
+	return len(ph.recentPanics) > ph.maxPanics
+}
+
+// runHandler calls a single panic handler, isolating panics raised by the handler itself
+func (ph *PanicHandler) runHandler(h PanicHandlerFunc, info PanicInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			ph.logger.Printf("Panic in panic handler: %v", r)
+		}
+	}()
+	h(info)
 }
 
 // RecoverWithFunc recovers from a panic in a function and returns an error
@@ -126,6 +251,7 @@ func (ph *PanicHandler) RecoverWithContext(ctx context.Context, fn func(context.
 	defer func() {
 		if r := recover(); r != nil {
 			info := ph.capturePanic(r)
+			annotateFromContext(info, ctx)
 			ph.Recover(info)
 			err = fmt.Errorf("panic recovered: %v", r)
 		}
@@ -134,6 +260,51 @@ func (ph *PanicHandler) RecoverWithContext(ctx context.Context, fn func(context.
 	return fn(ctx)
 }
 
+// componentKey is the context key for the component name
+type componentKey struct{}
+
+// WithComponent returns a context that attributes recovered panics to component
+func WithComponent(ctx context.Context, component string) context.Context {
+	return context.WithValue(ctx, componentKey{}, component)
+}
+
+// ComponentFromContext returns the component stored in ctx, if any
+func ComponentFromContext(ctx context.Context) string {
+	component, _ := ctx.Value(componentKey{}).(string)
+	return component
+}
+
+// RequestInfo identifies the HTTP request being served when a panic occurs
+type RequestInfo struct {
+	ID     string
+	Method string
+	Route  string
+}
+
+// requestInfoKey is the context key for the request information
+type requestInfoKey struct{}
+
+// WithRequestInfo returns a context that attributes recovered panics to an HTTP request
+func WithRequestInfo(ctx context.Context, req RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, req)
+}
+
+// RequestInfoFromContext returns the request information stored in ctx, if any
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	req, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return req, ok
+}
+
+// annotateFromContext copies the component and request information from ctx into info
+func annotateFromContext(info *PanicInfo, ctx context.Context) {
+	info.Component = ComponentFromContext(ctx)
+	if req, ok := RequestInfoFromContext(ctx); ok {
+		info.Method = req.Method
+		info.Route = req.Route
+		info.RequestID = req.ID
+	}
+}
+
 // capturePanic captures panic information
 func (ph *PanicHandler) capturePanic(panicValue interface{}) *PanicInfo {
 	stack := make([]byte, 4096)
@@ -163,6 +334,12 @@ func (ph *PanicHandler) logPanic(info *PanicInfo) {
 	ph.logger.Printf("=== PANIC RECOVERED ===")
 	ph.logger.Printf("Timestamp: %s", info.Timestamp.Format(time.RFC3339))
 	ph.logger.Printf("GoroutineID: %s", info.GoroutineID)
+	if info.Component != "" {
+		ph.logger.Printf("Component: %s", info.Component)
+	}
+	if info.Route != "" {
+		ph.logger.Printf("Request: %s %s (id %s)", info.Method, info.Route, info.RequestID)
+	}
 	ph.logger.Printf("PanicValue: %v", info.PanicValue)
 	ph.logger.Printf("Message: %s", info.Message)
 	ph.logger.Printf("Stack trace:\n%s", string(info.Stack))
@@ -175,9 +352,9 @@ func (ph *PanicHandler) GetStats() map[string]interface{} {
 	defer ph.mu.RUnlock()
 
 	stats := map[string]interface{}{
-		"total_recovered": len(ph.recovered),
+		"total_recovered":  len(ph.recovered),
 		"goroutine_counts": make(map[string]int64),
-		"enabled": ph.enabled,
+		"enabled":          ph.enabled,
 	}
 
 	for goroutineID, count := range ph.recovered {
@@ -228,19 +405,10 @@ func getGoroutineID() string {
 	}
 
 	// Extract goroutine ID from stack trace
-	stack := string(buf[:n])
 	// Format: "goroutine 123 [running]:"
-	for i := 0; i < len(stack); i++ {
-		if stack[i:i+9] == "goroutine " {
-			start := i + 9
-			end := start
-			for end < len(stack) && stack[end] != ' ' && stack[end] != '[' {
-				end++
-			}
-			if end > start {
-				return stack[start:end]
-			}
-		}
+	stack := strings.TrimPrefix(string(buf[:n]), "goroutine ")
+	if end := strings.IndexByte(stack, ' '); end > 0 {
+		return stack[:end]
 	}
 
 	return "unknown"
@@ -275,6 +443,7 @@ func SafeGoWithContext(ph *PanicHandler, ctx context.Context, fn func(context.Co
 		defer func() {
 			if r := recover(); r != nil {
 				info := ph.capturePanic(r)
+				annotateFromContext(info, ctx)
 				ph.Recover(info)
 			}
 		}()
@@ -307,4 +476,4 @@ func NewDefaultPanicHandler() *PanicHandler {
 		Handlers:       DefaultPanicHandlers(),
 		MaxStackFrames: 50,
 	})
-}
\ No newline at end of file
+}
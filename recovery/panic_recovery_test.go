@@ -6,6 +6,9 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 // TestLogger implements Logger interface for testing
@@ -415,6 +418,156 @@ func TestGetGoroutineID(t *testing.T) {
 	}
 }
 
+func TestPanicHandler_Metrics(t *testing.T) {
+	handler := NewPanicHandler(PanicRecoveryConfig{
+		Enabled: true,
+		Logger:  &TestLogger{},
+	})
+
+	ctx := WithComponent(context.Background(), "collector")
+	for i := 0; i < 2; i++ {
+		handler.RecoverWithContext(ctx, func(ctx context.Context) error {
+			panic("test panic")
+		})
+	}
+	handler.RecoverWithFunc(func() error {
+		panic("test panic")
+	})
+
+	if got := testutil.ToFloat64(handler.panicsTotal.WithLabelValues("collector")); got != 2 {
+		t.Errorf("Expected 2 panics for component 'collector', got %v", got)
+	}
+
+	if got := testutil.ToFloat64(handler.panicsTotal.WithLabelValues("unknown")); got != 1 {
+		t.Errorf("Expected 1 panic for component 'unknown', got %v", got)
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(handler); err != nil {
+		t.Fatalf("Failed to register panic handler: %v", err)
+	}
+}
+
+func TestPanicHandler_CrashReports(t *testing.T) {
+	dir := t.TempDir()
+	handler := NewPanicHandler(PanicRecoveryConfig{
+		Enabled:        true,
+		Logger:         &TestLogger{},
+		CrashReportDir: dir,
+	})
+
+	handler.RecoverWithFunc(func() error {
+		panic("test panic")
+	})
+
+	reports, err := listCrashReports(dir)
+	if err != nil {
+		t.Fatalf("Failed to list crash reports: %v", err)
+	}
+
+	if len(reports) != 1 {
+		t.Errorf("Expected 1 crash report, got %d", len(reports))
+	}
+}
+
+func TestPanicHandler_PanicLimit(t *testing.T) {
+	var exitCodes []int
+	handler := NewPanicHandler(PanicRecoveryConfig{
+		Enabled:     true,
+		Logger:      &TestLogger{},
+		MaxPanics:   2,
+		PanicWindow: time.Minute,
+		ExitFunc: func(code int) {
+			exitCodes = append(exitCodes, code)
+		},
+	})
+
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		handler.Recover(&PanicInfo{Timestamp: now, GoroutineID: "1", PanicValue: "test panic"})
+	}
+
+	if len(exitCodes) != 0 {
+		t.Fatalf("Expected no exit within the panic limit, got %v", exitCodes)
+	}
+
+	handler.Recover(&PanicInfo{Timestamp: now, GoroutineID: "1", PanicValue: "test panic"})
+
+	if len(exitCodes) != 1 || exitCodes[0] != ExitCodeTooManyPanics {
+		t.Errorf("Expected exit with code %d, got %v", ExitCodeTooManyPanics, exitCodes)
+	}
+}
+
+func TestPanicHandler_PanicLimitWindow(t *testing.T) {
+	exited := false
+	handler := NewPanicHandler(PanicRecoveryConfig{
+		Enabled:     true,
+		Logger:      &TestLogger{},
+		MaxPanics:   1,
+		PanicWindow: time.Minute,
+		ExitFunc: func(code int) {
+			exited = true
+		},
+	})
+
+	// Panics further apart than the window never accumulate
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		handler.Recover(&PanicInfo{
+			Timestamp:   start.Add(time.Duration(i) * 2 * time.Minute),
+			GoroutineID: "1",
+			PanicValue:  "test panic",
+		})
+	}
+
+	if exited {
+		t.Error("Expected no exit when panics are spread beyond the window")
+	}
+}
+
+func TestComponentFromContext(t *testing.T) {
+	if got := ComponentFromContext(context.Background()); got != "" {
+		t.Errorf("Expected empty component, got %s", got)
+	}
+
+	ctx := WithComponent(context.Background(), "watcher")
+	if got := ComponentFromContext(ctx); got != "watcher" {
+		t.Errorf("Expected component 'watcher', got %s", got)
+	}
+}
+
+func TestPanicHandler_RecoverWithRequestInfo(t *testing.T) {
+	var recovered PanicInfo
+	handler := NewPanicHandler(PanicRecoveryConfig{
+		Enabled: true,
+		Logger:  &TestLogger{},
+		Handlers: []PanicHandlerFunc{
+			func(info PanicInfo) {
+				recovered = info
+			},
+		},
+	})
+
+	ctx := WithComponent(context.Background(), "http")
+	ctx = WithRequestInfo(ctx, RequestInfo{ID: "req-1", Method: "GET", Route: "/metrics"})
+
+	err := handler.RecoverWithContext(ctx, func(ctx context.Context) error {
+		panic("test panic")
+	})
+	if err == nil {
+		t.Fatal("Expected error from recovered panic")
+	}
+
+	if recovered.Component != "http" || recovered.Method != "GET" || recovered.Route != "/metrics" || recovered.RequestID != "req-1" {
+		t.Errorf("Unexpected panic info: component=%q method=%q route=%q request_id=%q",
+			recovered.Component, recovered.Method, recovered.Route, recovered.RequestID)
+	}
+
+	if _, ok := RequestInfoFromContext(context.Background()); ok {
+		t.Error("Expected no request info in empty context")
+	}
+}
+
 // Helper function to check if a string contains a substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
@@ -0,0 +1,377 @@
+package recovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RestartPolicy controls when a supervised worker is restarted after it exits
+type RestartPolicy int
+
+const (
+	// RestartOnPanic restarts the worker only when it panics
+	RestartOnPanic RestartPolicy = iota
+	// RestartAlways restarts the worker whenever it exits
+	RestartAlways
+	// RestartNever never restarts the worker
+	RestartNever
+)
+
+// String returns the string representation of the restart policy
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartOnPanic:
+		return "on-panic"
+	case RestartAlways:
+		return "always"
+	case RestartNever:
+		return "never"
+	default:
+		return "unknown"
+	}
+}
+
+// WorkerState is the lifecycle state of a supervised worker
+type WorkerState int
+
+const (
+	WorkerRunning WorkerState = iota
+	WorkerRestarting
+	WorkerStopped
+	WorkerFailed
+)
+
+// String returns the string representation of the worker state
+func (s WorkerState) String() string {
+	switch s {
+	case WorkerRunning:
+		return "running"
+	case WorkerRestarting:
+		return "restarting"
+	case WorkerStopped:
+		return "stopped"
+	case WorkerFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	// ErrSupervisorStopped is returned when starting a worker on a stopped supervisor
+	ErrSupervisorStopped = errors.New("supervisor is stopped")
+	// ErrDuplicateWorker is returned when a worker with the same name is already supervised
+	ErrDuplicateWorker = errors.New("worker already supervised")
+)
+
+// WorkerSpec describes a long-lived worker run by a Supervisor
+type WorkerSpec struct {
+	Name string
+	// Run is the worker body; it should return when ctx is cancelled
+	Run    func(ctx context.Context) error
+	Policy RestartPolicy
+	// InitialBackoff is the delay before the first restart (default 1s);
+	// it doubles on each consecutive restart up to MaxBackoff (default 1m)
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxRestarts is the number of restarts allowed within RestartWindow
+	// before the worker is marked failed (default 5)
+	MaxRestarts   int
+	RestartWindow time.Duration
+}
+
+// WorkerStatus reports the state of a supervised worker
+type WorkerStatus struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	Policy    string    `json:"policy"`
+	Restarts  int       `json:"restarts"`
+	StartedAt time.Time `json:"started_at"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// SupervisorConfig holds configuration for a Supervisor
+type SupervisorConfig struct {
+	// PanicHandler records panics raised by workers; when nil they are only logged
+	PanicHandler *PanicHandler
+	Logger       Logger
+}
+
+// Supervisor runs named workers and restarts them according to their policy
+type Supervisor struct {
+	mu           sync.RWMutex
+	panicHandler *PanicHandler
+	logger       Logger
+	workers      map[string]*worker
+	stopped      bool
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+}
+
+// worker is the supervisor's bookkeeping for a single WorkerSpec
+type worker struct {
+	spec      WorkerSpec
+	state     WorkerState
+	restarts  int
+	startedAt time.Time
+	lastError string
+	history   []time.Time
+}
+
+// NewSupervisor creates a new supervisor
+func NewSupervisor(config SupervisorConfig) *Supervisor {
+	if config.Logger == nil {
+		config.Logger = &DefaultLogger{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Supervisor{
+		panicHandler: config.PanicHandler,
+		logger:       config.Logger,
+		workers:      make(map[string]*worker),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// SetPanicHandler sets the panic handler used for worker panics
+func (s *Supervisor) SetPanicHandler(ph *PanicHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.panicHandler = ph
+}
+
+// Go starts a supervised worker
+func (s *Supervisor) Go(spec WorkerSpec) error {
+	if spec.Name == "" {
+		return fmt.Errorf("worker name is required")
+	}
+	if spec.Run == nil {
+		return fmt.Errorf("worker %s has no run function", spec.Name)
+	}
+	if spec.InitialBackoff <= 0 {
+		spec.InitialBackoff = time.Second
+	}
+	if spec.MaxBackoff <= 0 {
+		spec.MaxBackoff = time.Minute
+	}
+	if spec.MaxBackoff < spec.InitialBackoff {
+		spec.MaxBackoff = spec.InitialBackoff
+	}
+	if spec.MaxRestarts <= 0 {
+		spec.MaxRestarts = 5
+	}
+	if spec.RestartWindow <= 0 {
+		spec.RestartWindow = 5 * time.Minute
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return ErrSupervisorStopped
+	}
+	if _, exists := s.workers[spec.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateWorker, spec.Name)
+	}
+
+	w := &worker{spec: spec, state: WorkerRunning, startedAt: time.Now()}
+	s.workers[spec.Name] = w
+
+	s.wg.Add(1)
+	go s.supervise(w)
+
+	return nil
+}
+
+// supervise runs a worker until it stops, fails or the supervisor is stopped
+func (s *Supervisor) supervise(w *worker) {
+	defer s.wg.Done()
+
+	backoff := w.spec.InitialBackoff
+	ctx := WithComponent(s.ctx, w.spec.Name)
+
+	for {
+		started := time.Now()
+		panicked, err := s.runOnce(ctx, w)
+
+		if s.ctx.Err() != nil {
+			s.setState(w, WorkerStopped, nil)
+			return
+		}
+
+		restart := w.spec.Policy == RestartAlways || (w.spec.Policy == RestartOnPanic && panicked)
+		if !restart {
+			state := WorkerStopped
+			if err != nil {
+				state = WorkerFailed
+			}
+			s.setState(w, state, err)
+			return
+		}
+
+		// A worker that ran for longer than the maximum backoff is considered
+		// healthy again, so its next restart starts from the initial backoff
+		if time.Since(started) >= w.spec.MaxBackoff {
+			backoff = w.spec.InitialBackoff
+		}
+
+		if !s.recordRestart(w, err) {
+			s.logger.Printf("Worker %s restarted more than %d times within %v, giving up",
+				w.spec.Name, w.spec.MaxRestarts, w.spec.RestartWindow)
+			return
+		}
+
+		s.logger.Printf("Worker %s exited (%v), restarting in %v", w.spec.Name, err, backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			s.setState(w, WorkerStopped, nil)
+			return
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > w.spec.MaxBackoff {
+			backoff = w.spec.MaxBackoff
+		}
+
+		s.mu.Lock()
+		w.state = WorkerRunning
+		w.startedAt = time.Now()
+		s.mu.Unlock()
+	}
+}
+
+// runOnce runs the worker body once, recovering and reporting a panic
+func (s *Supervisor) runOnce(ctx context.Context, w *worker) (panicked bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			err = fmt.Errorf("panic recovered: %v", r)
+
+			s.mu.RLock()
+			ph := s.panicHandler
+			s.mu.RUnlock()
+
+			if ph != nil && ph.IsEnabled() {
+				info := ph.capturePanic(r)
+				annotateFromContext(info, ctx)
+				ph.Recover(info)
+			} else {
+				s.logger.Printf("Worker %s panicked: %v", w.spec.Name, r)
+			}
+		}
+	}()
+
+	return false, w.spec.Run(ctx)
+}
+
+// recordRestart counts a restart in the worker's window and reports whether
+// it is still within MaxRestarts; otherwise the worker is marked failed
+func (s *Supervisor) recordRestart(w *worker, err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-w.spec.RestartWindow)
+	kept := w.history[:0]
+	for _, t := range w.history {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	w.history = kept
+
+	if err != nil {
+		w.lastError = err.Error()
+	}
+
+	if len(w.history) >= w.spec.MaxRestarts {
+		w.state = WorkerFailed
+		return false
+	}
+
+	w.history = append(w.history, now)
+	w.restarts++
+	w.state = WorkerRestarting
+	return true
+}
+
+// setState records the final state of a worker
+func (s *Supervisor) setState(w *worker, state WorkerState, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.state = state
+	if err != nil {
+		w.lastError = err.Error()
+	}
+}
+
+// Stop cancels all workers and waits for them to return or ctx to expire
+func (s *Supervisor) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for workers to stop: %w", ctx.Err())
+	}
+}
+
+// Status returns the status of all workers, sorted by name
+func (s *Supervisor) Status() []WorkerStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]WorkerStatus, 0, len(s.workers))
+	for _, w := range s.workers {
+		statuses = append(statuses, WorkerStatus{
+			Name:      w.spec.Name,
+			State:     w.state.String(),
+			Policy:    w.spec.Policy.String(),
+			Restarts:  w.restarts,
+			StartedAt: w.startedAt,
+			LastError: w.lastError,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// Healthy reports whether no worker has failed
+func (s *Supervisor) Healthy() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, w := range s.workers {
+		if w.state == WorkerFailed {
+			return false
+		}
+	}
+	return true
+}
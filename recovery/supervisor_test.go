@@ -0,0 +1,261 @@
+package recovery
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// waitFor polls cond until it returns true or the timeout expires
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Condition not met before timeout")
+}
+
+func workerStatus(s *Supervisor, name string) WorkerStatus {
+	for _, status := range s.Status() {
+		if status.Name == name {
+			return status
+		}
+	}
+	return WorkerStatus{}
+}
+
+func TestRestartPolicy_String(t *testing.T) {
+	tests := []struct {
+		policy   RestartPolicy
+		expected string
+	}{
+		{RestartOnPanic, "on-panic"},
+		{RestartAlways, "always"},
+		{RestartNever, "never"},
+		{RestartPolicy(99), "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.String(); got != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, got)
+		}
+	}
+}
+
+func TestSupervisor_RestartOnPanic(t *testing.T) {
+	ph := NewPanicHandler(PanicRecoveryConfig{Enabled: true, Logger: &TestLogger{}})
+	s := NewSupervisor(SupervisorConfig{PanicHandler: ph, Logger: &TestLogger{}})
+	defer s.Stop(context.Background())
+
+	var runs int32
+	err := s.Go(WorkerSpec{
+		Name:           "loop",
+		Policy:         RestartOnPanic,
+		InitialBackoff: time.Millisecond,
+		Run: func(ctx context.Context) error {
+			if atomic.AddInt32(&runs, 1) < 3 {
+				panic("loop exploded")
+			}
+			<-ctx.Done()
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to start worker: %v", err)
+	}
+
+	waitFor(t, time.Second, func() bool {
+		return atomic.LoadInt32(&runs) == 3 && workerStatus(s, "loop").State == "running"
+	})
+
+	status := workerStatus(s, "loop")
+	if status.Restarts != 2 {
+		t.Errorf("Expected 2 restarts, got %d", status.Restarts)
+	}
+	if status.LastError == "" {
+		t.Error("Expected last error to record the panic")
+	}
+	if got := testutil.ToFloat64(ph.panicsTotal.WithLabelValues("loop")); got != 2 {
+		t.Errorf("Expected 2 panics recorded for component loop, got %v", got)
+	}
+	if !s.Healthy() {
+		t.Error("Expected supervisor to be healthy")
+	}
+}
+
+func TestSupervisor_OnPanicDoesNotRestartOnError(t *testing.T) {
+	s := NewSupervisor(SupervisorConfig{Logger: &TestLogger{}})
+	defer s.Stop(context.Background())
+
+	var runs int32
+	s.Go(WorkerSpec{
+		Name:           "errors",
+		Policy:         RestartOnPanic,
+		InitialBackoff: time.Millisecond,
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return errors.New("boom")
+		},
+	})
+
+	waitFor(t, time.Second, func() bool {
+		return workerStatus(s, "errors").State == "failed"
+	})
+
+	if got := atomic.LoadInt32(&runs); got != 1 {
+		t.Errorf("Expected 1 run, got %d", got)
+	}
+	if s.Healthy() {
+		t.Error("Expected supervisor to be unhealthy after a worker failed")
+	}
+}
+
+func TestSupervisor_NeverRestart(t *testing.T) {
+	s := NewSupervisor(SupervisorConfig{Logger: &TestLogger{}})
+	defer s.Stop(context.Background())
+
+	var runs int32
+	s.Go(WorkerSpec{
+		Name:   "once",
+		Policy: RestartNever,
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		},
+	})
+
+	waitFor(t, time.Second, func() bool {
+		return workerStatus(s, "once").State == "stopped"
+	})
+
+	if got := atomic.LoadInt32(&runs); got != 1 {
+		t.Errorf("Expected 1 run, got %d", got)
+	}
+	if !s.Healthy() {
+		t.Error("Expected a cleanly stopped worker to keep the supervisor healthy")
+	}
+}
+
+func TestSupervisor_MaxRestarts(t *testing.T) {
+	s := NewSupervisor(SupervisorConfig{Logger: &TestLogger{}})
+	defer s.Stop(context.Background())
+
+	var runs int32
+	s.Go(WorkerSpec{
+		Name:           "flapping",
+		Policy:         RestartAlways,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+		MaxRestarts:    3,
+		RestartWindow:  time.Minute,
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		},
+	})
+
+	waitFor(t, time.Second, func() bool {
+		return workerStatus(s, "flapping").State == "failed"
+	})
+
+	if got := atomic.LoadInt32(&runs); got != 4 {
+		t.Errorf("Expected 4 runs (1 + 3 restarts), got %d", got)
+	}
+	if got := workerStatus(s, "flapping").Restarts; got != 3 {
+		t.Errorf("Expected 3 restarts, got %d", got)
+	}
+}
+
+func TestSupervisor_Backoff(t *testing.T) {
+	s := NewSupervisor(SupervisorConfig{Logger: &TestLogger{}})
+	defer s.Stop(context.Background())
+
+	var starts []time.Time
+	done := make(chan struct{})
+	s.Go(WorkerSpec{
+		Name:           "backoff",
+		Policy:         RestartAlways,
+		InitialBackoff: 20 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Run: func(ctx context.Context) error {
+			starts = append(starts, time.Now())
+			if len(starts) == 3 {
+				close(done)
+				<-ctx.Done()
+			}
+			return nil
+		},
+	})
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Worker was not restarted")
+	}
+
+	first := starts[1].Sub(starts[0])
+	second := starts[2].Sub(starts[1])
+	if first < 20*time.Millisecond || second < 40*time.Millisecond {
+		t.Errorf("Expected exponential backoff, got %v then %v", first, second)
+	}
+}
+
+func TestSupervisor_Stop(t *testing.T) {
+	s := NewSupervisor(SupervisorConfig{Logger: &TestLogger{}})
+
+	s.Go(WorkerSpec{
+		Name:   "blocking",
+		Policy: RestartAlways,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Failed to stop supervisor: %v", err)
+	}
+
+	status := workerStatus(s, "blocking")
+	if status.State != "stopped" || status.LastError != "" {
+		t.Errorf("Expected worker stopped without error, got %+v", status)
+	}
+
+	err := s.Go(WorkerSpec{Name: "late", Run: func(ctx context.Context) error { return nil }})
+	if !errors.Is(err, ErrSupervisorStopped) {
+		t.Errorf("Expected ErrSupervisorStopped, got %v", err)
+	}
+}
+
+func TestSupervisor_Go_Validation(t *testing.T) {
+	s := NewSupervisor(SupervisorConfig{Logger: &TestLogger{}})
+	defer s.Stop(context.Background())
+
+	if err := s.Go(WorkerSpec{Run: func(ctx context.Context) error { return nil }}); err == nil {
+		t.Error("Expected error for worker without a name")
+	}
+	if err := s.Go(WorkerSpec{Name: "no-run"}); err == nil {
+		t.Error("Expected error for worker without a run function")
+	}
+
+	block := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+	if err := s.Go(WorkerSpec{Name: "dup", Run: block}); err != nil {
+		t.Fatalf("Failed to start worker: %v", err)
+	}
+	if err := s.Go(WorkerSpec{Name: "dup", Run: block}); !errors.Is(err, ErrDuplicateWorker) {
+		t.Errorf("Expected ErrDuplicateWorker, got %v", err)
+	}
+}
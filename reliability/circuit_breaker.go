@@ -1,6 +1,7 @@
 package reliability
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"time"
@@ -15,16 +16,32 @@ const (
 	StateOpen
 )
 
+var (
+	// ErrOpen is returned when the circuit breaker rejects a request because it is open
+	ErrOpen = errors.New("circuit breaker is open")
+	// ErrTooManyRequests is returned when all half-open trial slots are in use
+	ErrTooManyRequests = errors.New("circuit breaker is half-open: too many trial requests")
+)
+
 // CircuitBreaker implements the circuit breaker pattern
 type CircuitBreaker struct {
-	name          string
-	maxFailures   int
-	resetTimeout  time.Duration
-	mu            sync.RWMutex
-	state         State
-	failures      int
-	lastFailTime  time.Time
-	onStateChange func(name string, from State, to State)
+	name                 string
+	maxFailures          int
+	resetTimeout         time.Duration
+	halfOpenMaxRequests  int
+	successThreshold     int
+	failureRateThreshold float64
+	minRequests          int
+	mu                   sync.RWMutex
+	state                State
+	generation           uint64
+	failures             int
+	openedAt             time.Time
+	halfOpenInFlight     int
+	halfOpenSuccesses    int
+	window               *outcomeWindow
+	isSuccessful         func(err error) bool
+	onStateChange        func(name string, from State, to State)
 }
 
 // CircuitBreakerConfig holds configuration for the circuit breaker
@@ -32,6 +49,24 @@ type CircuitBreakerConfig struct {
 	Name         string
 	MaxFailures  int
 	ResetTimeout time.Duration
+	// HalfOpenMaxRequests caps the number of concurrent trial calls
+	// admitted while the breaker is half-open (default 1)
+	HalfOpenMaxRequests int
+	// SuccessThreshold is the number of successful trial calls required
+	// to close the breaker from half-open (default 1)
+	SuccessThreshold int
+	// FailureRateThreshold opens the breaker when the failure ratio over
+	// the sliding window reaches it, in (0, 1]; zero disables the check
+	FailureRateThreshold float64
+	// WindowSize is the number of most recent calls kept in the sliding
+	// window (default 20)
+	WindowSize int
+	// MinRequests is the number of calls the window must contain before
+	// the failure rate is evaluated (default WindowSize)
+	MinRequests int
+	// IsSuccessful classifies the error returned by a call; calls for which
+	// it returns true do not count as failures (default err == nil)
+	IsSuccessful  func(err error) bool
 	OnStateChange func(name string, from State, to State)
 }
 
@@ -43,49 +78,126 @@ func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
 	if config.ResetTimeout <= 0 {
 		config.ResetTimeout = 60 * time.Second
 	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = 1
+	}
+	if config.SuccessThreshold <= 0 {
+		config.SuccessThreshold = 1
+	}
+	if config.FailureRateThreshold > 1 {
+		config.FailureRateThreshold = 1
+	}
+	if config.WindowSize <= 0 {
+		config.WindowSize = 20
+	}
+	if config.MinRequests <= 0 || config.MinRequests > config.WindowSize {
+		config.MinRequests = config.WindowSize
+	}
+	if config.IsSuccessful == nil {
+		config.IsSuccessful = func(err error) bool {
+			return err == nil
+		}
+	}
 
 	return &CircuitBreaker{
-		name:          config.Name,
-		maxFailures:   config.MaxFailures,
-		resetTimeout:  config.ResetTimeout,
-		state:         StateClosed,
-		onStateChange: config.OnStateChange,
+		name:                 config.Name,
+		maxFailures:          config.MaxFailures,
+		resetTimeout:         config.ResetTimeout,
+		halfOpenMaxRequests:  config.HalfOpenMaxRequests,
+		successThreshold:     config.SuccessThreshold,
+		failureRateThreshold: config.FailureRateThreshold,
+		minRequests:          config.MinRequests,
+		state:                StateClosed,
+		window:               newOutcomeWindow(config.WindowSize),
+		isSuccessful:         config.IsSuccessful,
+		onStateChange:        config.OnStateChange,
 	}
 }
 
 // Execute executes the given function if the circuit breaker allows it
 func (cb *CircuitBreaker) Execute(fn func() error) error {
-	if !cb.allowRequest() {
-		return errors.New("circuit breaker is open")
-	}
+	return cb.ExecuteContext(context.Background(), func(context.Context) error {
+		return fn()
+	})
+}
 
-	err := fn()
-	cb.recordResult(err == nil)
+// ExecuteContext executes the given function if the circuit breaker allows it.
+// Calls that fail because ctx was cancelled are not counted against the breaker.
+func (cb *CircuitBreaker) ExecuteContext(ctx context.Context, fn func(context.Context) error) error {
+	_, err := ExecuteValue(ctx, cb, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
 	return err
 }
 
-// allowRequest determines whether a request should be allowed
-func (cb *CircuitBreaker) allowRequest() bool {
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
+// ExecuteValue executes the given function through the circuit breaker and returns its value
+func ExecuteValue[T any](ctx context.Context, cb *CircuitBreaker, fn func(context.Context) (T, error)) (T, error) {
+	var zero T
+
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	generation, err := cb.beforeRequest()
+	if err != nil {
+		return zero, err
+	}
+
+	// Record the outcome even if fn panics, so a half-open trial slot is
+	// never leaked; a panic counts as a failure and is re-raised
+	completed := false
+	defer func() {
+		if !completed {
+			cb.afterRequest(generation, false)
+		}
+	}()
+
+	result, err := fn(ctx)
+	completed = true
+	if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		cb.releaseRequest(generation)
+		return result, err
+	}
+
+	cb.afterRequest(generation, cb.isSuccessful(err))
+	return result, err
+}
+
+// beforeRequest admits or rejects a request and returns the generation it
+// was admitted in, so that late results from a previous state are ignored
+func (cb *CircuitBreaker) beforeRequest() (uint64, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.updateState(time.Now())
 
 	switch cb.state {
-	case StateClosed:
-		return true
 	case StateOpen:
-		return time.Since(cb.lastFailTime) >= cb.resetTimeout
+		return cb.generation, ErrOpen
 	case StateHalfOpen:
-		return true
-	default:
-		return false
+		if cb.halfOpenInFlight >= cb.halfOpenMaxRequests {
+			return cb.generation, ErrTooManyRequests
+		}
+		cb.halfOpenInFlight++
 	}
+
+	return cb.generation, nil
 }
 
-// recordResult records the result of a request
-func (cb *CircuitBreaker) recordResult(success bool) {
+// afterRequest records the result of a request admitted in generation
+func (cb *CircuitBreaker) afterRequest(generation uint64, success bool) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
+	cb.updateState(time.Now())
+	if generation != cb.generation {
+		return
+	}
+
+	if cb.state == StateHalfOpen {
+		cb.halfOpenInFlight--
+	}
+
 	if success {
 		cb.onSuccess()
 	} else {
@@ -93,73 +205,117 @@ func (cb *CircuitBreaker) recordResult(success bool) {
 	}
 }
 
+// releaseRequest frees a half-open trial slot without recording an outcome
+func (cb *CircuitBreaker) releaseRequest(generation uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation == cb.generation && cb.state == StateHalfOpen {
+		cb.halfOpenInFlight--
+	}
+}
+
+// updateState moves an open breaker to half-open once the reset timeout
+// has elapsed. Callers must hold cb.mu for writing.
+func (cb *CircuitBreaker) updateState(now time.Time) {
+	if cb.state == StateOpen && now.Sub(cb.openedAt) >= cb.resetTimeout {
+		cb.setState(StateHalfOpen)
+	}
+}
+
 // onSuccess handles a successful request
 func (cb *CircuitBreaker) onSuccess() {
 	cb.failures = 0
 
 	switch cb.state {
 	case StateClosed:
-		// Stay closed
-	case StateOpen:
-		// Check if we should transition to half-open
-		if time.Since(cb.lastFailTime) >= cb.resetTimeout {
-			cb.setState(StateHalfOpen)
-		}
+		cb.window.record(true)
 	case StateHalfOpen:
-		cb.setState(StateClosed)
+		cb.halfOpenSuccesses++
+		if cb.halfOpenSuccesses >= cb.successThreshold {
+			cb.setState(StateClosed)
+		}
 	}
 }
 
 // onFailure handles a failed request
 func (cb *CircuitBreaker) onFailure() {
 	cb.failures++
-	cb.lastFailTime = time.Now()
 
 	switch cb.state {
 	case StateClosed:
-		if cb.failures >= cb.maxFailures {
+		cb.window.record(false)
+		if cb.failures >= cb.maxFailures || cb.failureRateExceeded() {
 			cb.setState(StateOpen)
 		}
 	case StateHalfOpen:
 		cb.setState(StateOpen)
-	case StateOpen:
-		// Stay open, already tracking failures
 	}
 }
 
-// setState changes the circuit breaker state
+// failureRateExceeded reports whether the sliding-window failure rate has
+// reached the configured threshold
+func (cb *CircuitBreaker) failureRateExceeded() bool {
+	if cb.failureRateThreshold <= 0 || cb.window.count < cb.minRequests {
+		return false
+	}
+	return cb.window.failureRate() >= cb.failureRateThreshold
+}
+
+// setState changes the circuit breaker state and starts a new generation
 func (cb *CircuitBreaker) setState(newState State) {
-	if cb.state != newState {
-		oldState := cb.state
-		cb.state = newState
+	if cb.state == newState {
+		return
+	}
 
-		if cb.onStateChange != nil {
-			go cb.onStateChange(cb.name, oldState, newState)
-		}
+	oldState := cb.state
+	cb.state = newState
+	cb.generation++
+	cb.halfOpenInFlight = 0
+	cb.halfOpenSuccesses = 0
+
+	switch newState {
+	case StateOpen:
+		cb.openedAt = time.Now()
+	case StateClosed:
+		cb.window.reset()
+	}
+
+	if cb.onStateChange != nil {
+		go cb.onStateChange(cb.name, oldState, newState)
 	}
 }
 
 // State returns the current circuit breaker state
 func (cb *CircuitBreaker) State() State {
-	cb.mu.RLock()
-	defer cb.mu.RUnlock()
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.updateState(time.Now())
 	return cb.state
 }
 
-// Failures returns the current failure count
+// Failures returns the current consecutive failure count
 func (cb *CircuitBreaker) Failures() int {
 	cb.mu.RLock()
 	defer cb.mu.RUnlock()
 	return cb.failures
 }
 
+// FailureRate returns the failure ratio over the sliding window
+func (cb *CircuitBreaker) FailureRate() float64 {
+	cb.mu.RLock()
+	defer cb.mu.RUnlock()
+	return cb.window.failureRate()
+}
+
 // Reset resets the circuit breaker to closed state
 func (cb *CircuitBreaker) Reset() {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
 	cb.failures = 0
-	cb.lastFailTime = time.Time{}
+	cb.window.reset()
 	cb.setState(StateClosed)
 }
 
@@ -195,4 +351,49 @@ func (s State) String() string {
 	default:
 		return "UNKNOWN"
 	}
-}
\ No newline at end of file
+}
+
+// outcomeWindow is a fixed-size ring buffer of recent call outcomes
+type outcomeWindow struct {
+	outcomes []bool
+	next     int
+	count    int
+	failures int
+}
+
+// newOutcomeWindow creates a window holding the last size outcomes
+func newOutcomeWindow(size int) *outcomeWindow {
+	return &outcomeWindow{outcomes: make([]bool, size)}
+}
+
+// record adds an outcome, evicting the oldest one when the window is full
+func (w *outcomeWindow) record(success bool) {
+	if w.count == len(w.outcomes) {
+		if !w.outcomes[w.next] {
+			w.failures--
+		}
+	} else {
+		w.count++
+	}
+
+	w.outcomes[w.next] = success
+	if !success {
+		w.failures++
+	}
+	w.next = (w.next + 1) % len(w.outcomes)
+}
+
+// failureRate returns the ratio of failures to recorded outcomes
+func (w *outcomeWindow) failureRate() float64 {
+	if w.count == 0 {
+		return 0
+	}
+	return float64(w.failures) / float64(w.count)
+}
+
+// reset clears all recorded outcomes
+func (w *outcomeWindow) reset() {
+	w.next = 0
+	w.count = 0
+	w.failures = 0
+}
@@ -1,6 +1,7 @@
 package reliability
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"testing"
@@ -351,4 +352,355 @@ func TestState_String(t *testing.T) {
 			t.Errorf("Expected %s, got %s", expected, state.String())
 		}
 	}
-}
\ No newline at end of file
+}
+func TestCircuitBreaker_HalfOpenTransitionOnTimeout(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:         "test-cb",
+		MaxFailures:  1,
+		ResetTimeout: 50 * time.Millisecond,
+	})
+
+	cb.Execute(func() error {
+		return errors.New("test error")
+	})
+
+	if !cb.IsOpen() {
+		t.Fatal("Expected circuit breaker to be OPEN")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	// The transition happens on timeout, without any request being made
+	if !cb.IsHalfOpen() {
+		t.Errorf("Expected circuit breaker to be HALF_OPEN after reset timeout, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpenMaxRequests(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:                "test-cb",
+		MaxFailures:         1,
+		ResetTimeout:        50 * time.Millisecond,
+		HalfOpenMaxRequests: 2,
+	})
+
+	cb.Execute(func() error {
+		return errors.New("test error")
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	var wg sync.WaitGroup
+
+	// Occupy both trial slots
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cb.Execute(func() error {
+				started <- struct{}{}
+				<-release
+				return nil
+			})
+		}()
+	}
+	<-started
+	<-started
+
+	executed := false
+	err := cb.Execute(func() error {
+		executed = true
+		return nil
+	})
+
+	if err == nil {
+		t.Error("Expected third concurrent trial request to be rejected")
+	}
+
+	if executed {
+		t.Error("Expected rejected trial request not to be executed")
+	}
+
+	close(release)
+	wg.Wait()
+
+	if !cb.IsClosed() {
+		t.Errorf("Expected circuit breaker to be CLOSED after successful trials, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_HalfOpenPanic(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:         "test-cb",
+		MaxFailures:  1,
+		ResetTimeout: 50 * time.Millisecond,
+	})
+
+	cb.Execute(func() error {
+		return errors.New("test error")
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "trial panic" {
+				t.Errorf("Expected the panic to be re-raised, got %v", r)
+			}
+		}()
+		cb.Execute(func() error {
+			panic("trial panic")
+		})
+	}()
+
+	// The panicking trial counts as a failure and frees its slot
+	if !cb.IsOpen() {
+		t.Fatalf("Expected circuit breaker to be OPEN after a panicking trial, got %s", cb.State())
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if err := cb.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("Expected the next trial request to be allowed, got %v", err)
+	}
+	if !cb.IsClosed() {
+		t.Errorf("Expected circuit breaker to be CLOSED, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_SuccessThreshold(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:             "test-cb",
+		MaxFailures:      1,
+		ResetTimeout:     50 * time.Millisecond,
+		SuccessThreshold: 3,
+	})
+
+	cb.Execute(func() error {
+		return errors.New("test error")
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		if err := cb.Execute(func() error { return nil }); err != nil {
+			t.Fatalf("Expected trial request %d to be allowed, got %v", i+1, err)
+		}
+		if !cb.IsHalfOpen() {
+			t.Fatalf("Expected circuit breaker to stay HALF_OPEN after %d successes, got %s", i+1, cb.State())
+		}
+	}
+
+	if err := cb.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("Expected trial request to be allowed, got %v", err)
+	}
+
+	if !cb.IsClosed() {
+		t.Errorf("Expected circuit breaker to be CLOSED after 3 successes, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_FailureRateThreshold(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:                 "test-cb",
+		MaxFailures:          100,
+		ResetTimeout:         30 * time.Second,
+		FailureRateThreshold: 0.5,
+		WindowSize:           10,
+		MinRequests:          4,
+	})
+
+	// Alternating results never produce consecutive failures
+	results := []bool{true, false, true, false}
+	for _, ok := range results {
+		cb.Execute(func() error {
+			if ok {
+				return nil
+			}
+			return errors.New("test error")
+		})
+	}
+
+	if !cb.IsOpen() {
+		t.Errorf("Expected circuit breaker to be OPEN at 50%% failure rate, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_FailureRateMinRequests(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:                 "test-cb",
+		MaxFailures:          100,
+		ResetTimeout:         30 * time.Second,
+		FailureRateThreshold: 0.5,
+		WindowSize:           10,
+		MinRequests:          5,
+	})
+
+	for i := 0; i < 2; i++ {
+		cb.Execute(func() error {
+			return errors.New("test error")
+		})
+	}
+
+	if !cb.IsClosed() {
+		t.Errorf("Expected circuit breaker to stay CLOSED below minimum requests, got %s", cb.State())
+	}
+
+	if cb.FailureRate() != 1 {
+		t.Errorf("Expected failure rate 1, got %v", cb.FailureRate())
+	}
+}
+
+func TestCircuitBreaker_SentinelErrors(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:         "test-cb",
+		MaxFailures:  1,
+		ResetTimeout: 50 * time.Millisecond,
+	})
+
+	cb.Execute(func() error {
+		return errors.New("test error")
+	})
+
+	err := cb.Execute(func() error { return nil })
+	if !errors.Is(err, ErrOpen) {
+		t.Errorf("Expected ErrOpen, got %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cb.Execute(func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	err = cb.Execute(func() error { return nil })
+	if !errors.Is(err, ErrTooManyRequests) {
+		t.Errorf("Expected ErrTooManyRequests, got %v", err)
+	}
+
+	close(release)
+	<-done
+}
+
+func TestCircuitBreaker_IsSuccessful(t *testing.T) {
+	errExpected := errors.New("expected condition")
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:         "test-cb",
+		MaxFailures:  2,
+		ResetTimeout: 30 * time.Second,
+		IsSuccessful: func(err error) bool {
+			return err == nil || errors.Is(err, errExpected)
+		},
+	})
+
+	for i := 0; i < 5; i++ {
+		err := cb.Execute(func() error {
+			return errExpected
+		})
+		if !errors.Is(err, errExpected) {
+			t.Errorf("Expected the function error to be returned, got %v", err)
+		}
+	}
+
+	if !cb.IsClosed() {
+		t.Errorf("Expected circuit breaker to stay CLOSED, got %s", cb.State())
+	}
+
+	if cb.Failures() != 0 {
+		t.Errorf("Expected no failures, got %d", cb.Failures())
+	}
+}
+
+func TestCircuitBreaker_ExecuteContext(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:         "test-cb",
+		MaxFailures:  1,
+		ResetTimeout: 30 * time.Second,
+	})
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	err := cb.ExecuteContext(ctx, func(ctx context.Context) error {
+		if ctx.Value(ctxKey{}) != "value" {
+			t.Error("Expected context to be passed to the function")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	// A cancelled context must not trip the breaker
+	ctx, cancel := context.WithCancel(context.Background())
+	err = cb.ExecuteContext(ctx, func(ctx context.Context) error {
+		cancel()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	if !cb.IsClosed() {
+		t.Errorf("Expected circuit breaker to stay CLOSED after cancellation, got %s", cb.State())
+	}
+
+	// An already cancelled context is rejected without calling the function
+	called := false
+	err = cb.ExecuteContext(ctx, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if called {
+		t.Error("Expected function not to be called with a cancelled context")
+	}
+}
+
+func TestExecuteValue(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Name:         "test-cb",
+		MaxFailures:  1,
+		ResetTimeout: 30 * time.Second,
+	})
+
+	result, err := ExecuteValue(context.Background(), cb, func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if result != 42 {
+		t.Errorf("Expected 42, got %d", result)
+	}
+
+	_, err = ExecuteValue(context.Background(), cb, func(ctx context.Context) (int, error) {
+		return 0, errors.New("test error")
+	})
+	if err == nil {
+		t.Error("Expected error")
+	}
+
+	result, err = ExecuteValue(context.Background(), cb, func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	if !errors.Is(err, ErrOpen) {
+		t.Errorf("Expected ErrOpen, got %v", err)
+	}
+	if result != 0 {
+		t.Errorf("Expected zero value when rejected, got %d", result)
+	}
+}
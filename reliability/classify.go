@@ -0,0 +1,203 @@
+package reliability
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+// ErrorClass describes whether an error is worth retrying
+type ErrorClass int
+
+const (
+	ErrorClassUnknown ErrorClass = iota
+	ErrorClassTransient
+	ErrorClassPermanent
+)
+
+// String returns the string representation of ErrorClass
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorClassTransient:
+		return "transient"
+	case ErrorClassPermanent:
+		return "permanent"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifierFunc classifies an error, returning ErrorClassUnknown when it has no opinion
+type ClassifierFunc func(err error) ErrorClass
+
+// ErrorClassifier classifies errors by inspecting their chain with errors.Is
+// and errors.As. Classifiers registered later take precedence over earlier
+// ones, and all of them take precedence over the built-in rules.
+type ErrorClassifier struct {
+	mu          sync.RWMutex
+	classifiers []ClassifierFunc
+}
+
+// NewErrorClassifier creates a classifier that only applies the built-in rules
+func NewErrorClassifier() *ErrorClassifier {
+	return &ErrorClassifier{}
+}
+
+// Register adds a classifier function
+func (c *ErrorClassifier) Register(fn ClassifierFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.classifiers = append(c.classifiers, fn)
+}
+
+// RegisterTransient marks errors matching any of targets (via errors.Is) as transient
+func (c *ErrorClassifier) RegisterTransient(targets ...error) {
+	c.Register(matchErrors(ErrorClassTransient, targets))
+}
+
+// RegisterPermanent marks errors matching any of targets (via errors.Is) as permanent
+func (c *ErrorClassifier) RegisterPermanent(targets ...error) {
+	c.Register(matchErrors(ErrorClassPermanent, targets))
+}
+
+// Classify returns the class of err
+func (c *ErrorClassifier) Classify(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassUnknown
+	}
+
+	c.mu.RLock()
+	classifiers := c.classifiers
+	c.mu.RUnlock()
+
+	for i := len(classifiers) - 1; i >= 0; i-- {
+		if class := classifiers[i](err); class != ErrorClassUnknown {
+			return class
+		}
+	}
+
+	return classifyBuiltin(err)
+}
+
+// IsTransient returns true if err is classified as transient
+func (c *ErrorClassifier) IsTransient(err error) bool {
+	return c.Classify(err) == ErrorClassTransient
+}
+
+// TransientType returns a classifier that marks errors of type T (via errors.As) as transient
+func TransientType[T error]() ClassifierFunc {
+	return func(err error) ErrorClass {
+		var target T
+		if errors.As(err, &target) {
+			return ErrorClassTransient
+		}
+		return ErrorClassUnknown
+	}
+}
+
+// matchErrors returns a classifier that assigns class to errors matching any of targets
+func matchErrors(class ErrorClass, targets []error) ClassifierFunc {
+	return func(err error) ErrorClass {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return class
+			}
+		}
+		return ErrorClassUnknown
+	}
+}
+
+// transientErrnos lists system call errors that usually clear up on their own,
+// including the ones a hung or recovering network filesystem produces
+var transientErrnos = map[syscall.Errno]bool{
+	syscall.EAGAIN:       true,
+	syscall.EINTR:        true,
+	syscall.EBUSY:        true,
+	syscall.EIO:          true,
+	syscall.ESTALE:       true,
+	syscall.ETIMEDOUT:    true,
+	syscall.ECONNREFUSED: true,
+	syscall.ECONNRESET:   true,
+	syscall.ECONNABORTED: true,
+	syscall.ENETDOWN:     true,
+	syscall.ENETUNREACH:  true,
+	syscall.EHOSTDOWN:    true,
+	syscall.EHOSTUNREACH: true,
+	syscall.ENOBUFS:      true,
+	syscall.ENOMEM:       true,
+}
+
+// classifyBuiltin applies the built-in classification rules
+func classifyBuiltin(err error) ErrorClass {
+	// The caller gave up; retrying cannot help
+	if errors.Is(err, context.Canceled) {
+		return ErrorClassPermanent
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassTransient
+	}
+
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrorClassPermanent
+	}
+
+	for _, target := range DefaultRetryableErrors() {
+		if errors.Is(err, target) {
+			return ErrorClassTransient
+		}
+	}
+
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		if transientErrnos[errno] {
+			return ErrorClassTransient
+		}
+		return ErrorClassPermanent
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorClassTransient
+	}
+
+	// A command killed by a signal (e.g. after hanging on a stale mount) may
+	// succeed next time; a command that exited on its own gave its answer
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ProcessState != nil && exitErr.ProcessState.ExitCode() == -1 {
+		return ErrorClassTransient
+	}
+
+	return ErrorClassUnknown
+}
+
+// defaultClassifier is the process-wide registry used by IsTransientError
+var defaultClassifier = NewErrorClassifier()
+
+// RegisterClassifier adds a classifier function to the default registry
+func RegisterClassifier(fn ClassifierFunc) {
+	defaultClassifier.Register(fn)
+}
+
+// RegisterTransientError marks errors matching any of targets as transient in the default registry
+func RegisterTransientError(targets ...error) {
+	defaultClassifier.RegisterTransient(targets...)
+}
+
+// RegisterPermanentError marks errors matching any of targets as permanent in the default registry
+func RegisterPermanentError(targets ...error) {
+	defaultClassifier.RegisterPermanent(targets...)
+}
+
+// Classify returns the class of err according to the default registry
+func Classify(err error) ErrorClass {
+	return defaultClassifier.Classify(err)
+}
+
+// IsTransientError checks if an error is transient (retryable) according to the default registry
+func IsTransientError(err error) bool {
+	return defaultClassifier.IsTransient(err)
+}
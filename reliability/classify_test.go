@@ -0,0 +1,106 @@
+package reliability
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+type testTransientError struct{}
+
+func (e *testTransientError) Error() string {
+	return "test transient error"
+}
+
+func TestErrorClassifier_Builtin(t *testing.T) {
+	classifier := NewErrorClassifier()
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"nil", nil, ErrorClassUnknown},
+		{"plain error", errors.New("something"), ErrorClassUnknown},
+		{"transient errno", syscall.EIO, ErrorClassTransient},
+		{"permanent errno", syscall.ENOENT, ErrorClassPermanent},
+		{"not found", exec.ErrNotFound, ErrorClassPermanent},
+		{"exit error", &exec.ExitError{}, ErrorClassUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifier.Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorClassifier_SignaledProcess(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "kill -KILL $$")
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Skipf("Expected *exec.ExitError, got %v", err)
+	}
+
+	if got := NewErrorClassifier().Classify(fmt.Errorf("findmnt command failed: %w", err)); got != ErrorClassTransient {
+		t.Errorf("Expected signaled process to be transient, got %s", got)
+	}
+}
+
+func TestErrorClassifier_Register(t *testing.T) {
+	errCustom := errors.New("custom")
+	classifier := NewErrorClassifier()
+
+	if classifier.IsTransient(errCustom) {
+		t.Fatal("Expected unregistered error not to be transient")
+	}
+
+	classifier.RegisterTransient(errCustom)
+	if !classifier.IsTransient(fmt.Errorf("wrapped: %w", errCustom)) {
+		t.Error("Expected registered error to be transient through wrapping")
+	}
+
+	// Later registrations take precedence, including over built-in rules
+	classifier.RegisterPermanent(errCustom, syscall.EIO)
+	if classifier.Classify(errCustom) != ErrorClassPermanent {
+		t.Error("Expected later registration to override earlier one")
+	}
+	if classifier.Classify(syscall.EIO) != ErrorClassPermanent {
+		t.Error("Expected registration to override built-in rule")
+	}
+}
+
+func TestErrorClassifier_TransientType(t *testing.T) {
+	classifier := NewErrorClassifier()
+	classifier.Register(TransientType[*testTransientError]())
+
+	err := fmt.Errorf("operation failed: %w", &testTransientError{})
+	if !classifier.IsTransient(err) {
+		t.Error("Expected registered error type to be transient")
+	}
+
+	if classifier.IsTransient(errors.New("test transient error")) {
+		t.Error("Expected error with the same message but a different type not to be transient")
+	}
+}
+
+func TestErrorClass_String(t *testing.T) {
+	tests := map[ErrorClass]string{
+		ErrorClassUnknown:   "unknown",
+		ErrorClassTransient: "transient",
+		ErrorClassPermanent: "permanent",
+		ErrorClass(999):     "unknown",
+	}
+
+	for class, expected := range tests {
+		if class.String() != expected {
+			t.Errorf("Expected %s, got %s", expected, class.String())
+		}
+	}
+}
@@ -2,6 +2,7 @@ package reliability
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -17,6 +18,22 @@ const (
 	BackoffStrategyFixed
 )
 
+// JitterStrategy defines how randomness is applied to backoff delays
+type JitterStrategy int
+
+const (
+	// JitterStrategyProportional adds up to 10% on top of the backoff delay
+	JitterStrategyProportional JitterStrategy = iota
+	// JitterStrategyNone uses the backoff delay unchanged
+	JitterStrategyNone
+	// JitterStrategyFull picks a random delay in [0, backoff)
+	JitterStrategyFull
+	// JitterStrategyEqual keeps half of the backoff and randomizes the other half
+	JitterStrategyEqual
+	// JitterStrategyDecorrelated picks a random delay in [InitialDelay, 3*previous delay)
+	JitterStrategyDecorrelated
+)
+
 // RetryConfig holds configuration for retry logic
 type RetryConfig struct {
 	MaxAttempts     int
@@ -24,6 +41,9 @@ type RetryConfig struct {
 	MaxDelay        time.Duration
 	Multiplier      float64
 	Strategy        BackoffStrategy
+	Jitter          JitterStrategy
+	Budget          *RetryBudget
+	OnRetry         func(attempt int, err error, delay time.Duration)
 	RetryableErrors []error
 	ShouldRetry     func(error) bool
 }
@@ -44,6 +64,7 @@ func NewRetry(opts ...RetryOption) *Retry {
 		MaxDelay:     30 * time.Second,
 		Multiplier:   2.0,
 		Strategy:     BackoffStrategyExponential,
+		Jitter:       JitterStrategyProportional,
 		ShouldRetry: func(err error) bool {
 			return err != nil
 		},
@@ -91,6 +112,28 @@ func WithBackoffStrategy(strategy BackoffStrategy) RetryOption {
 	}
 }
 
+// WithJitterStrategy sets the jitter strategy applied to backoff delays
+func WithJitterStrategy(jitter JitterStrategy) RetryOption {
+	return func(c *RetryConfig) {
+		c.Jitter = jitter
+	}
+}
+
+// WithRetryBudget limits retries with a budget that may be shared by several Retry instances
+func WithRetryBudget(budget *RetryBudget) RetryOption {
+	return func(c *RetryConfig) {
+		c.Budget = budget
+	}
+}
+
+// WithOnRetry sets a callback invoked before each retry with the attempt number
+// about to run (starting at 1), the error that caused it and the chosen delay
+func WithOnRetry(onRetry func(attempt int, err error, delay time.Duration)) RetryOption {
+	return func(c *RetryConfig) {
+		c.OnRetry = onRetry
+	}
+}
+
 // WithRetryableErrors sets specific errors that should be retried
 func WithRetryableErrors(errors ...error) RetryOption {
 	return func(c *RetryConfig) {
@@ -115,63 +158,41 @@ func WithShouldRetry(shouldRetry func(error) bool) RetryOption {
 
 // Do executes the given function with retry logic
 func (r *Retry) Do(ctx context.Context, fn func() error) error {
-	var lastErr error
-
-	for attempt := 0; attempt < r.config.MaxAttempts; attempt++ {
-		if attempt > 0 {
-			delay := r.calculateDelay(attempt)
-
-			// Add jitter to prevent thundering herd
-			jitter := time.Duration(rand.Float64() * float64(delay) * 0.1) // 10% jitter
-			delay += jitter
-
-			select {
-			case <-time.After(delay):
-				// Continue with retry
-			case <-ctx.Done():
-				return fmt.Errorf("retry cancelled: %w", ctx.Err())
-			}
-		}
-
-		err := fn()
-		if err == nil {
-			return nil
-		}
-
-		lastErr = err
-
-		// Check if we should retry this error
-		if !r.config.ShouldRetry(err) {
-			break
-		}
-
-		// If this is the last attempt, don't wait
-		if attempt == r.config.MaxAttempts-1 {
-			break
-		}
-	}
-
-	return fmt.Errorf("max retry attempts (%d) exceeded, last error: %w", r.config.MaxAttempts, lastErr)
+	_, err := DoWithValue(ctx, r, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
 }
 
 // DoWithValue executes the given function with retry logic and returns a value
-func (r *Retry) DoWithValue[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+func DoWithValue[T any](ctx context.Context, r *Retry, fn func() (T, error)) (T, error) {
 	var result T
 	var lastErr error
+	var delay time.Duration
+
+	if r.config.Budget != nil {
+		r.config.Budget.recordRequest()
+	}
 
 	for attempt := 0; attempt < r.config.MaxAttempts; attempt++ {
 		if attempt > 0 {
-			delay := r.calculateDelay(attempt)
+			if r.config.Budget != nil && !r.config.Budget.withdraw() {
+				return result, fmt.Errorf("%w, last error: %w", ErrRetryBudgetExhausted, lastErr)
+			}
 
-			// Add jitter to prevent thundering herd
-			jitter := time.Duration(rand.Float64() * float64(delay) * 0.1) // 10% jitter
-			delay += jitter
+			delay = r.nextDelay(attempt, delay, lastErr)
 
+			if r.config.OnRetry != nil {
+				r.config.OnRetry(attempt, lastErr, delay)
+			}
+
+			timer := time.NewTimer(delay)
 			select {
-			case <-time.After(delay):
+			case <-timer.C:
 				// Continue with retry
 			case <-ctx.Done():
-				return result, fmt.Errorf("retry cancelled: %w", ctx.Err())
+				timer.Stop()
+				return result, fmt.Errorf("retry cancelled: %w, last error: %w", ctx.Err(), lastErr)
 			}
 		}
 
@@ -197,6 +218,42 @@ func (r *Retry) DoWithValue[T any](ctx context.Context, fn func() (T, error)) (T
 	return result, fmt.Errorf("max retry attempts (%d) exceeded, last error: %w", r.config.MaxAttempts, lastErr)
 }
 
+// nextDelay returns the delay before the given attempt. A delay requested by
+// the error takes precedence over the backoff and jitter strategies.
+func (r *Retry) nextDelay(attempt int, previous time.Duration, err error) time.Duration {
+	if hint, ok := RetryAfterHint(err); ok {
+		return min(hint, r.config.MaxDelay)
+	}
+
+	delay := r.calculateDelay(attempt)
+
+	switch r.config.Jitter {
+	case JitterStrategyNone:
+	case JitterStrategyFull:
+		delay = randomDuration(0, delay)
+	case JitterStrategyEqual:
+		delay = delay/2 + randomDuration(0, delay-delay/2)
+	case JitterStrategyDecorrelated:
+		if previous < r.config.InitialDelay {
+			previous = r.config.InitialDelay
+		}
+		delay = randomDuration(r.config.InitialDelay, previous*3)
+	default:
+		// Add jitter to prevent thundering herd
+		delay += time.Duration(rand.Float64() * float64(delay) * 0.1) // 10% jitter
+	}
+
+	return min(delay, r.config.MaxDelay)
+}
+
+// randomDuration returns a random duration in [lo, hi)
+func randomDuration(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + time.Duration(rand.Int63n(int64(hi-lo)))
+}
+
 // calculateDelay calculates the delay for a given attempt based on the strategy
 func (r *Retry) calculateDelay(attempt int) time.Duration {
 	var delay time.Duration
@@ -230,12 +287,54 @@ func (r *Retry) IsRetryableError(err error) bool {
 	return r.config.ShouldRetry(err)
 }
 
+// RetryAfterError is implemented by errors that carry a preferred delay
+// before the operation is retried, such as a server's Retry-After hint
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+// retryAfterError wraps an error with a preferred retry delay
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+func (e *retryAfterError) RetryAfter() time.Duration {
+	return e.delay
+}
+
+// WithRetryAfter annotates err with the delay the retry loop should wait before the next attempt
+func WithRetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &retryAfterError{err: err, delay: delay}
+}
+
+// RetryAfterHint returns the preferred retry delay carried by err, if any
+func RetryAfterHint(err error) (time.Duration, bool) {
+	var hinted RetryAfterError
+	if errors.As(err, &hinted) && hinted.RetryAfter() > 0 {
+		return hinted.RetryAfter(), true
+	}
+	return 0, false
+}
+
 // Common retryable errors
 var (
-	ErrTimeout          = fmt.Errorf("timeout")
-	ErrConnectionRefused = fmt.Errorf("connection refused")
-	ErrTemporaryFailure  = fmt.Errorf("temporary failure")
-	ErrRateLimited      = fmt.Errorf("rate limited")
+	ErrTimeout            = fmt.Errorf("timeout")
+	ErrConnectionRefused  = fmt.Errorf("connection refused")
+	ErrTemporaryFailure   = fmt.Errorf("temporary failure")
+	ErrRateLimited        = fmt.Errorf("rate limited")
 	ErrServiceUnavailable = fmt.Errorf("service unavailable")
 )
 
@@ -249,64 +348,3 @@ func DefaultRetryableErrors() []error {
 		ErrServiceUnavailable,
 	}
 }
-
-// IsTransientError checks if an error is likely transient (retryable)
-func IsTransientError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	errStr := err.Error()
-
-	// Common patterns for transient errors
-	transientPatterns := []string{
-		"timeout",
-		"connection refused",
-		"temporary failure",
-		"rate limited",
-		"service unavailable",
-		"network is unreachable",
-		"no route to host",
-		"connection reset",
-		"connection timed out",
-		"deadline exceeded",
-		"resource temporarily unavailable",
-	}
-
-	for _, pattern := range transientPatterns {
-		if contains(errStr, pattern) {
-			return true
-		}
-	}
-
-	return false
-}
-
-// contains checks if a string contains a substring (case-insensitive)
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
-		(len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
-		func() bool {
-			sLower := toLower(s)
-			substrLower := toLower(substr)
-			for i := 1; i <= len(sLower)-len(substrLower); i++ {
-				if sLower[i:i+len(substrLower)] == substrLower {
-					return true
-				}
-			}
-			return false
-		}())))
-}
-
-// toLower converts a string to lowercase
-func toLower(s string) string {
-	result := make([]rune, len([]rune(s)))
-	for i, r := range []rune(s) {
-		if r >= 'A' && r <= 'Z' {
-			result[i] = r + ('a' - 'A')
-		} else {
-			result[i] = r
-		}
-	}
-	return string(result)
-}
\ No newline at end of file
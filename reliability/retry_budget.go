@@ -0,0 +1,71 @@
+package reliability
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrRetryBudgetExhausted is returned when a retry is skipped because the retry budget is empty
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// RetryBudget is a token bucket that caps the ratio of retries to requests.
+// Every request deposits Ratio tokens and every retry withdraws one, so a
+// budget shared by several callers stops them from retrying in lockstep
+// when a dependency fails for everyone at once.
+type RetryBudget struct {
+	mu        sync.Mutex
+	ratio     float64
+	maxTokens float64
+	tokens    float64
+}
+
+// RetryBudgetConfig holds configuration for a retry budget
+type RetryBudgetConfig struct {
+	// Ratio is the number of retries allowed per request (default 0.1)
+	Ratio float64
+	// MaxTokens bounds the number of retries that can be saved up (default 10)
+	MaxTokens float64
+}
+
+// NewRetryBudget creates a new retry budget, initially full
+func NewRetryBudget(config RetryBudgetConfig) *RetryBudget {
+	if config.Ratio <= 0 {
+		config.Ratio = 0.1
+	}
+	if config.MaxTokens <= 0 {
+		config.MaxTokens = 10
+	}
+
+	return &RetryBudget{
+		ratio:     config.Ratio,
+		maxTokens: config.MaxTokens,
+		tokens:    config.MaxTokens,
+	}
+}
+
+// recordRequest deposits tokens for a new request
+func (b *RetryBudget) recordRequest() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.tokens+b.ratio, b.maxTokens)
+}
+
+// withdraw takes a token for a retry, returning false if none is available
+func (b *RetryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Tokens returns the number of retries currently available
+func (b *RetryBudget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
@@ -0,0 +1,47 @@
+package reliability
+
+import "testing"
+
+func TestRetryBudget_Defaults(t *testing.T) {
+	budget := NewRetryBudget(RetryBudgetConfig{})
+
+	if budget.Tokens() != 10 {
+		t.Errorf("Expected budget to start full with 10 tokens, got %v", budget.Tokens())
+	}
+}
+
+func TestRetryBudget_DepositAndWithdraw(t *testing.T) {
+	budget := NewRetryBudget(RetryBudgetConfig{Ratio: 0.25, MaxTokens: 1})
+
+	if !budget.withdraw() {
+		t.Fatal("Expected withdraw from a full budget to succeed")
+	}
+
+	if budget.withdraw() {
+		t.Fatal("Expected withdraw from an empty budget to fail")
+	}
+
+	for i := 0; i < 3; i++ {
+		budget.recordRequest()
+	}
+	if budget.withdraw() {
+		t.Fatal("Expected withdraw to fail with 0.75 tokens")
+	}
+
+	budget.recordRequest()
+	if !budget.withdraw() {
+		t.Fatal("Expected withdraw to succeed after four requests at ratio 0.25")
+	}
+}
+
+func TestRetryBudget_MaxTokens(t *testing.T) {
+	budget := NewRetryBudget(RetryBudgetConfig{Ratio: 1, MaxTokens: 2})
+
+	for i := 0; i < 10; i++ {
+		budget.recordRequest()
+	}
+
+	if budget.Tokens() != 2 {
+		t.Errorf("Expected tokens to be capped at 2, got %v", budget.Tokens())
+	}
+}
@@ -3,6 +3,11 @@ package reliability
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -119,7 +124,7 @@ func TestRetry_MaxAttemptsExceeded(t *testing.T) {
 		t.Error("Expected error after max attempts")
 	}
 
-	if !contains(err.Error(), "max retry attempts") {
+	if !strings.Contains(err.Error(), "max retry attempts") {
 		t.Errorf("Expected max retry attempts error, got %s", err.Error())
 	}
 
@@ -146,6 +151,10 @@ func TestRetry_NonRetryableError(t *testing.T) {
 		t.Error("Expected error")
 	}
 
+	if err == nil {
+		t.Error("Expected error for non-retryable error")
+	}
+
 	if calls != 1 {
 		t.Errorf("Expected 1 call for non-retryable error, got %d", calls)
 	}
@@ -172,7 +181,7 @@ func TestRetry_ContextCancellation(t *testing.T) {
 		t.Error("Expected context cancellation error")
 	}
 
-	if !contains(err.Error(), "retry cancelled") {
+	if !strings.Contains(err.Error(), "retry cancelled") {
 		t.Errorf("Expected retry cancelled error, got %s", err.Error())
 	}
 
@@ -190,7 +199,7 @@ func TestRetry_DoWithValue(t *testing.T) {
 	)
 	calls := 0
 
-	result, err := retry.DoWithValue(context.Background(), func() (string, error) {
+	result, err := DoWithValue(context.Background(), retry, func() (string, error) {
 		calls++
 		if calls < 3 {
 			return "", errors.New("temporary failure")
@@ -335,6 +344,10 @@ func TestRetry_WithRetryableErrors(t *testing.T) {
 		return retryableErr
 	})
 
+	if err == nil {
+		t.Error("Expected error after exhausting retries")
+	}
+
 	if calls != 3 {
 		t.Errorf("Expected 3 calls for retryable error, got %d", calls)
 	}
@@ -357,16 +370,23 @@ func TestIsTransientError(t *testing.T) {
 		err  error
 		want bool
 	}{
-		{"timeout error", errors.New("operation timed out"), true},
-		{"connection refused", errors.New("connection refused"), true},
-		{"temporary failure", errors.New("temporary failure"), true},
-		{"rate limited", errors.New("rate limited"), true},
-		{"service unavailable", errors.New("service unavailable"), true},
-		{"network error", errors.New("network is unreachable"), true},
-		{"permanent error", errors.New("permanent failure"), false},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"wrapped deadline exceeded", fmt.Errorf("findmnt command timed out: %w", context.DeadlineExceeded), true},
+		{"context canceled", context.Canceled, false},
+		{"connection refused", ErrConnectionRefused, true},
+		{"temporary failure", ErrTemporaryFailure, true},
+		{"rate limited", ErrRateLimited, true},
+		{"service unavailable", ErrServiceUnavailable, true},
+		{"network error", syscall.ENETUNREACH, true},
+		{"stale file handle", &os.PathError{Op: "stat", Path: "/mnt/nfs", Err: syscall.ESTALE}, true},
+		{"io error", fmt.Errorf("read failed: %w", syscall.EIO), true},
+		{"try again", syscall.EAGAIN, true},
+		{"errno timeout", syscall.ETIMEDOUT, true},
+		{"permission denied", syscall.EACCES, false},
+		{"command not found", &exec.Error{Name: "findmnt", Err: exec.ErrNotFound}, false},
 		{"nil error", nil, false},
 		{"validation error", errors.New("invalid input"), false},
-		{"mixed case", errors.New("CONNECTION REFUSED"), true},
+		{"timeout in path", fmt.Errorf("invalid mount point /mnt/timeout"), false},
 	}
 
 	for _, tt := range tests {
@@ -385,7 +405,7 @@ func TestRetry_IsRetryableError(t *testing.T) {
 		}),
 	)
 
-	transientErr := errors.New("operation timed out")
+	transientErr := fmt.Errorf("operation timed out: %w", syscall.ETIMEDOUT)
 	permanentErr := errors.New("invalid input")
 
 	if !retry.IsRetryableError(transientErr) {
@@ -427,16 +447,166 @@ func TestDefaultRetryableErrors(t *testing.T) {
 	}
 }
 
-// Helper function to check if a string contains a substring
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
-		(len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
-		func() bool {
-			for i := 1; i <= len(s)-len(substr); i++ {
-				if s[i:i+len(substr)] == substr {
-					return true
+func TestRetry_JitterStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		jitter   JitterStrategy
+		min, max time.Duration
+	}{
+		{"none", JitterStrategyNone, 400 * time.Millisecond, 400 * time.Millisecond},
+		{"proportional", JitterStrategyProportional, 400 * time.Millisecond, 440 * time.Millisecond},
+		{"full", JitterStrategyFull, 0, 400 * time.Millisecond},
+		{"equal", JitterStrategyEqual, 200 * time.Millisecond, 400 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry := NewRetry(
+				WithInitialDelay(100*time.Millisecond),
+				WithMultiplier(2),
+				WithBackoffStrategy(BackoffStrategyExponential),
+				WithJitterStrategy(tt.jitter),
+			)
+
+			for i := 0; i < 100; i++ {
+				delay := retry.nextDelay(3, 0, errors.New("test error"))
+				if delay < tt.min || delay > tt.max {
+					t.Fatalf("Expected delay in [%v, %v], got %v", tt.min, tt.max, delay)
 				}
 			}
-			return false
-		}())))
-}
\ No newline at end of file
+		})
+	}
+}
+
+func TestRetry_DecorrelatedJitter(t *testing.T) {
+	retry := NewRetry(
+		WithInitialDelay(10*time.Millisecond),
+		WithMaxDelay(1*time.Second),
+		WithJitterStrategy(JitterStrategyDecorrelated),
+	)
+
+	previous := time.Duration(0)
+	for attempt := 1; attempt < 20; attempt++ {
+		delay := retry.nextDelay(attempt, previous, errors.New("test error"))
+
+		upper := max(previous, 10*time.Millisecond) * 3
+		if delay < 10*time.Millisecond || delay > min(upper, time.Second) {
+			t.Fatalf("Attempt %d: expected delay in [10ms, %v], got %v", attempt, min(upper, time.Second), delay)
+		}
+		previous = delay
+	}
+}
+
+func TestRetry_RetryAfterHint(t *testing.T) {
+	var delays []time.Duration
+	retry := NewRetry(
+		WithMaxAttempts(2),
+		WithInitialDelay(1*time.Second),
+		WithMaxDelay(5*time.Second),
+		WithOnRetry(func(attempt int, err error, delay time.Duration) {
+			delays = append(delays, delay)
+		}),
+	)
+
+	calls := 0
+	err := retry.Do(context.Background(), func() error {
+		calls++
+		return WithRetryAfter(errors.New("busy"), 5*time.Millisecond)
+	})
+
+	if err == nil {
+		t.Error("Expected error after exhausting retries")
+	}
+
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+
+	if len(delays) != 1 || delays[0] != 5*time.Millisecond {
+		t.Errorf("Expected the hinted 5ms delay to be used, got %v", delays)
+	}
+
+	if hint, ok := RetryAfterHint(fmt.Errorf("wrapped: %w", WithRetryAfter(errors.New("busy"), time.Minute))); !ok || hint != time.Minute {
+		t.Errorf("Expected hint to be found through wrapping, got %v, %v", hint, ok)
+	}
+
+	if _, ok := RetryAfterHint(errors.New("plain")); ok {
+		t.Error("Expected no hint on a plain error")
+	}
+
+	if WithRetryAfter(nil, time.Second) != nil {
+		t.Error("Expected WithRetryAfter(nil) to return nil")
+	}
+}
+
+func TestRetry_OnRetry(t *testing.T) {
+	var attempts []int
+	var errs []error
+	retry := NewRetry(
+		WithMaxAttempts(3),
+		WithInitialDelay(1*time.Millisecond),
+		WithOnRetry(func(attempt int, err error, delay time.Duration) {
+			attempts = append(attempts, attempt)
+			errs = append(errs, err)
+		}),
+	)
+
+	testErr := errors.New("test error")
+	retry.Do(context.Background(), func() error {
+		return testErr
+	})
+
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("Expected OnRetry for attempts [1 2], got %v", attempts)
+	}
+
+	for _, err := range errs {
+		if err != testErr {
+			t.Errorf("Expected OnRetry to receive the failing error, got %v", err)
+		}
+	}
+}
+
+func TestRetry_SharedBudget(t *testing.T) {
+	budget := NewRetryBudget(RetryBudgetConfig{Ratio: 0.5, MaxTokens: 2})
+	newRetry := func() *Retry {
+		return NewRetry(
+			WithMaxAttempts(10),
+			WithInitialDelay(1*time.Millisecond),
+			WithRetryBudget(budget),
+		)
+	}
+
+	calls := 0
+	failing := func() error {
+		calls++
+		return errors.New("test error")
+	}
+
+	err := newRetry().Do(context.Background(), failing)
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Errorf("Expected ErrRetryBudgetExhausted, got %v", err)
+	}
+
+	// 1 initial call + 2 retries from the full bucket
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+
+	// The second caller only gets the 0.5 token it deposited: no retry
+	calls = 0
+	err = newRetry().Do(context.Background(), failing)
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Errorf("Expected ErrRetryBudgetExhausted, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+
+	// A third caller brings the bucket back to one token and may retry once
+	calls = 0
+	newRetry().Do(context.Background(), failing)
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
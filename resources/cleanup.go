@@ -6,6 +6,8 @@ import (
 	"runtime"
 	"sync"
 	"time"
+
+	"github.com/mount-exporter/mount-exporter/recovery"
 )
 
 // ResourceType represents the type of resource
@@ -22,13 +24,51 @@ const (
 // CleanupFunc represents a cleanup function for a resource
 type CleanupFunc func() error
 
+// ContextCleanupFunc represents a cleanup function that honours a deadline
+type ContextCleanupFunc func(ctx context.Context) error
+
 // Resource represents a managed resource
 type Resource struct {
 	ID          string
 	Type        ResourceType
 	Description string
 	Cleanup     CleanupFunc
-	CreatedAt   time.Time
+	// ContextCleanup takes precedence over Cleanup when set
+	ContextCleanup ContextCleanupFunc
+	// DependsOn lists the resources this one uses; it is cleaned up before them
+	DependsOn []string
+	// Priority orders cleanup of unrelated resources: higher goes first
+	Priority int
+	// Timeout bounds this resource's cleanup; zero uses only the shared context
+	Timeout   time.Duration
+	CreatedAt time.Time
+
+	once       sync.Once
+	cleanupErr error
+}
+
+// ResourceOption configures a resource at registration
+type ResourceOption func(*Resource)
+
+// DependsOn declares resources that must be cleaned up after this one
+func DependsOn(ids ...string) ResourceOption {
+	return func(r *Resource) {
+		r.DependsOn = append(r.DependsOn, ids...)
+	}
+}
+
+// WithPriority sets the cleanup priority; higher priorities are cleaned up first
+func WithPriority(priority int) ResourceOption {
+	return func(r *Resource) {
+		r.Priority = priority
+	}
+}
+
+// WithTimeout bounds the time spent cleaning up the resource
+func WithTimeout(timeout time.Duration) ResourceOption {
+	return func(r *Resource) {
+		r.Timeout = timeout
+	}
 }
 
 // ResourceManager manages resources and ensures proper cleanup
@@ -43,9 +83,10 @@ type ResourceManager struct {
 		goroutineCount     int64
 		lastGC             time.Time
 	}
-	logger Logger
-	ctx    context.Context
-	cancel context.CancelFunc
+	logger  Logger
+	monitor *usageMonitor
+	ctx     context.Context
+	cancel  context.CancelFunc
 }
 
 // Logger interface for resource management logging
@@ -65,8 +106,26 @@ type ResourceManagerConfig struct {
 	Logger          Logger
 	EnableGC        bool
 	GCInterval      time.Duration
+	// MaxMemoryMB and MaxGoroutines are hard limits: while one is exceeded
+	// BeginScrape refuses new collections and Ready reports an error.
+	// Soft limits only log. Zero disables a limit.
 	MaxMemoryMB     int64
 	MaxGoroutines   int64
+	MaxOpenFDs      int64
+	SoftMemoryMB    int64
+	SoftGoroutines  int64
+	SoftOpenFDs     int64
+	// SampleInterval is how often usage is sampled; zero disables periodic sampling
+	SampleInterval time.Duration
+	// LeakStackPatterns marks goroutines as leaked when their stack contains
+	// one of the patterns while no scrape is in flight
+	LeakStackPatterns []string
+	// LeakGracePeriod is how long after a scrape ends leak detection waits
+	// (default 5s)
+	LeakGracePeriod time.Duration
+	// Supervisor, when set, runs the background cleanup loop as a
+	// supervised worker that is restarted if it panics
+	Supervisor *recovery.Supervisor
 }
 
 // NewResourceManager creates a new resource manager
@@ -79,6 +138,10 @@ func NewResourceManager(config ResourceManagerConfig) *ResourceManager {
 		config.GCInterval = 5 * time.Minute
 	}
 
+	if config.LeakGracePeriod <= 0 {
+		config.LeakGracePeriod = 5 * time.Second
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	rm := &ResourceManager{
@@ -88,26 +151,60 @@ func NewResourceManager(config ResourceManagerConfig) *ResourceManager {
 		cancel:    cancel,
 	}
 
+	rm.monitor = newUsageMonitor(config)
+	rm.monitor.sample()
+
 	// Start background cleanup if enabled
 	if config.EnableGC {
-		go rm.backgroundCleanup(config.GCInterval)
+		rm.startWorker(config.Supervisor, "resource-gc", func(ctx context.Context) error {
+			return rm.backgroundCleanup(ctx, config.GCInterval)
+		})
+	}
+
+	// Start usage sampling if enabled
+	if config.SampleInterval > 0 {
+		rm.startWorker(config.Supervisor, "resource-monitor", func(ctx context.Context) error {
+			return rm.monitorUsage(ctx, config.SampleInterval)
+		})
 	}
 
 	return rm
 }
 
 // RegisterResource registers a resource for cleanup
-func (rm *ResourceManager) RegisterResource(id string, resourceType ResourceType, description string, cleanup CleanupFunc) {
-	rm.mu.Lock()
-	defer rm.mu.Unlock()
-
-	resource := &Resource{
+func (rm *ResourceManager) RegisterResource(id string, resourceType ResourceType, description string, cleanup CleanupFunc, opts ...ResourceOption) {
+	rm.register(&Resource{
 		ID:          id,
 		Type:        resourceType,
 		Description: description,
 		Cleanup:     cleanup,
-		CreatedAt:   time.Now(),
+	}, opts)
+}
+
+// RegisterResourceContext registers a resource whose cleanup honours the
+// shutdown context and its own timeout
+func (rm *ResourceManager) RegisterResourceContext(id string, resourceType ResourceType, description string, cleanup ContextCleanupFunc, opts ...ResourceOption) {
+	rm.register(&Resource{
+		ID:             id,
+		Type:           resourceType,
+		Description:    description,
+		ContextCleanup: cleanup,
+	}, opts)
+}
+
+// register applies the options and adds the resource
+func (rm *ResourceManager) register(resource *Resource, opts []ResourceOption) {
+	for _, opt := range opts {
+		opt(resource)
 	}
+	resource.CreatedAt = time.Now()
+
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	id := resource.ID
+	resourceType := resource.Type
+	description := resource.Description
 
 	rm.resources[id] = resource
 	rm.stats.totalResources++
@@ -118,27 +215,15 @@ func (rm *ResourceManager) RegisterResource(id string, resourceType ResourceType
 // UnregisterResource removes a resource from management and attempts cleanup
 func (rm *ResourceManager) UnregisterResource(id string) error {
 	rm.mu.Lock()
-	defer rm.mu.Unlock()
-
 	resource, exists := rm.resources[id]
+	delete(rm.resources, id)
+	rm.mu.Unlock()
+
 	if !exists {
 		return fmt.Errorf("resource %s not found", id)
 	}
 
-	var cleanupErr error
-	if resource.Cleanup != nil {
-		if err := resource.Cleanup(); err != nil {
-			rm.stats.failedCleanups++
-			cleanupErr = fmt.Errorf("cleanup failed for resource %s: %w", id, err)
-			rm.logger.Printf("Cleanup failed for resource %s: %v", id, err)
-		} else {
-			rm.stats.cleanedResources++
-			rm.logger.Printf("Successfully cleaned up resource: %s", id)
-		}
-	}
-
-	delete(rm.resources, id)
-	return cleanupErr
+	return rm.cleanup(context.Background(), resource)
 }
 
 // CleanupResource cleans up a specific resource without unregistering it
@@ -151,45 +236,79 @@ func (rm *ResourceManager) CleanupResource(id string) error {
 		return fmt.Errorf("resource %s not found", id)
 	}
 
-	if resource.Cleanup != nil {
-		if err := resource.Cleanup(); err != nil {
-			rm.stats.failedCleanups++
-			rm.logger.Printf("Cleanup failed for resource %s: %v", id, err)
-			return fmt.Errorf("cleanup failed for resource %s: %w", id, err)
-		}
-
-		rm.stats.cleanedResources++
-		rm.logger.Printf("Successfully cleaned up resource: %s", id)
-	}
-
-	return nil
+	return rm.cleanup(context.Background(), resource)
 }
 
 // CleanupAll cleans up all registered resources
 func (rm *ResourceManager) CleanupAll() []error {
+	return rm.CleanupAllContext(context.Background())
+}
+
+// CleanupAllContext cleans up all registered resources in reverse-dependency
+// and priority order, running independent resources in parallel. Each
+// resource's cleanup is bounded by ctx and its own timeout.
+func (rm *ResourceManager) CleanupAllContext(ctx context.Context) []error {
 	rm.mu.Lock()
-	defer rm.mu.Unlock()
+	resources := rm.resources
+	rm.resources = make(map[string]*Resource)
+	rm.mu.Unlock()
+
+	return rm.cleanupOrdered(ctx, resources)
+}
 
-	var errors []error
-
-	for id, resource := range rm.resources {
-		if resource.Cleanup != nil {
-			if err := resource.Cleanup(); err != nil {
-				rm.stats.failedCleanups++
-				errMsg := fmt.Sprintf("cleanup failed for resource %s: %v", id, err)
-				errors = append(errors, fmt.Errorf(errMsg))
-				rm.logger.Printf(errMsg)
-			} else {
-				rm.stats.cleanedResources++
-				rm.logger.Printf("Successfully cleaned up resource: %s", id)
-			}
+// cleanup runs a resource's cleanup exactly once and records the outcome;
+// later calls return the first result
+func (rm *ResourceManager) cleanup(ctx context.Context, resource *Resource) error {
+	resource.once.Do(func() {
+		err := resource.run(ctx)
+
+		rm.mu.Lock()
+		if err != nil {
+			rm.stats.failedCleanups++
+		} else if resource.Cleanup != nil || resource.ContextCleanup != nil {
+			rm.stats.cleanedResources++
+		}
+		rm.mu.Unlock()
+
+		if err != nil {
+			resource.cleanupErr = fmt.Errorf("cleanup failed for resource %s: %w", resource.ID, err)
+			rm.logger.Printf("Cleanup failed for resource %s: %v", resource.ID, err)
+		} else if resource.Cleanup != nil || resource.ContextCleanup != nil {
+			rm.logger.Printf("Successfully cleaned up resource: %s", resource.ID)
 		}
+	})
+
+	return resource.cleanupErr
+}
+
+// run invokes the cleanup function, giving up once the resource's timeout
+// or ctx expires
+func (r *Resource) run(ctx context.Context) error {
+	if r.Cleanup == nil && r.ContextCleanup == nil {
+		return nil
 	}
 
-	// Clear all resources
-	rm.resources = make(map[string]*Resource)
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
 
-	return errors
+	done := make(chan error, 1)
+	go func() {
+		if r.ContextCleanup != nil {
+			done <- r.ContextCleanup(ctx)
+		} else {
+			done <- r.Cleanup()
+		}
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out: %w", ctx.Err())
+	}
 }
 
 // GetResource returns a resource by ID
@@ -224,7 +343,7 @@ func (rm *ResourceManager) GetStats() map[string]interface{} {
 	runtime.ReadMemStats(&m)
 
 	rm.stats.memoryUsage = int64(m.Alloc)
-	rm.stats.goroutineCount = int64(m.NumGoroutine)
+	rm.stats.goroutineCount = int64(runtime.NumGoroutine())
 
 	return map[string]interface{}{
 		"total_resources":   rm.stats.totalResources,
@@ -246,15 +365,55 @@ func (rm *ResourceManager) RunGC() {
 	rm.logger.Printf("Forced garbage collection")
 }
 
-// backgroundCleanup runs periodic cleanup and GC
-func (rm *ResourceManager) backgroundCleanup(interval time.Duration) {
+// startWorker starts a background loop, under supervision if a supervisor
+// is given
+func (rm *ResourceManager) startWorker(supervisor *recovery.Supervisor, name string, run func(ctx context.Context) error) {
+	if supervisor == nil {
+		go run(rm.ctx)
+		return
+	}
+
+	err := supervisor.Go(recovery.WorkerSpec{
+		Name:   name,
+		Policy: recovery.RestartOnPanic,
+		Run:    run,
+	})
+	if err != nil {
+		rm.logger.Printf("Failed to supervise %s: %v", name, err)
+		go run(rm.ctx)
+	}
+}
+
+// monitorUsage samples resource usage until ctx is cancelled or the
+// resource manager is closed
+func (rm *ResourceManager) monitorUsage(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-rm.ctx.Done():
+			return nil
+		case <-ticker.C:
+			rm.monitor.sample()
+		}
+	}
+}
+
+// backgroundCleanup runs periodic cleanup and GC until ctx is cancelled or
+// the resource manager is closed
+func (rm *ResourceManager) backgroundCleanup(ctx context.Context, interval time.Duration) error {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
+		case <-ctx.Done():
+			return nil
 		case <-rm.ctx.Done():
-			return
+			return nil
 		case <-ticker.C:
 			rm.performBackgroundCleanup()
 		}
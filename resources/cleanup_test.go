@@ -1,10 +1,14 @@
 package resources
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/mount-exporter/mount-exporter/recovery"
 )
 
 // TestLogger implements Logger interface for testing
@@ -77,6 +81,10 @@ func TestResourceManager_RegisterResource(t *testing.T) {
 		t.Errorf("Expected resource type File, got %s", resource.Type.String())
 	}
 
+	if cleanupCalled {
+		t.Error("Expected cleanup not to be called on registration")
+	}
+
 	// Check stats
 	stats := rm.GetStats()
 	if stats["total_resources"] != int64(1) {
@@ -191,12 +199,12 @@ func TestResourceManager_CleanupAll(t *testing.T) {
 		EnableGC: false,
 	})
 
-	// Register multiple resources
-	cleanupCount := 0
+	// Register multiple resources; independent resources are cleaned up in parallel
+	var cleanupCount int32
 	for i := 0; i < 3; i++ {
 		id := fmt.Sprintf("resource-%d", i)
 		rm.RegisterResource(id, ResourceTypeFile, fmt.Sprintf("Test resource %d", i), func() error {
-			cleanupCount++
+			atomic.AddInt32(&cleanupCount, 1)
 			return nil
 		})
 	}
@@ -489,6 +497,14 @@ func TestNewFileResource(t *testing.T) {
 	if !contains(resource.Description, "/tmp/test.txt") {
 		t.Errorf("Expected description to contain file path, got %s", resource.Description)
 	}
+
+	if err := rm.CleanupResource("test-file"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if !cleanupCalled {
+		t.Error("Expected cleanup function to be called")
+	}
 }
 
 func TestNewNetworkResource(t *testing.T) {
@@ -520,6 +536,14 @@ func TestNewNetworkResource(t *testing.T) {
 	if resource.Description != "Database connection" {
 		t.Errorf("Expected description 'Database connection', got %s", resource.Description)
 	}
+
+	if err := rm.CleanupResource("test-conn"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if !cleanupCalled {
+		t.Error("Expected cleanup function to be called")
+	}
 }
 
 // Helper function to check if a string contains a substring
@@ -534,4 +558,36 @@ func contains(s, substr string) bool {
 			}
 			return false
 		}())))
-}
\ No newline at end of file
+}
+func TestResourceManager_SupervisedBackgroundCleanup(t *testing.T) {
+	supervisor := recovery.NewSupervisor(recovery.SupervisorConfig{Logger: &TestLogger{}})
+	defer supervisor.Stop(context.Background())
+
+	rm := NewResourceManager(ResourceManagerConfig{
+		Logger:     &TestLogger{},
+		EnableGC:   true,
+		GCInterval: time.Minute,
+		Supervisor: supervisor,
+	})
+
+	statuses := supervisor.Status()
+	if len(statuses) != 1 || statuses[0].Name != "resource-gc" {
+		t.Fatalf("Expected resource-gc worker to be supervised, got %+v", statuses)
+	}
+	if statuses[0].State != "running" {
+		t.Errorf("Expected resource-gc worker to be running, got %s", statuses[0].State)
+	}
+
+	// Closing the manager ends the loop without marking the worker failed
+	rm.Close()
+	deadline := time.Now().Add(time.Second)
+	for supervisor.Status()[0].State != "stopped" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if state := supervisor.Status()[0].State; state != "stopped" {
+		t.Errorf("Expected resource-gc worker to be stopped, got %s", state)
+	}
+	if !supervisor.Healthy() {
+		t.Error("Expected supervisor to stay healthy after a clean close")
+	}
+}
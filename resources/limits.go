@@ -0,0 +1,413 @@
+package resources
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrHardLimitExceeded is returned when a hard resource limit is exceeded
+var ErrHardLimitExceeded = errors.New("resource hard limit exceeded")
+
+// LimitLevel reports how far a resource is over its limits
+type LimitLevel int
+
+const (
+	LimitOK LimitLevel = iota
+	LimitSoft
+	LimitHard
+)
+
+// String returns the string representation of the limit level
+func (l LimitLevel) String() string {
+	switch l {
+	case LimitOK:
+		return "ok"
+	case LimitSoft:
+		return "soft"
+	case LimitHard:
+		return "hard"
+	default:
+		return "unknown"
+	}
+}
+
+// Usage is a sample of the process's resource usage
+type Usage struct {
+	HeapBytes      uint64
+	RSSBytes       uint64
+	Goroutines     int
+	OpenFDs        int
+	ChildProcesses int
+	// LeakedGoroutines counts goroutines in scrape code while no scrape runs
+	LeakedGoroutines int
+	// LeakedChildren counts child processes that outlived a sample interval
+	LeakedChildren int
+	SampledAt      time.Time
+}
+
+// limit holds the soft and hard thresholds for one resource; zero disables
+type limit struct {
+	name string
+	soft int64
+	hard int64
+}
+
+// level returns the limit level for value
+func (l limit) level(value int64) LimitLevel {
+	switch {
+	case l.hard > 0 && value > l.hard:
+		return LimitHard
+	case l.soft > 0 && value > l.soft:
+		return LimitSoft
+	default:
+		return LimitOK
+	}
+}
+
+// usageMonitor samples resource usage, enforces limits and detects leaks
+type usageMonitor struct {
+	mu           sync.RWMutex
+	logger       Logger
+	procRoot     string
+	memory       limit
+	goroutines   limit
+	openFDs      limit
+	leakPatterns []string
+	leakGrace    time.Duration
+
+	usage         Usage
+	levels        map[string]LimitLevel
+	children      map[int]bool
+	scrapes       int
+	lastScrapeEnd time.Time
+
+	heapBytes        *prometheus.Desc
+	rssBytes         *prometheus.Desc
+	goroutineCount   *prometheus.Desc
+	openFDCount      *prometheus.Desc
+	childProcesses   *prometheus.Desc
+	leakedGoroutines *prometheus.Desc
+	leakedChildren   *prometheus.Desc
+	limitState       *prometheus.Desc
+}
+
+// newUsageMonitor creates a monitor for the limits in config
+func newUsageMonitor(config ResourceManagerConfig) *usageMonitor {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("mount_exporter", "resource", name), help, labels, nil)
+	}
+
+	return &usageMonitor{
+		logger:       config.Logger,
+		procRoot:     "/proc",
+		memory:       limit{name: "memory", soft: config.SoftMemoryMB * 1024 * 1024, hard: config.MaxMemoryMB * 1024 * 1024},
+		goroutines:   limit{name: "goroutines", soft: config.SoftGoroutines, hard: config.MaxGoroutines},
+		openFDs:      limit{name: "open_fds", soft: config.SoftOpenFDs, hard: config.MaxOpenFDs},
+		leakPatterns: config.LeakStackPatterns,
+		leakGrace:    config.LeakGracePeriod,
+		levels:       make(map[string]LimitLevel),
+		children:     make(map[int]bool),
+
+		heapBytes:        desc("heap_bytes", "Heap bytes allocated by the exporter"),
+		rssBytes:         desc("resident_memory_bytes", "Resident memory size of the exporter in bytes"),
+		goroutineCount:   desc("goroutines", "Number of goroutines in the exporter"),
+		openFDCount:      desc("open_fds", "Number of open file descriptors in the exporter"),
+		childProcesses:   desc("child_processes", "Number of child processes of the exporter"),
+		leakedGoroutines: desc("leaked_goroutines", "Goroutines in scrape code that outlived their scrape"),
+		leakedChildren:   desc("leaked_child_processes", "Child processes that outlived their scrape"),
+		limitState:       desc("limit_state", "Resource limit state (0=ok, 1=soft limit exceeded, 2=hard limit exceeded)", "resource"),
+	}
+}
+
+// sample measures current usage, evaluates limits and detects leaks
+func (m *usageMonitor) sample() Usage {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	usage := Usage{
+		HeapBytes:  ms.HeapAlloc,
+		RSSBytes:   m.readRSS(),
+		Goroutines: runtime.NumGoroutine(),
+		OpenFDs:    m.countOpenFDs(),
+		SampledAt:  time.Now(),
+	}
+
+	children, zombies := m.listChildren()
+	usage.ChildProcesses = len(children)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idle := m.scrapes == 0 && usage.SampledAt.Sub(m.lastScrapeEnd) >= m.leakGrace
+
+	// A child seen in two consecutive samples, or a zombie nobody reaped,
+	// has outlived the scrape that started it
+	current := make(map[int]bool, len(children))
+	for _, pid := range children {
+		current[pid] = true
+		if zombies[pid] || (idle && m.children[pid]) {
+			usage.LeakedChildren++
+		}
+	}
+	m.children = current
+
+	if idle && len(m.leakPatterns) > 0 {
+		usage.LeakedGoroutines = countGoroutines(m.leakPatterns)
+	}
+
+	if usage.LeakedChildren > 0 {
+		m.logger.Printf("Detected %d leaked child processes", usage.LeakedChildren)
+	}
+	if usage.LeakedGoroutines > 0 {
+		m.logger.Printf("Detected %d leaked goroutines outside of a scrape", usage.LeakedGoroutines)
+	}
+
+	memory := int64(usage.RSSBytes)
+	if memory == 0 {
+		memory = int64(usage.HeapBytes)
+	}
+	m.evaluate(m.memory, memory)
+	m.evaluate(m.goroutines, int64(usage.Goroutines))
+	m.evaluate(m.openFDs, int64(usage.OpenFDs))
+
+	m.usage = usage
+	return usage
+}
+
+// evaluate updates the level for l, logging transitions. Callers must hold m.mu.
+func (m *usageMonitor) evaluate(l limit, value int64) {
+	level := l.level(value)
+	previous := m.levels[l.name]
+	m.levels[l.name] = level
+
+	if level == previous {
+		return
+	}
+
+	switch level {
+	case LimitHard:
+		m.logger.Printf("Hard %s limit exceeded: %d > %d, refusing new collections", l.name, value, l.hard)
+	case LimitSoft:
+		m.logger.Printf("Soft %s limit exceeded: %d > %d", l.name, value, l.soft)
+	case LimitOK:
+		m.logger.Printf("%s usage back within limits: %d", l.name, value)
+	}
+}
+
+// hardLimitError returns an error naming the resources over their hard limit
+func (m *usageMonitor) hardLimitError() error {
+	var exceeded []string
+	for name, level := range m.levels {
+		if level == LimitHard {
+			exceeded = append(exceeded, name)
+		}
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	sort.Strings(exceeded)
+	return fmt.Errorf("%w: %s", ErrHardLimitExceeded, strings.Join(exceeded, ", "))
+}
+
+// readRSS returns the resident set size from /proc/self/statm
+func (m *usageMonitor) readRSS() uint64 {
+	data, err := os.ReadFile(filepath.Join(m.procRoot, "self", "statm"))
+	if err != nil {
+		return 0
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0
+	}
+
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * uint64(os.Getpagesize())
+}
+
+// countOpenFDs returns the number of entries in /proc/self/fd
+func (m *usageMonitor) countOpenFDs() int {
+	entries, err := os.ReadDir(filepath.Join(m.procRoot, "self", "fd"))
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
+
+// listChildren returns the PIDs of this process's children and which of
+// them are zombies
+func (m *usageMonitor) listChildren() ([]int, map[int]bool) {
+	entries, err := os.ReadDir(m.procRoot)
+	if err != nil {
+		return nil, nil
+	}
+
+	self := os.Getpid()
+	var children []int
+	zombies := make(map[int]bool)
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(m.procRoot, entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+
+		// The command name may contain spaces, so parse after its closing paren
+		end := bytes.LastIndexByte(data, ')')
+		if end < 0 {
+			continue
+		}
+		fields := strings.Fields(string(data[end+1:]))
+		if len(fields) < 2 {
+			continue
+		}
+
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil || ppid != self {
+			continue
+		}
+
+		children = append(children, pid)
+		if fields[0] == "Z" {
+			zombies[pid] = true
+		}
+	}
+
+	return children, zombies
+}
+
+// countGoroutines returns the number of goroutines whose stack mentions any
+// of the patterns
+func countGoroutines(patterns []string) int {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	count := 0
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		for _, pattern := range patterns {
+			if strings.Contains(stack, pattern) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// Sample measures resource usage now and re-evaluates the limits
+func (rm *ResourceManager) Sample() Usage {
+	return rm.monitor.sample()
+}
+
+// Usage returns the most recent resource usage sample
+func (rm *ResourceManager) Usage() Usage {
+	rm.monitor.mu.RLock()
+	defer rm.monitor.mu.RUnlock()
+	return rm.monitor.usage
+}
+
+// LimitLevels returns the current limit level of each limited resource
+func (rm *ResourceManager) LimitLevels() map[string]LimitLevel {
+	rm.monitor.mu.RLock()
+	defer rm.monitor.mu.RUnlock()
+
+	levels := make(map[string]LimitLevel, len(rm.monitor.levels))
+	for name, level := range rm.monitor.levels {
+		levels[name] = level
+	}
+	return levels
+}
+
+// Ready returns an error wrapping ErrHardLimitExceeded while any hard limit
+// is exceeded
+func (rm *ResourceManager) Ready() error {
+	rm.monitor.mu.RLock()
+	defer rm.monitor.mu.RUnlock()
+	return rm.monitor.hardLimitError()
+}
+
+// BeginScrape admits a collection and returns a function to call when it
+// ends, or an error if a hard limit is exceeded. Leak detection only runs
+// while no scrape is in flight.
+func (rm *ResourceManager) BeginScrape() (func(), error) {
+	m := rm.monitor
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.hardLimitError(); err != nil {
+		return nil, err
+	}
+
+	m.scrapes++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.scrapes--
+			m.lastScrapeEnd = time.Now()
+		})
+	}, nil
+}
+
+// Describe implements prometheus.Collector interface
+func (rm *ResourceManager) Describe(ch chan<- *prometheus.Desc) {
+	m := rm.monitor
+	ch <- m.heapBytes
+	ch <- m.rssBytes
+	ch <- m.goroutineCount
+	ch <- m.openFDCount
+	ch <- m.childProcesses
+	ch <- m.leakedGoroutines
+	ch <- m.leakedChildren
+	ch <- m.limitState
+}
+
+// Collect implements prometheus.Collector interface
+func (rm *ResourceManager) Collect(ch chan<- prometheus.Metric) {
+	m := rm.monitor
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	usage := m.usage
+	ch <- prometheus.MustNewConstMetric(m.heapBytes, prometheus.GaugeValue, float64(usage.HeapBytes))
+	ch <- prometheus.MustNewConstMetric(m.rssBytes, prometheus.GaugeValue, float64(usage.RSSBytes))
+	ch <- prometheus.MustNewConstMetric(m.goroutineCount, prometheus.GaugeValue, float64(usage.Goroutines))
+	ch <- prometheus.MustNewConstMetric(m.openFDCount, prometheus.GaugeValue, float64(usage.OpenFDs))
+	ch <- prometheus.MustNewConstMetric(m.childProcesses, prometheus.GaugeValue, float64(usage.ChildProcesses))
+	ch <- prometheus.MustNewConstMetric(m.leakedGoroutines, prometheus.GaugeValue, float64(usage.LeakedGoroutines))
+	ch <- prometheus.MustNewConstMetric(m.leakedChildren, prometheus.GaugeValue, float64(usage.LeakedChildren))
+
+	for _, l := range []limit{m.memory, m.goroutines, m.openFDs} {
+		ch <- prometheus.MustNewConstMetric(m.limitState, prometheus.GaugeValue, float64(m.levels[l.name]), l.name)
+	}
+}
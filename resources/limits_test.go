@@ -0,0 +1,219 @@
+package resources
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// writeFakeProc creates a fake /proc tree with the given open fds and children
+func writeFakeProc(t *testing.T, rssPages, fds int, children map[int]string) string {
+	t.Helper()
+	root := t.TempDir()
+
+	self := filepath.Join(root, "self")
+	if err := os.MkdirAll(filepath.Join(self, "fd"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(self, "statm"), []byte(fmt.Sprintf("1000 %d 50 10 0 200 0\n", rssPages)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < fds; i++ {
+		if err := os.WriteFile(filepath.Join(self, "fd", fmt.Sprint(i)), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for pid, state := range children {
+		dir := filepath.Join(root, fmt.Sprint(pid))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		stat := fmt.Sprintf("%d (find mnt) %s %d 1 1 0 -1\n", pid, state, os.Getpid())
+		if err := os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An unrelated process
+	other := filepath.Join(root, "1")
+	os.MkdirAll(other, 0o755)
+	os.WriteFile(filepath.Join(other, "stat"), []byte("1 (init) S 0 1 1 0 -1\n"), 0o644)
+
+	return root
+}
+
+func newTestManager(config ResourceManagerConfig, procRoot string) *ResourceManager {
+	config.Logger = &TestLogger{}
+	rm := NewResourceManager(config)
+	rm.monitor.procRoot = procRoot
+	rm.monitor.sample()
+	return rm
+}
+
+func TestLimitLevel_String(t *testing.T) {
+	tests := map[LimitLevel]string{
+		LimitOK:        "ok",
+		LimitSoft:      "soft",
+		LimitHard:      "hard",
+		LimitLevel(99): "unknown",
+	}
+
+	for level, expected := range tests {
+		if got := level.String(); got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	}
+}
+
+func TestResourceManager_SampleUsage(t *testing.T) {
+	root := writeFakeProc(t, 256, 7, map[int]string{4242: "S"})
+	rm := newTestManager(ResourceManagerConfig{}, root)
+
+	usage := rm.Usage()
+	if usage.RSSBytes != uint64(256*os.Getpagesize()) {
+		t.Errorf("Expected RSS %d, got %d", 256*os.Getpagesize(), usage.RSSBytes)
+	}
+	if usage.OpenFDs != 7 {
+		t.Errorf("Expected 7 open fds, got %d", usage.OpenFDs)
+	}
+	if usage.ChildProcesses != 1 {
+		t.Errorf("Expected 1 child process, got %d", usage.ChildProcesses)
+	}
+	if usage.Goroutines <= 0 || usage.HeapBytes == 0 {
+		t.Errorf("Expected goroutines and heap to be sampled, got %+v", usage)
+	}
+}
+
+func TestResourceManager_HardLimit(t *testing.T) {
+	root := writeFakeProc(t, 10, 3, nil)
+	rm := newTestManager(ResourceManagerConfig{MaxGoroutines: 1, MaxOpenFDs: 100}, root)
+
+	if err := rm.Ready(); !errors.Is(err, ErrHardLimitExceeded) || !strings.Contains(err.Error(), "goroutines") {
+		t.Errorf("Expected goroutine hard limit error, got %v", err)
+	}
+
+	if _, err := rm.BeginScrape(); !errors.Is(err, ErrHardLimitExceeded) {
+		t.Errorf("Expected BeginScrape to refuse collection, got %v", err)
+	}
+
+	levels := rm.LimitLevels()
+	if levels["goroutines"] != LimitHard || levels["open_fds"] != LimitOK {
+		t.Errorf("Unexpected limit levels: %v", levels)
+	}
+}
+
+func TestResourceManager_SoftLimit(t *testing.T) {
+	root := writeFakeProc(t, 10, 20, nil)
+	logger := &TestLogger{}
+	rm := NewResourceManager(ResourceManagerConfig{Logger: logger, SoftOpenFDs: 10, MaxOpenFDs: 100})
+	rm.monitor.procRoot = root
+	rm.Sample()
+
+	if err := rm.Ready(); err != nil {
+		t.Errorf("Expected soft limit to keep the exporter ready, got %v", err)
+	}
+
+	end, err := rm.BeginScrape()
+	if err != nil {
+		t.Fatalf("Expected soft limit to allow collection, got %v", err)
+	}
+	end()
+
+	if rm.LimitLevels()["open_fds"] != LimitSoft {
+		t.Errorf("Expected soft open_fds level, got %v", rm.LimitLevels())
+	}
+
+	found := false
+	for _, msg := range logger.GetMessages() {
+		if strings.Contains(msg, "Soft open_fds limit exceeded") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected soft limit to be logged, got %v", logger.GetMessages())
+	}
+}
+
+func TestResourceManager_LeakedChildProcesses(t *testing.T) {
+	root := writeFakeProc(t, 10, 3, map[int]string{100: "S", 101: "Z"})
+	rm := newTestManager(ResourceManagerConfig{LeakGracePeriod: time.Nanosecond}, root)
+
+	// The zombie is leaked immediately; the sleeping child only once it
+	// survives into the next sample
+	if got := rm.Usage().LeakedChildren; got != 1 {
+		t.Errorf("Expected 1 leaked child after the first sample, got %d", got)
+	}
+
+	if got := rm.Sample().LeakedChildren; got != 2 {
+		t.Errorf("Expected 2 leaked children after the second sample, got %d", got)
+	}
+}
+
+func TestResourceManager_NoChildLeakDuringScrape(t *testing.T) {
+	root := writeFakeProc(t, 10, 3, map[int]string{100: "S"})
+	rm := newTestManager(ResourceManagerConfig{LeakGracePeriod: time.Nanosecond}, root)
+
+	end, err := rm.BeginScrape()
+	if err != nil {
+		t.Fatalf("Failed to begin scrape: %v", err)
+	}
+	defer end()
+
+	if got := rm.Sample().LeakedChildren; got != 0 {
+		t.Errorf("Expected no leak while a scrape is in flight, got %d", got)
+	}
+}
+
+// leakyScrapeWorker blocks until released, standing in for a leaked goroutine
+func leakyScrapeWorker(release chan struct{}) {
+	<-release
+}
+
+func TestResourceManager_LeakedGoroutines(t *testing.T) {
+	root := writeFakeProc(t, 10, 3, nil)
+	rm := newTestManager(ResourceManagerConfig{
+		LeakStackPatterns: []string{"resources.leakyScrapeWorker"},
+		LeakGracePeriod:   time.Nanosecond,
+	}, root)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		close(started)
+		leakyScrapeWorker(release)
+	}()
+	<-started
+	defer close(release)
+
+	// Give the goroutine time to block inside leakyScrapeWorker
+	time.Sleep(10 * time.Millisecond)
+
+	if got := rm.Sample().LeakedGoroutines; got != 1 {
+		t.Errorf("Expected 1 leaked goroutine, got %d", got)
+	}
+}
+
+func TestResourceManager_UsageMetrics(t *testing.T) {
+	root := writeFakeProc(t, 10, 3, nil)
+	rm := newTestManager(ResourceManagerConfig{}, root)
+
+	// 7 usage gauges plus one limit state per limited resource
+	if count := testutil.CollectAndCount(rm); count != 10 {
+		t.Errorf("Expected 10 metrics, got %d", count)
+	}
+
+	if err := testutil.CollectAndCompare(rm, strings.NewReader(`
+# HELP mount_exporter_resource_open_fds Number of open file descriptors in the exporter
+# TYPE mount_exporter_resource_open_fds gauge
+mount_exporter_resource_open_fds 3
+`), "mount_exporter_resource_open_fds"); err != nil {
+		t.Errorf("Unexpected open fds metric: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// cleanupOrdered cleans up resources so that every resource is torn down
+// before the resources it depends on. Resources are grouped into phases by
+// priority, highest first; within a phase, resources whose dependents are
+// done are cleaned up in parallel.
+func (rm *ResourceManager) cleanupOrdered(ctx context.Context, resources map[string]*Resource) []error {
+	dependents, errs := buildDependents(resources)
+	priorities := effectivePriorities(resources, dependents)
+
+	// Group resources into phases by effective priority
+	phases := make(map[int][]*Resource)
+	for id, resource := range resources {
+		phases[priorities[id]] = append(phases[priorities[id]], resource)
+	}
+	order := make([]int, 0, len(phases))
+	for priority := range phases {
+		order = append(order, priority)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(order)))
+
+	var mu sync.Mutex
+	for _, priority := range order {
+		phase := phases[priority]
+
+		done := make(map[string]chan struct{}, len(phase))
+		for _, resource := range phase {
+			done[resource.ID] = make(chan struct{})
+		}
+
+		var wg sync.WaitGroup
+		for _, resource := range phase {
+			wg.Add(1)
+			go func(resource *Resource) {
+				defer wg.Done()
+				defer close(done[resource.ID])
+
+				// Dependents in earlier phases have already finished
+				for _, dependent := range dependents[resource.ID] {
+					if ch, ok := done[dependent]; ok {
+						<-ch
+					}
+				}
+
+				if err := rm.cleanup(ctx, resource); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}(resource)
+		}
+		wg.Wait()
+	}
+
+	return errs
+}
+
+// buildDependents maps each resource ID to the resources that depend on it.
+// Dependencies on unknown resources are ignored, and edges closing a cycle
+// are dropped and reported so that cleanup cannot deadlock.
+func buildDependents(resources map[string]*Resource) (map[string][]string, []error) {
+	ids := make([]string, 0, len(resources))
+	for id := range resources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// dependencies[id] lists the known resources id depends on
+	dependencies := make(map[string][]string, len(resources))
+	for _, id := range ids {
+		seen := make(map[string]bool)
+		for _, dep := range resources[id].DependsOn {
+			if _, ok := resources[dep]; ok && !seen[dep] {
+				seen[dep] = true
+				dependencies[id] = append(dependencies[id], dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var errs []error
+	state := make(map[string]int, len(resources))
+	dependents := make(map[string][]string, len(resources))
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		for _, dep := range dependencies[id] {
+			switch state[dep] {
+			case visiting:
+				errs = append(errs, fmt.Errorf("dependency cycle: resource %s depends on %s, ignoring dependency", id, dep))
+				continue
+			case unvisited:
+				visit(dep)
+			}
+			dependents[dep] = append(dependents[dep], id)
+		}
+		state[id] = visited
+	}
+
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+
+	return dependents, errs
+}
+
+// effectivePriorities lowers each resource's priority to that of its lowest
+// dependent, so a resource is never scheduled before something that uses it
+func effectivePriorities(resources map[string]*Resource, dependents map[string][]string) map[string]int {
+	priorities := make(map[string]int, len(resources))
+
+	var resolve func(id string) int
+	resolve = func(id string) int {
+		if priority, ok := priorities[id]; ok {
+			return priority
+		}
+
+		priority := resources[id].Priority
+		for _, dependent := range dependents[id] {
+			if p := resolve(dependent); p < priority {
+				priority = p
+			}
+		}
+
+		priorities[id] = priority
+		return priority
+	}
+
+	for id := range resources {
+		resolve(id)
+	}
+
+	return priorities
+}
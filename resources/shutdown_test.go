@@ -0,0 +1,226 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// cleanupRecorder records the order in which cleanups run
+type cleanupRecorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *cleanupRecorder) cleanup(id string) CleanupFunc {
+	return func() error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.order = append(r.order, id)
+		return nil
+	}
+}
+
+func (r *cleanupRecorder) index(id string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, got := range r.order {
+		if got == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestCleanupAll_DependencyOrder(t *testing.T) {
+	rm := NewResourceManager(ResourceManagerConfig{Logger: &TestLogger{}})
+	recorder := &cleanupRecorder{}
+
+	// Registered in the "wrong" order on purpose
+	rm.RegisterResource("listener", ResourceTypeNetwork, "listener", recorder.cleanup("listener"))
+	rm.RegisterResource("collector", ResourceTypeCustom, "collector", recorder.cleanup("collector"))
+	rm.RegisterResource("http-server", ResourceTypeNetwork, "server", recorder.cleanup("http-server"),
+		DependsOn("listener", "collector"))
+	rm.RegisterResource("proxy", ResourceTypeNetwork, "proxy", recorder.cleanup("proxy"),
+		DependsOn("http-server"))
+
+	if errs := rm.CleanupAll(); len(errs) > 0 {
+		t.Fatalf("Expected no cleanup errors, got %v", errs)
+	}
+
+	if len(recorder.order) != 4 {
+		t.Fatalf("Expected 4 cleanups, got %v", recorder.order)
+	}
+	if recorder.index("proxy") > recorder.index("http-server") {
+		t.Errorf("Expected proxy before http-server, got %v", recorder.order)
+	}
+	if recorder.index("http-server") > recorder.index("listener") || recorder.index("http-server") > recorder.index("collector") {
+		t.Errorf("Expected http-server before its dependencies, got %v", recorder.order)
+	}
+}
+
+func TestCleanupAll_Priority(t *testing.T) {
+	rm := NewResourceManager(ResourceManagerConfig{Logger: &TestLogger{}})
+	recorder := &cleanupRecorder{}
+
+	rm.RegisterResource("low", ResourceTypeCustom, "low", recorder.cleanup("low"), WithPriority(-1))
+	rm.RegisterResource("default", ResourceTypeCustom, "default", recorder.cleanup("default"))
+	rm.RegisterResource("high", ResourceTypeCustom, "high", recorder.cleanup("high"), WithPriority(10))
+
+	rm.CleanupAll()
+
+	expected := []string{"high", "default", "low"}
+	for i, id := range expected {
+		if recorder.index(id) != i {
+			t.Fatalf("Expected order %v, got %v", expected, recorder.order)
+		}
+	}
+}
+
+func TestCleanupAll_DependencyOverridesPriority(t *testing.T) {
+	rm := NewResourceManager(ResourceManagerConfig{Logger: &TestLogger{}})
+	recorder := &cleanupRecorder{}
+
+	// The dependency must still outlive its low-priority dependent
+	rm.RegisterResource("database", ResourceTypeCustom, "db", recorder.cleanup("database"), WithPriority(10))
+	rm.RegisterResource("worker", ResourceTypeCustom, "worker", recorder.cleanup("worker"),
+		DependsOn("database"), WithPriority(-5))
+
+	rm.CleanupAll()
+
+	if recorder.index("worker") > recorder.index("database") {
+		t.Errorf("Expected worker before database, got %v", recorder.order)
+	}
+}
+
+func TestCleanupAll_IndependentResourcesRunInParallel(t *testing.T) {
+	rm := NewResourceManager(ResourceManagerConfig{Logger: &TestLogger{}})
+
+	var running, maxRunning int32
+	slow := func() error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+
+	rm.RegisterResource("a", ResourceTypeCustom, "a", slow)
+	rm.RegisterResource("b", ResourceTypeCustom, "b", slow)
+	rm.RegisterResource("c", ResourceTypeCustom, "c", slow)
+
+	start := time.Now()
+	rm.CleanupAll()
+
+	if atomic.LoadInt32(&maxRunning) < 2 {
+		t.Errorf("Expected independent cleanups to overlap, max concurrency %d", maxRunning)
+	}
+	if elapsed := time.Since(start); elapsed > 140*time.Millisecond {
+		t.Errorf("Expected parallel cleanup, took %v", elapsed)
+	}
+}
+
+func TestCleanupAll_Timeout(t *testing.T) {
+	rm := NewResourceManager(ResourceManagerConfig{Logger: &TestLogger{}})
+	recorder := &cleanupRecorder{}
+
+	block := make(chan struct{})
+	defer close(block)
+
+	rm.RegisterResource("stuck", ResourceTypeCustom, "stuck", func() error {
+		<-block
+		return nil
+	}, WithTimeout(20*time.Millisecond), DependsOn("after-stuck"))
+	rm.RegisterResource("after-stuck", ResourceTypeCustom, "after", recorder.cleanup("after-stuck"))
+
+	errs := rm.CleanupAll()
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 timeout error, got %v", errs)
+	}
+	if !errors.Is(errs[0], context.DeadlineExceeded) || !strings.Contains(errs[0].Error(), "stuck") {
+		t.Errorf("Expected deadline error for stuck resource, got %v", errs[0])
+	}
+	if recorder.index("after-stuck") != 0 {
+		t.Error("Expected dependency to be cleaned up after its dependent timed out")
+	}
+}
+
+func TestCleanupAllContext_SharedDeadline(t *testing.T) {
+	rm := NewResourceManager(ResourceManagerConfig{Logger: &TestLogger{}})
+
+	rm.RegisterResourceContext("server", ResourceTypeNetwork, "server", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	errs := rm.CleanupAllContext(ctx)
+	if len(errs) != 1 || !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline error, got %v", errs)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected cleanup to honour the shared deadline, took %v", elapsed)
+	}
+}
+
+func TestCleanup_Idempotent(t *testing.T) {
+	rm := NewResourceManager(ResourceManagerConfig{Logger: &TestLogger{}})
+
+	var calls int32
+	rm.RegisterResource("server", ResourceTypeNetwork, "server", func() error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+
+	if err := rm.CleanupResource("server"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if errs := rm.CleanupAll(); len(errs) > 0 {
+		t.Fatalf("Expected no cleanup errors, got %v", errs)
+	}
+	rm.Close()
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected cleanup to run exactly once, ran %d times", got)
+	}
+	if got := rm.GetStats()["cleaned_resources"]; got != int64(1) {
+		t.Errorf("Expected 1 cleaned resource, got %v", got)
+	}
+}
+
+func TestCleanupAll_DependencyCycle(t *testing.T) {
+	rm := NewResourceManager(ResourceManagerConfig{Logger: &TestLogger{}})
+	recorder := &cleanupRecorder{}
+
+	rm.RegisterResource("a", ResourceTypeCustom, "a", recorder.cleanup("a"), DependsOn("b"))
+	rm.RegisterResource("b", ResourceTypeCustom, "b", recorder.cleanup("b"), DependsOn("a"))
+	rm.RegisterResource("c", ResourceTypeCustom, "c", recorder.cleanup("c"), DependsOn("missing"))
+
+	done := make(chan []error)
+	go func() { done <- rm.CleanupAll() }()
+
+	select {
+	case errs := <-done:
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), "dependency cycle") {
+			t.Errorf("Expected a dependency cycle error, got %v", errs)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Cleanup deadlocked on a dependency cycle")
+	}
+
+	if len(recorder.order) != 3 {
+		t.Errorf("Expected all resources to be cleaned up, got %v", recorder.order)
+	}
+}
@@ -2,17 +2,25 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/kubernetes"
 	"github.com/mount-exporter/mount-exporter/metrics"
+	"github.com/mount-exporter/mount-exporter/recovery"
 	"github.com/mount-exporter/mount-exporter/resources"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -20,6 +28,30 @@ import (
 
 var version = "dev" // This will be set at build time
 
+const (
+	// requestIDHeader carries the request ID in requests and responses
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLength bounds client-supplied request IDs
+	maxRequestIDLength = 128
+
+	// Defaults used when the corresponding server setting is zero
+	defaultReadTimeout     = 30 * time.Second
+	defaultWriteTimeout    = 30 * time.Second
+	defaultIdleTimeout     = 60 * time.Second
+	defaultShutdownTimeout = 30 * time.Second
+
+	// scrapeRetryAfter is the Retry-After hint sent when scrapes are rejected
+	scrapeRetryAfter = time.Second
+)
+
+// scrapeStackPatterns identify goroutines owned by a scrape: those in the
+// scrape collector's methods or created by them. FindmntWrapper frames are
+// not enough, since the latency and write probe workers and the detached
+// runPending calls legitimately run FindmntWrapper code between scrapes.
+var scrapeStackPatterns = []string{
+	"mount-exporter/metrics.(*Collector).",
+}
+
 // Server represents the HTTP server
 type Server struct {
 	config          *config.Config
@@ -28,6 +60,21 @@ type Server struct {
 	httpServer      *http.Server
 	logger          *log.Logger
 	resourceManager *resources.ResourceManager
+	panicHandler    *recovery.PanicHandler
+	supervisor      *recovery.Supervisor
+	writeProbe      *metrics.WriteProbeCollector
+	latencyProbe    *metrics.LatencyProbeCollector
+	kubernetes      *kubernetes.Enricher
+	scrapeSlots     chan struct{}
+	scrapesRejected prometheus.Counter
+	draining        atomic.Bool
+}
+
+// healthResponse is the body returned by the health endpoints
+type healthResponse struct {
+	Status  string                  `json:"status"`
+	Error   string                  `json:"error,omitempty"`
+	Workers []recovery.WorkerStatus `json:"workers,omitempty"`
 }
 
 // NewServer creates a new HTTP server
@@ -39,12 +86,75 @@ func NewServer(cfg *config.Config, logger *log.Logger) (*Server, error) {
 	registry := prometheus.NewRegistry()
 	registry.MustRegister(collector)
 
+	// Recover panics in request handlers and mount checks
+	panicHandler := recovery.NewPanicHandler(recovery.PanicRecoveryConfig{
+		Enabled: true,
+		Logger:  logger,
+	})
+	registry.MustRegister(panicHandler)
+	collector.SetPanicHandler(panicHandler)
+
+	// Restart background workers that panic
+	supervisor := recovery.NewSupervisor(recovery.SupervisorConfig{
+		PanicHandler: panicHandler,
+		Logger:       logger,
+	})
+
 	// Create resource manager
 	resourceManager := resources.NewResourceManager(resources.ResourceManagerConfig{
-		Logger:     &resourcesLogger{logger: logger},
-		EnableGC:   true,
-		GCInterval: 5 * time.Minute,
+		Logger:         &resourcesLogger{logger: logger},
+		EnableGC:       true,
+		GCInterval:     5 * time.Minute,
+		MaxMemoryMB:    cfg.Limits.MaxMemoryMB,
+		MaxGoroutines:  cfg.Limits.MaxGoroutines,
+		MaxOpenFDs:     cfg.Limits.MaxOpenFDs,
+		SoftMemoryMB:   cfg.Limits.SoftMemoryMB,
+		SoftGoroutines: cfg.Limits.SoftGoroutines,
+		SoftOpenFDs:    cfg.Limits.SoftOpenFDs,
+		SampleInterval: cfg.Limits.SampleInterval,
+		// Goroutines still running scrape code after a scrape has ended are leaks
+		LeakStackPatterns: scrapeStackPatterns,
+		Supervisor: supervisor,
+	})
+	registry.MustRegister(resourceManager)
+	collector.SetScrapeGuard(resourceManager)
+
+	// Stop background workers as part of the ordered shutdown
+	resourceManager.RegisterResourceContext(
+		"background-workers",
+		resources.ResourceTypeGoroutine,
+		"Supervised background workers",
+		supervisor.Stop,
+	)
+
+	// Count scrapes turned away by max_concurrent_scrapes
+	scrapesRejected := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "mount_exporter",
+		Name:      "scrapes_rejected_total",
+		Help:      "Total number of scrapes rejected because too many were in flight",
 	})
+	registry.MustRegister(scrapesRejected)
+
+	// Write probes run in the background, off the scrape path
+	var writeProbe *metrics.WriteProbeCollector
+	if len(cfg.WriteProbe.MountPoints) > 0 {
+		writeProbe = metrics.NewWriteProbeCollector(cfg.WriteProbe)
+		registry.MustRegister(writeProbe)
+	}
+
+	// Kubernetes metadata is refreshed in the background and looked up on scrape
+	var enricher *kubernetes.Enricher
+	if cfg.Kubernetes.Enabled {
+		enricher = kubernetes.NewEnricher(cfg.Kubernetes, logger)
+		collector.SetKubernetesEnricher(enricher)
+	}
+
+	// Latency probes share the collector's findmnt timeout
+	var latencyProbe *metrics.LatencyProbeCollector
+	if len(cfg.LatencyProbe.MountPoints) > 0 {
+		latencyProbe = metrics.NewLatencyProbeCollector(cfg.LatencyProbe, collector.GetFindmntWrapper())
+		registry.MustRegister(latencyProbe)
+	}
 
 	// Create HTTP server
 	server := &Server{
@@ -53,6 +163,12 @@ func NewServer(cfg *config.Config, logger *log.Logger) (*Server, error) {
 		registry:        registry,
 		logger:          logger,
 		resourceManager: resourceManager,
+		panicHandler:    panicHandler,
+		supervisor:      supervisor,
+		writeProbe:      writeProbe,
+		latencyProbe:    latencyProbe,
+		kubernetes:      enricher,
+		scrapesRejected: scrapesRejected,
 	}
 
 	return server, nil
@@ -62,31 +178,97 @@ func NewServer(cfg *config.Config, logger *log.Logger) (*Server, error) {
 func (s *Server) setupRoutes() {
 	mux := http.NewServeMux()
 
+	// handle registers a route with panic recovery that knows the route pattern
+	handle := func(pattern string, handler http.Handler) {
+		mux.Handle(pattern, s.recoveryMiddleware(pattern, handler))
+	}
+
 	// Metrics endpoint
-	mux.Handle(s.config.Server.Path, promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{
-		EnableOpenMetrics: true,
-	}))
+	handle(s.config.Server.Path, s.scrapeLimitMiddleware(http.HandlerFunc(s.metricsHandler)))
 
 	// Health endpoint
-	mux.HandleFunc("/health", s.healthHandler)
-	mux.HandleFunc("/healthz", s.healthHandler) // Alternative health endpoint
+	handle("/health", http.HandlerFunc(s.healthHandler))
+	handle("/healthz", http.HandlerFunc(s.healthHandler)) // Alternative health endpoint
+
+	// Readiness endpoint
+	handle("/ready", http.HandlerFunc(s.readyHandler))
+
+	// Configuration schema endpoint
+	handle("/api/v1/config/schema", http.HandlerFunc(s.configSchemaHandler))
 
 	// Root endpoint
-	mux.HandleFunc("/", s.rootHandler)
+	handle("/", http.HandlerFunc(s.rootHandler))
 
 	// Apply middleware
 	handler := s.loggingMiddleware(mux)
 	handler = s.securityMiddleware(handler)
 
 	s.httpServer = &http.Server{
-		Addr:         s.config.GetAddress(),
-		Handler:      handler,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:           s.config.GetAddress(),
+		Handler:        handler,
+		ReadTimeout:    durationOrDefault(s.config.Server.ReadTimeout, defaultReadTimeout),
+		WriteTimeout:   durationOrDefault(s.config.Server.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:    durationOrDefault(s.config.Server.IdleTimeout, defaultIdleTimeout),
+		MaxHeaderBytes: s.config.Server.MaxHeaderBytes,
 	}
 }
 
+// metricsHandler serves all metrics, or with mount and group query
+// parameters only the selected mount points from a per-request registry
+func (s *Server) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	opts := promhttp.HandlerOpts{EnableOpenMetrics: true}
+
+	query := r.URL.Query()
+	mounts, groups := query["mount"], query["group"]
+	if len(mounts) == 0 && len(groups) == 0 {
+		promhttp.HandlerFor(s.registry, opts).ServeHTTP(w, r)
+		return
+	}
+
+	mountPoints, err := s.config.ResolveMountPoints(mounts, groups)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(s.collector.ForMountPoints(mountPoints)); err != nil {
+		http.Error(w, fmt.Sprintf("failed to register collector: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	promhttp.HandlerFor(registry, opts).ServeHTTP(w, r)
+}
+
+// durationOrDefault returns d, or def when d is not set
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+// scrapeLimitMiddleware rejects scrapes beyond max_concurrent_scrapes with 503
+func (s *Server) scrapeLimitMiddleware(next http.Handler) http.Handler {
+	limit := s.config.Server.MaxConcurrentScrapes
+	if limit <= 0 {
+		return next
+	}
+	s.scrapeSlots = make(chan struct{}, limit)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case s.scrapeSlots <- struct{}{}:
+			defer func() { <-s.scrapeSlots }()
+			next.ServeHTTP(w, r)
+		default:
+			s.scrapesRejected.Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(scrapeRetryAfter/time.Second)))
+			http.Error(w, "too many concurrent scrapes", http.StatusServiceUnavailable)
+		}
+	})
+}
+
 // healthHandler handles health check requests
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -94,17 +276,83 @@ func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	response := healthResponse{
+		Status:  "healthy",
+		Workers: s.supervisor.Status(),
+	}
+	status := http.StatusOK
+
 	// Check if findmnt is available
 	findmnt := s.collector.GetFindmntWrapper()
-	if !findmnt.IsAvailable() {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		w.Write([]byte(`{"status": "unhealthy", "error": "findmnt command not available"}`))
+	switch {
+	case !findmnt.IsAvailable():
+		response.Status = "unhealthy"
+		response.Error = "findmnt command not available"
+		status = http.StatusServiceUnavailable
+	case !s.supervisor.Healthy():
+		response.Status = "unhealthy"
+		response.Error = "background worker failed"
+		status = http.StatusServiceUnavailable
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, "Failed to encode health status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// readyHandler reports whether the exporter should receive scrapes
+func (s *Server) readyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := healthResponse{Status: "ready"}
+	status := http.StatusOK
+
+	if s.draining.Load() {
+		response.Status = "draining"
+		response.Error = "server is shutting down"
+		status = http.StatusServiceUnavailable
+	} else if err := s.resourceManager.Ready(); err != nil {
+		response.Status = "not ready"
+		response.Error = err.Error()
+		status = http.StatusServiceUnavailable
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, "Failed to encode readiness status", http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// configSchemaHandler serves the JSON Schema of the configuration file
+func (s *Server) configSchemaHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := config.Schema()
+	if err != nil {
+		http.Error(w, "Failed to encode configuration schema", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status": "healthy"}`))
+	w.Write(body)
 }
 
 // rootHandler handles requests to the root path
@@ -119,6 +367,8 @@ func (s *Server) rootHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Mount Exporter\n\n")
 	fmt.Fprintf(w, "Metrics: %s\n", s.config.Server.Path)
 	fmt.Fprintf(w, "Health: /health\n")
+	fmt.Fprintf(w, "Ready: /ready\n")
+	fmt.Fprintf(w, "Config schema: /api/v1/config/schema\n")
 	fmt.Fprintf(w, "Version: %s\n", version)
 }
 
@@ -128,7 +378,7 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 		start := time.Now()
 
 		// Create a response writer wrapper to capture status code
-		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		wrapped := newResponseWriter(w)
 
 		next.ServeHTTP(wrapped, r)
 
@@ -150,17 +400,121 @@ func (s *Server) securityMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// recoveryMiddleware recovers panics in a route handler, reports them to the
+// panic handler with the request's route and method, and responds with a 500
+// carrying the request ID
+func (s *Server) recoveryMiddleware(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := requestIDFrom(r)
+		w.Header().Set(requestIDHeader, requestID)
+
+		ctx := recovery.WithComponent(r.Context(), "http")
+		ctx = recovery.WithRequestInfo(ctx, recovery.RequestInfo{
+			ID:     requestID,
+			Method: r.Method,
+			Route:  route,
+		})
+
+		wrapped := newResponseWriter(w)
+		aborted := false
+
+		err := s.panicHandler.RecoverWithContext(ctx, func(ctx context.Context) error {
+			defer func() {
+				// http.ErrAbortHandler is a deliberate abort, not a crash
+				if p := recover(); p != nil {
+					if p != http.ErrAbortHandler {
+						panic(p)
+					}
+					aborted = true
+				}
+			}()
+
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+			return nil
+		})
+
+		if aborted {
+			panic(http.ErrAbortHandler)
+		}
+		if err == nil {
+			return
+		}
+
+		// The response is already partially sent; let the client see it fail
+		if wrapped.wroteHeader {
+			panic(http.ErrAbortHandler)
+		}
+
+		body, _ := json.Marshal(map[string]string{
+			"error":      "internal server error",
+			"request_id": requestID,
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write(body)
+	})
+}
+
+// requestIDFrom returns the client-supplied request ID if it is well formed,
+// or a newly generated one
+func requestIDFrom(r *http.Request) string {
+	if id := r.Header.Get(requestIDHeader); isValidRequestID(id) {
+		return id
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// isValidRequestID reports whether id is safe to echo back and log
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // responseWriter wraps http.ResponseWriter to capture status code
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode  int
+	wroteHeader bool
+}
+
+// newResponseWriter wraps w, defaulting the recorded status to 200 OK
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
+	rw.wroteHeader = true
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	rw.wroteHeader = true
+	return rw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher when the underlying writer supports it
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	s.setupRoutes()
@@ -178,17 +532,53 @@ func (s *Server) Start() error {
 		return fmt.Errorf("failed to create listener: %w", err)
 	}
 
-	// Register listener as a resource
-	listenerID := fmt.Sprintf("tcp-listener-%s", s.config.GetAddress())
+	// Register listener as a resource; the HTTP server closes it while
+	// shutting down, so a second close is not an error
 	s.resourceManager.RegisterResource(
-		listenerID,
+		s.listenerID(),
 		resources.ResourceTypeNetwork,
 		fmt.Sprintf("TCP listener on %s", s.config.GetAddress()),
 		func() error {
-			return listener.Close()
+			if err := listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+				return err
+			}
+			return nil
 		},
 	)
 
+	if s.writeProbe != nil {
+		err := s.supervisor.Go(recovery.WorkerSpec{
+			Name:   "write-probe",
+			Run:    s.writeProbe.Run,
+			Policy: recovery.RestartOnPanic,
+		})
+		if err != nil {
+			s.logger.Printf("Failed to start write probes: %v", err)
+		}
+	}
+
+	if s.latencyProbe != nil {
+		err := s.supervisor.Go(recovery.WorkerSpec{
+			Name:   "latency-probe",
+			Run:    s.latencyProbe.Run,
+			Policy: recovery.RestartOnPanic,
+		})
+		if err != nil {
+			s.logger.Printf("Failed to start latency probes: %v", err)
+		}
+	}
+
+	if s.kubernetes != nil && s.kubernetes.NeedsRefresh() {
+		err := s.supervisor.Go(recovery.WorkerSpec{
+			Name:   "kubernetes-metadata",
+			Run:    s.kubernetes.Run,
+			Policy: recovery.RestartOnPanic,
+		})
+		if err != nil {
+			s.logger.Printf("Failed to start Kubernetes metadata refresh: %v", err)
+		}
+	}
+
 	// Start server in a goroutine
 	go func() {
 		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
@@ -207,36 +597,55 @@ func (s *Server) Stop(ctx context.Context) error {
 
 	s.logger.Println("Shutting down server...")
 
+	// Fail readiness first so load balancers stop routing scrapes here
+	// before the listener closes
+	s.drain(ctx)
+
 	// Create shutdown context with timeout
-	shutdownCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(ctx, durationOrDefault(s.config.Server.ShutdownTimeout, defaultShutdownTimeout))
 	defer cancel()
 
-	// Attempt graceful shutdown
-	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
-		s.logger.Printf("Server shutdown error: %v", err)
-		return err
+	// The HTTP server, listener and background workers are registered
+	// resources, so one ordered cleanup drains the server before closing
+	// what it uses and tears each resource down exactly once
+	s.logger.Println("Cleaning up resources...")
+	errs := s.resourceManager.CleanupAllContext(shutdownCtx)
+	s.resourceManager.Close()
+
+	if len(errs) > 0 {
+		s.logger.Printf("Resource cleanup encountered %d errors", len(errs))
+		for _, err := range errs {
+			s.logger.Printf("Cleanup error: %v", err)
+		}
+		return fmt.Errorf("shutdown encountered %d errors: %w", len(errs), errors.Join(errs...))
 	}
 
 	s.logger.Println("Server shutdown complete")
+	return nil
+}
 
-	// Cleanup all registered resources
-	s.logger.Println("Cleaning up resources...")
-	if s.resourceManager != nil {
-		errors := s.resourceManager.CleanupAll()
-		if len(errors) > 0 {
-			s.logger.Printf("Resource cleanup encountered %d errors", len(errors))
-			for _, err := range errors {
-				s.logger.Printf("Cleanup error: %v", err)
-			}
-		} else {
-			s.logger.Println("All resources cleaned up successfully")
-		}
+// drain marks the server as draining and waits out the drain period
+func (s *Server) drain(ctx context.Context) {
+	s.draining.Store(true)
+
+	period := s.config.Server.DrainPeriod
+	if period <= 0 {
+		return
+	}
+
+	s.logger.Printf("Draining for %v before closing the listener...", period)
+	timer := time.NewTimer(period)
+	defer timer.Stop()
 
-		// Close resource manager
-		s.resourceManager.Close()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
 	}
+}
 
-	return nil
+// IsDraining reports whether the server is draining before shutdown
+func (s *Server) IsDraining() bool {
+	return s.draining.Load()
 }
 
 // WaitForShutdown waits for shutdown signals and gracefully shuts down the server
@@ -247,11 +656,8 @@ func (s *Server) WaitForShutdown() {
 	sig := <-sigChan
 	s.logger.Printf("Received signal: %v", sig)
 
-	// Create context for shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	if err := s.Stop(ctx); err != nil {
+	// Stop bounds the drain period and the shutdown timeout itself
+	if err := s.Stop(context.Background()); err != nil {
 		s.logger.Printf("Graceful shutdown failed: %v", err)
 		os.Exit(1)
 	}
@@ -279,6 +685,21 @@ func (s *Server) SetupRoutes() {
 	s.setupRoutes()
 }
 
+// SetPanicHandler replaces the panic handler used by the HTTP handlers and
+// the collector, and exports its metrics in place of the previous one
+func (s *Server) SetPanicHandler(ph *recovery.PanicHandler) error {
+	if s.panicHandler != nil {
+		s.registry.Unregister(s.panicHandler)
+	}
+	if err := s.registry.Register(ph); err != nil {
+		return fmt.Errorf("failed to register panic metrics: %w", err)
+	}
+	s.panicHandler = ph
+	s.collector.SetPanicHandler(ph)
+	s.supervisor.SetPanicHandler(ph)
+	return nil
+}
+
 // SetVersion sets the server version
 func SetVersion(v string) {
 	version = v
@@ -290,19 +711,20 @@ func (s *Server) registerResources() {
 		return
 	}
 
-	// Register HTTP server as a resource
-	s.resourceManager.RegisterResource(
+	// Register HTTP server as a resource; it is drained first, before the
+	// listener and collector it uses
+	s.resourceManager.RegisterResourceContext(
 		"http-server",
 		resources.ResourceTypeNetwork,
 		fmt.Sprintf("HTTP server on %s", s.config.GetAddress()),
-		func() error {
+		func(ctx context.Context) error {
 			if s.httpServer != nil {
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				defer cancel()
 				return s.httpServer.Shutdown(ctx)
 			}
 			return nil
 		},
+		resources.DependsOn(s.listenerID(), "metrics-collector"),
+		resources.WithPriority(10),
 	)
 
 	// Register metrics collector as a resource
@@ -319,6 +741,16 @@ func (s *Server) registerResources() {
 	s.logger.Println("Registered application resources for cleanup")
 }
 
+// listenerID returns the resource ID of the TCP listener
+func (s *Server) listenerID() string {
+	return fmt.Sprintf("tcp-listener-%s", s.config.GetAddress())
+}
+
+// GetSupervisor returns the supervisor running background workers
+func (s *Server) GetSupervisor() *recovery.Supervisor {
+	return s.supervisor
+}
+
 // GetResourceManager returns the resource manager (for testing)
 func (s *Server) GetResourceManager() *resources.ResourceManager {
 	return s.resourceManager
@@ -2,15 +2,24 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/mount-exporter/mount-exporter/config"
+	"github.com/mount-exporter/mount-exporter/recovery"
+	"github.com/mount-exporter/mount-exporter/resources"
+	"github.com/mount-exporter/mount-exporter/system"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestNewServer(t *testing.T) {
@@ -262,7 +271,7 @@ func TestServer_securityMiddleware(t *testing.T) {
 
 func TestServer_responseWriter(t *testing.T) {
 	recorder := httptest.NewRecorder()
-	rw := &responseWriter{ResponseWriter: recorder}
+	rw := newResponseWriter(recorder)
 
 	// Test default status code
 	if rw.statusCode != http.StatusOK {
@@ -365,4 +374,644 @@ func TestServer_GetAddress(t *testing.T) {
 	if address != expected {
 		t.Errorf("Expected address '%s', got '%s'", expected, address)
 	}
-}
\ No newline at end of file
+}
+func TestServer_recoveryMiddleware(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/test"},
+		Interval:    30 * time.Second,
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	var recovered []recovery.PanicInfo
+	ph := recovery.NewPanicHandler(recovery.PanicRecoveryConfig{
+		Enabled: true,
+		Logger:  log.New(io.Discard, "", 0),
+		Handlers: []recovery.PanicHandlerFunc{
+			func(info recovery.PanicInfo) {
+				recovered = append(recovered, info)
+			},
+		},
+	})
+	if err := server.SetPanicHandler(ph); err != nil {
+		t.Fatalf("Failed to set panic handler: %v", err)
+	}
+
+	handler := server.recoveryMiddleware("/boom", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler exploded")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/boom", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	requestID := resp.Header.Get("X-Request-ID")
+	if requestID == "" {
+		t.Fatal("Expected X-Request-ID header to be set")
+	}
+
+	var body map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if body["request_id"] != requestID {
+		t.Errorf("Expected body request_id %q, got %q", requestID, body["request_id"])
+	}
+
+	if len(recovered) != 1 {
+		t.Fatalf("Expected 1 recovered panic, got %d", len(recovered))
+	}
+	info := recovered[0]
+	if info.Component != "http" || info.Method != http.MethodPost || info.Route != "/boom" || info.RequestID != requestID {
+		t.Errorf("Unexpected panic info: component=%q method=%q route=%q request_id=%q",
+			info.Component, info.Method, info.Route, info.RequestID)
+	}
+
+	if got := testutil.ToFloat64(ph); got != 1 {
+		t.Errorf("Expected 1 recovered panic in metrics, got %v", got)
+	}
+}
+
+func TestServer_recoveryMiddlewareRequestID(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/test"},
+		Interval:    30 * time.Second,
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	handler := server.recoveryMiddleware("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name     string
+		header   string
+		expectID string
+	}{
+		{name: "client supplied", header: "abc-123", expectID: "abc-123"},
+		{name: "invalid replaced", header: "bad id\n", expectID: ""},
+		{name: "missing generated", header: "", expectID: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Request-ID", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			got := w.Result().Header.Get("X-Request-ID")
+			if got == "" {
+				t.Fatal("Expected X-Request-ID header to be set")
+			}
+			if tt.expectID != "" && got != tt.expectID {
+				t.Errorf("Expected request ID %q, got %q", tt.expectID, got)
+			}
+			if tt.expectID == "" && got == tt.header {
+				t.Errorf("Expected a generated request ID, got %q", got)
+			}
+		})
+	}
+}
+
+func TestServer_healthHandlerWorkers(t *testing.T) {
+	cfg := &config.Config{
+		MountPoints: []string{"/test"},
+		Interval:    30 * time.Second,
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	defer server.GetSupervisor().Stop(context.Background())
+
+	server.GetSupervisor().Go(recovery.WorkerSpec{
+		Name:   "broken",
+		Policy: recovery.RestartNever,
+		Run: func(ctx context.Context) error {
+			return fmt.Errorf("broken worker")
+		},
+	})
+
+	deadline := time.Now().Add(time.Second)
+	for server.GetSupervisor().Healthy() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	w := httptest.NewRecorder()
+	server.healthHandler(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	var body healthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+
+	if body.Status != "unhealthy" {
+		t.Errorf("Expected unhealthy status, got %s", body.Status)
+	}
+
+	states := make(map[string]string)
+	for _, worker := range body.Workers {
+		states[worker.Name] = worker.State
+	}
+	if states["resource-gc"] != "running" {
+		t.Errorf("Expected resource-gc worker to be running, got %q", states["resource-gc"])
+	}
+	if states["broken"] != "failed" {
+		t.Errorf("Expected broken worker to be failed, got %q", states["broken"])
+	}
+}
+
+func TestServer_StartStop(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host: "127.0.0.1",
+			Port: 0,
+			Path: "/metrics",
+		},
+		MountPoints: []string{"/test"},
+		Interval:    30 * time.Second,
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// The HTTP server, listener and workers are each torn down exactly once,
+	// so the listener already closed by the HTTP server is not an error
+	if err := server.Stop(ctx); err != nil {
+		t.Fatalf("Expected clean shutdown, got %v", err)
+	}
+
+	if resources := server.GetResourceManager().ListResources(); len(resources) != 0 {
+		t.Errorf("Expected all resources to be cleaned up, got %d", len(resources))
+	}
+
+	for _, worker := range server.GetSupervisor().Status() {
+		if worker.State != "stopped" {
+			t.Errorf("Expected worker %s to be stopped, got %s", worker.Name, worker.State)
+		}
+	}
+}
+
+func TestServer_readyHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		limits         config.LimitsConfig
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "within limits",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"status":"ready"`,
+		},
+		{
+			name:           "hard limit exceeded",
+			limits:         config.LimitsConfig{MaxGoroutines: 1},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "resource hard limit exceeded: goroutines",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				MountPoints: []string{"/test"},
+				Interval:    30 * time.Second,
+				Limits:      tt.limits,
+			}
+
+			server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/ready", nil)
+			w := httptest.NewRecorder()
+			server.readyHandler(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			if !strings.Contains(string(body), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got %q", tt.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestServer_configSchemaHandler(t *testing.T) {
+	cfg := &config.Config{
+		Server:      config.ServerConfig{Host: "127.0.0.1", Port: 8080, Path: "/metrics"},
+		MountPoints: []string{"/test"},
+		Interval:    30 * time.Second,
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	server.setupRoutes()
+	handler := server.httpServer.Handler
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/config/schema", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/schema+json" {
+		t.Errorf("Expected schema content type, got %q", ct)
+	}
+
+	var schema map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+		t.Fatalf("Failed to decode schema: %v", err)
+	}
+	if _, ok := schema["properties"].(map[string]interface{})["mount_points"]; !ok {
+		t.Error("Expected mount_points in the schema")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/config/schema", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d for POST, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
+func TestServer_SetupRoutesConfiguredTimeouts(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:           "127.0.0.1",
+			Port:           8080,
+			Path:           "/metrics",
+			ReadTimeout:    5 * time.Second,
+			WriteTimeout:   10 * time.Second,
+			IdleTimeout:    15 * time.Second,
+			MaxHeaderBytes: 4096,
+		},
+		MountPoints: []string{"/test"},
+		Interval:    30 * time.Second,
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	server.setupRoutes()
+
+	hs := server.GetHTTPServer()
+	if hs.ReadTimeout != 5*time.Second || hs.WriteTimeout != 10*time.Second || hs.IdleTimeout != 15*time.Second {
+		t.Errorf("Unexpected timeouts: read %v, write %v, idle %v", hs.ReadTimeout, hs.WriteTimeout, hs.IdleTimeout)
+	}
+	if hs.MaxHeaderBytes != 4096 {
+		t.Errorf("Expected max header bytes 4096, got %d", hs.MaxHeaderBytes)
+	}
+}
+
+func TestServer_scrapeLimitMiddleware(t *testing.T) {
+	cfg := &config.Config{
+		Server:      config.ServerConfig{MaxConcurrentScrapes: 1},
+		MountPoints: []string{"/test"},
+		Interval:    30 * time.Second,
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := server.scrapeLimitMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Hold the only slot with a slow scrape
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		done <- w.Code
+	}()
+	<-entered
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", got)
+	}
+	if got := testutil.ToFloat64(server.scrapesRejected); got != 1 {
+		t.Errorf("Expected 1 rejected scrape, got %v", got)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusOK {
+		t.Errorf("Expected in-flight scrape to succeed, got %d", code)
+	}
+}
+
+func TestServer_StopDrainsBeforeClosing(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:        "127.0.0.1",
+			Port:        0,
+			Path:        "/metrics",
+			DrainPeriod: 100 * time.Millisecond,
+		},
+		MountPoints: []string{"/test"},
+		Interval:    30 * time.Second,
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+
+	stopped := make(chan error)
+	go func() { stopped <- server.Stop(context.Background()) }()
+
+	// While draining, readiness fails but the listener is still open
+	deadline := time.Now().Add(time.Second)
+	for !server.IsDraining() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	server.readyHandler(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), `"status":"draining"`) {
+		t.Errorf("Expected draining readiness, got %d %s", w.Code, w.Body.String())
+	}
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("Server stopped before the drain period elapsed: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if err := <-stopped; err != nil {
+		t.Errorf("Failed to stop server: %v", err)
+	}
+}
+
+func TestServer_metricsHandlerSelection(t *testing.T) {
+	cfg := &config.Config{
+		Server:      config.ServerConfig{Path: "/metrics"},
+		MountPoints: []string{"/", "/tmp"},
+		MountGroups: map[string][]string{"root": {"/"}},
+		Interval:    5 * time.Second,
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		contains       []string
+		excludes       []string
+	}{
+		{
+			name:           "all mount points",
+			expectedStatus: http.StatusOK,
+			contains:       []string{`mount_point="/"`, `mount_point="/tmp"`, "mount_exporter_resource_goroutines"},
+		},
+		{
+			name:           "single mount",
+			query:          "?mount=/tmp",
+			expectedStatus: http.StatusOK,
+			contains:       []string{`mount_point="/tmp"`},
+			excludes:       []string{`mount_point="/"`, "mount_exporter_resource_goroutines"},
+		},
+		{
+			name:           "group",
+			query:          "?group=root",
+			expectedStatus: http.StatusOK,
+			contains:       []string{`mount_point="/"`},
+			excludes:       []string{`mount_point="/tmp"`},
+		},
+		{
+			name:           "unknown mount",
+			query:          "?mount=/srv",
+			expectedStatus: http.StatusBadRequest,
+			contains:       []string{"mount point is not configured: /srv"},
+		},
+		{
+			name:           "unknown group",
+			query:          "?group=caches",
+			expectedStatus: http.StatusBadRequest,
+			contains:       []string{"mount group is not configured: caches"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			server.metricsHandler(w, httptest.NewRequest(http.MethodGet, "/metrics"+tt.query, nil))
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			body := w.Body.String()
+			for _, s := range tt.contains {
+				if !strings.Contains(body, s) {
+					t.Errorf("Expected body to contain %q", s)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(body, s) {
+					t.Errorf("Expected body not to contain %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_WriteProbeWorker(t *testing.T) {
+	mountPoint := t.TempDir()
+	cfg := &config.Config{
+		Server:      config.ServerConfig{Host: "127.0.0.1", Port: 0, Path: "/metrics"},
+		MountPoints: []string{mountPoint},
+		Interval:    30 * time.Second,
+		WriteProbe: config.WriteProbeConfig{
+			MountPoints: []string{mountPoint},
+			Directory:   ".mount-exporter",
+			Interval:    time.Minute,
+			Timeout:     time.Second,
+		},
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if testutil.CollectAndCount(server.writeProbe, "mount_exporter_write_probe_success") == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// The temporary directory is not a mount point, so it is reported
+	// without being written to
+	expected := fmt.Sprintf(`
+# HELP mount_exporter_write_probe_success Whether the last write probe succeeded (1=success, 0=failure)
+# TYPE mount_exporter_write_probe_success gauge
+mount_exporter_write_probe_success{errno_class="not_mounted",mount_point=%q} 0
+`, mountPoint)
+	if err := testutil.CollectAndCompare(server.writeProbe, strings.NewReader(expected), "mount_exporter_write_probe_success"); err != nil {
+		t.Errorf("Expected write probe result: %v", err)
+	}
+
+	running := false
+	for _, status := range server.GetSupervisor().Status() {
+		if status.Name == "write-probe" && status.State == "running" {
+			running = true
+		}
+	}
+	if !running {
+		t.Errorf("Expected write-probe worker to be running, got %+v", server.GetSupervisor().Status())
+	}
+}
+
+func TestScrapeStackPatterns(t *testing.T) {
+	// A canary FIFO blocks the probe's read in a detached runPending
+	// goroutine, as a hung filesystem would, well after the probe returned
+	mountPoint := t.TempDir()
+	fifo := filepath.Join(mountPoint, "canary")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Skipf("Cannot create FIFO: %v", err)
+	}
+	defer func() {
+		// Opening the write end releases the blocked reader
+		if f, err := os.OpenFile(fifo, os.O_WRONLY, 0); err == nil {
+			f.Close()
+		}
+	}()
+
+	findmnt := system.NewFindmntWrapper(20 * time.Millisecond)
+	samples := findmnt.ProbeLatency(context.Background(), mountPoint, "canary")
+	if err := samples[len(samples)-1].Error; err == nil {
+		t.Fatal("Expected the canary read to time out")
+	}
+
+	sample := func(patterns []string) int {
+		rm := resources.NewResourceManager(resources.ResourceManagerConfig{
+			Logger:            &resourcesLogger{logger: log.New(io.Discard, "", 0)},
+			LeakStackPatterns: patterns,
+		})
+		return rm.Sample().LeakedGoroutines
+	}
+
+	if got := sample([]string{"mount-exporter/system.(*FindmntWrapper)"}); got == 0 {
+		t.Fatal("Expected the blocked probe goroutine to run FindmntWrapper code")
+	}
+	if got := sample(scrapeStackPatterns); got != 0 {
+		t.Errorf("Expected probe goroutines not to count as leaked scrapes, got %d", got)
+	}
+}
+
+func TestServer_LatencyProbeWorker(t *testing.T) {
+	mountPoint := t.TempDir()
+	cfg := &config.Config{
+		Server:      config.ServerConfig{Host: "127.0.0.1", Port: 0, Path: "/metrics"},
+		MountPoints: []string{mountPoint},
+		Interval:    30 * time.Second,
+		LatencyProbe: config.LatencyProbeConfig{
+			MountPoints: []string{mountPoint},
+			Interval:    time.Minute,
+		},
+	}
+
+	server, err := NewServer(cfg, log.New(io.Discard, "", log.LstdFlags))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer server.Stop(context.Background())
+
+	// One histogram each for stat and readdir
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if testutil.CollectAndCount(server.latencyProbe, "mount_exporter_mount_operation_duration_seconds") == 2 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := testutil.CollectAndCount(server.latencyProbe, "mount_exporter_mount_operation_duration_seconds"); got != 2 {
+		t.Errorf("Expected 2 operation histograms, got %d", got)
+	}
+
+	running := false
+	for _, status := range server.GetSupervisor().Status() {
+		if status.Name == "latency-probe" && status.State == "running" {
+			running = true
+		}
+	}
+	if !running {
+		t.Errorf("Expected latency-probe worker to be running, got %+v", server.GetSupervisor().Status())
+	}
+}
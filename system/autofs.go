@@ -0,0 +1,138 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AutofsState describes an autofs-managed mount point
+type AutofsState int
+
+const (
+	// AutofsNone means the mount point is not managed by autofs
+	AutofsNone AutofsState = iota
+	// AutofsTriggerPresent means only the autofs trigger is mounted
+	AutofsTriggerPresent
+	// AutofsMounted means the trigger fired and the real filesystem is mounted
+	AutofsMounted
+	// AutofsTriggerFailed means accessing the path did not mount a filesystem
+	AutofsTriggerFailed
+)
+
+// AutofsStates lists all states, e.g. for exporting one series per state
+var AutofsStates = []AutofsState{AutofsNone, AutofsTriggerPresent, AutofsMounted, AutofsTriggerFailed}
+
+// String returns the string representation of AutofsState
+func (s AutofsState) String() string {
+	switch s {
+	case AutofsNone:
+		return "none"
+	case AutofsTriggerPresent:
+		return "trigger_present"
+	case AutofsMounted:
+		return "mounted"
+	case AutofsTriggerFailed:
+		return "trigger_failed"
+	default:
+		return "unknown"
+	}
+}
+
+// AutofsResult is the result of an autofs check
+type AutofsResult struct {
+	MountPoint string
+	State      AutofsState
+	// FSType and Source describe the underlying filesystem once mounted
+	FSType string
+	Source string
+	Error  error
+}
+
+// CheckAutofs inspects the mount table for an autofs trigger on mountPoint.
+// With trigger set, an untriggered path is accessed to mount the real
+// filesystem, waiting at most timeout. The access cannot be interrupted, so
+// while an earlier access of the same path is still hanging no new one is
+// started and the trigger is reported as failed.
+func (f *FindmntWrapper) CheckAutofs(ctx context.Context, mountPoint string, trigger bool, timeout time.Duration) *AutofsResult {
+	result := &AutofsResult{MountPoint: mountPoint}
+
+	mountinfo := filepath.Join(f.procRoot, "self", "mountinfo")
+	entries, err := ReadMountInfo(mountinfo)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	result.State, result.FSType, result.Source = autofsState(entries, mountPoint)
+	if result.State != AutofsTriggerPresent || !trigger {
+		return result
+	}
+
+	if err := f.triggerAutofs(ctx, mountPoint, timeout); err != nil {
+		result.State = AutofsTriggerFailed
+		result.Error = err
+		return result
+	}
+
+	if entries, err = ReadMountInfo(mountinfo); err != nil {
+		result.Error = err
+		return result
+	}
+
+	result.State, result.FSType, result.Source = autofsState(entries, mountPoint)
+	if result.State != AutofsMounted {
+		result.State = AutofsTriggerFailed
+		result.Error = fmt.Errorf("autofs trigger on %s did not mount a filesystem", mountPoint)
+	}
+	return result
+}
+
+// autofsState classifies the mounts stacked on mountPoint. The real
+// filesystem of a triggered autofs mount is stacked on top of the trigger.
+func autofsState(entries []MountInfo, mountPoint string) (AutofsState, string, string) {
+	var stack []MountInfo
+	for _, entry := range entries {
+		if entry.MountPoint == mountPoint {
+			stack = append(stack, entry)
+		}
+	}
+	if len(stack) == 0 || stack[0].FSType != "autofs" {
+		return AutofsNone, "", ""
+	}
+
+	top := stack[len(stack)-1]
+	if top.FSType == "autofs" {
+		return AutofsTriggerPresent, "", ""
+	}
+	return AutofsMounted, top.FSType, top.Source
+}
+
+// triggerAutofs reads the directory to make the automounter mount it
+func (f *FindmntWrapper) triggerAutofs(ctx context.Context, mountPoint string, timeout time.Duration) error {
+	err := f.runPending(ctx, "autofs:"+mountPoint, timeout, func() error {
+		return readDirEntry(mountPoint)
+	})
+	if err != nil {
+		return fmt.Errorf("autofs trigger on %s failed: %w", mountPoint, err)
+	}
+	return nil
+}
+
+// readDirEntry opens a directory and reads at most one entry
+func readDirEntry(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	if _, err := dir.Readdirnames(1); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newAutofsWrapper returns a wrapper reading the given mountinfo
+func newAutofsWrapper(t *testing.T, mountinfo string) *FindmntWrapper {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "self", "mountinfo"), []byte(mountinfo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFindmntWrapper(time.Second)
+	f.procRoot = root
+	return f
+}
+
+func TestAutofsState_String(t *testing.T) {
+	expected := []string{"none", "trigger_present", "mounted", "trigger_failed"}
+	for i, state := range AutofsStates {
+		if got := state.String(); got != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], got)
+		}
+	}
+	if got := AutofsState(99).String(); got != "unknown" {
+		t.Errorf("Expected unknown, got %s", got)
+	}
+}
+
+func TestFindmntWrapper_CheckAutofs(t *testing.T) {
+	mountinfo := `22 1 8:1 / / rw - ext4 /dev/sda1 rw
+30 22 0:40 / /net/home rw - autofs systemd-1 rw,fd=40,direct
+31 22 0:41 / /net/data rw - autofs systemd-1 rw,fd=41,direct
+32 31 0:50 / /net/data rw - nfs4 nas:/data rw,vers=4.2
+`
+	f := newAutofsWrapper(t, mountinfo)
+	ctx := context.Background()
+
+	tests := []struct {
+		mountPoint string
+		state      AutofsState
+		fsType     string
+	}{
+		{"/", AutofsNone, ""},
+		{"/net/home", AutofsTriggerPresent, ""},
+		{"/net/data", AutofsMounted, "nfs4"},
+	}
+	for _, tt := range tests {
+		result := f.CheckAutofs(ctx, tt.mountPoint, false, time.Second)
+		if result.State != tt.state || result.FSType != tt.fsType || result.Error != nil {
+			t.Errorf("Expected %s state %s (%s), got %+v", tt.mountPoint, tt.state, tt.fsType, result)
+		}
+	}
+}
+
+func TestFindmntWrapper_CheckAutofs_TriggerFailed(t *testing.T) {
+	dir := t.TempDir()
+	mountinfo := "30 22 0:40 / " + dir + " rw - autofs systemd-1 rw,direct\n" +
+		"31 22 0:41 / /net/missing rw - autofs systemd-1 rw,direct\n"
+	f := newAutofsWrapper(t, mountinfo)
+	ctx := context.Background()
+
+	// Accessible, but nothing gets mounted on top of the trigger
+	result := f.CheckAutofs(ctx, dir, true, time.Second)
+	if result.State != AutofsTriggerFailed || result.Error == nil || !strings.Contains(result.Error.Error(), "did not mount") {
+		t.Errorf("Expected trigger failure without a mount, got %+v", result)
+	}
+
+	// The access itself fails
+	result = f.CheckAutofs(ctx, "/net/missing", true, time.Second)
+	if result.State != AutofsTriggerFailed || result.Error == nil {
+		t.Errorf("Expected trigger failure for inaccessible path, got %+v", result)
+	}
+}
+
+func TestFindmntWrapper_CheckAutofs_TriggerTimeout(t *testing.T) {
+	// Opening a FIFO blocks until a writer appears, like a hung automount
+	fifo := filepath.Join(t.TempDir(), "hung")
+	if err := syscall.Mkfifo(fifo, 0o644); err != nil {
+		t.Skipf("Cannot create FIFO: %v", err)
+	}
+	f := newAutofsWrapper(t, "30 22 0:40 / "+fifo+" rw - autofs systemd-1 rw,direct\n")
+	defer func() {
+		// Unblock the pending open
+		if w, err := os.OpenFile(fifo, os.O_WRONLY, 0); err == nil {
+			w.Close()
+		}
+	}()
+
+	result := f.CheckAutofs(context.Background(), fifo, true, 20*time.Millisecond)
+	if result.State != AutofsTriggerFailed || !errors.Is(result.Error, context.DeadlineExceeded) {
+		t.Errorf("Expected trigger timeout, got %+v", result)
+	}
+
+	// The hung access is not repeated
+	result = f.CheckAutofs(context.Background(), fifo, true, 20*time.Millisecond)
+	if result.State != AutofsTriggerFailed || !errors.Is(result.Error, ErrStillPending) {
+		t.Errorf("Expected pending trigger to be reported, got %+v", result)
+	}
+}
@@ -0,0 +1,221 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrNoBlockDevice is returned for mounts that are not backed by a block
+// device, such as network, memory and overlay filesystems
+var ErrNoBlockDevice = errors.New("mount is not backed by a block device")
+
+// sectorSize is the unit of the sector counters in /sys/block/*/stat
+const sectorSize = 512
+
+// nonBlockFSTypes are filesystems that never have a backing block device
+var nonBlockFSTypes = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smb3": true, "9p": true,
+	"ceph": true, "glusterfs": true, "tmpfs": true, "ramfs": true,
+	"overlay": true, "aufs": true, "proc": true, "sysfs": true,
+	"devtmpfs": true, "devpts": true, "cgroup": true, "cgroup2": true,
+	"autofs": true,
+}
+
+// BlockStat holds the counters of a block device stat file, see the
+// kernel's Documentation/block/stat.rst
+type BlockStat struct {
+	ReadsCompleted  uint64
+	ReadSectors     uint64
+	ReadTime        time.Duration
+	WritesCompleted uint64
+	WriteSectors    uint64
+	WriteTime       time.Duration
+	InFlight        uint64
+	IOTime          time.Duration
+	TimeInQueue     time.Duration
+}
+
+// ReadBytes returns the number of bytes read
+func (s BlockStat) ReadBytes() uint64 {
+	return s.ReadSectors * sectorSize
+}
+
+// WrittenBytes returns the number of bytes written
+func (s BlockStat) WrittenBytes() uint64 {
+	return s.WriteSectors * sectorSize
+}
+
+// ParseBlockStat parses the contents of a block device stat file
+func ParseBlockStat(data string) (BlockStat, error) {
+	fields := strings.Fields(data)
+	if len(fields) < 11 {
+		return BlockStat{}, fmt.Errorf("block stat has %d fields, expected at least 11", len(fields))
+	}
+
+	values := make([]uint64, 11)
+	for i := range values {
+		v, err := strconv.ParseUint(fields[i], 10, 64)
+		if err != nil {
+			return BlockStat{}, fmt.Errorf("invalid block stat field %d: %w", i+1, err)
+		}
+		values[i] = v
+	}
+
+	return BlockStat{
+		ReadsCompleted:  values[0],
+		ReadSectors:     values[2],
+		ReadTime:        time.Duration(values[3]) * time.Millisecond,
+		WritesCompleted: values[4],
+		WriteSectors:    values[6],
+		WriteTime:       time.Duration(values[7]) * time.Millisecond,
+		InFlight:        values[8],
+		IOTime:          time.Duration(values[9]) * time.Millisecond,
+		TimeInQueue:     time.Duration(values[10]) * time.Millisecond,
+	}, nil
+}
+
+// BlockDeviceStats are the I/O statistics of the device backing a mount point
+type BlockDeviceStats struct {
+	MountPoint string
+	// Device is the kernel name, e.g. sda1, dm-0 or md0
+	Device string
+	// DMName is the device-mapper name (e.g. an LVM "vg-lv"), if any
+	DMName string
+	Stat   BlockStat
+}
+
+// BlockDeviceReaderConfig configures a BlockDeviceReader; empty roots
+// default to /proc and /sys
+type BlockDeviceReaderConfig struct {
+	ProcRoot string
+	SysRoot  string
+}
+
+// BlockDeviceReader resolves mount points to their backing block devices
+// and reads their I/O statistics
+type BlockDeviceReader struct {
+	procRoot string
+	sysRoot  string
+}
+
+// NewBlockDeviceReader creates a new BlockDeviceReader
+func NewBlockDeviceReader(cfg BlockDeviceReaderConfig) *BlockDeviceReader {
+	if cfg.ProcRoot == "" {
+		cfg.ProcRoot = "/proc"
+	}
+	if cfg.SysRoot == "" {
+		cfg.SysRoot = "/sys"
+	}
+	return &BlockDeviceReader{procRoot: cfg.ProcRoot, sysRoot: cfg.SysRoot}
+}
+
+// Stats returns the block device statistics of the given mount points.
+// Mount points that are not mounted or not backed by a block device are
+// skipped; other per-mount failures are returned joined.
+func (r *BlockDeviceReader) Stats(mountPoints []string) ([]BlockDeviceStats, error) {
+	entries, err := ReadMountInfo(filepath.Join(r.procRoot, "self", "mountinfo"))
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []BlockDeviceStats
+	var errs []error
+	for _, mountPoint := range mountPoints {
+		entry, ok := FindMountInfo(entries, mountPoint)
+		if !ok {
+			continue
+		}
+
+		stat, err := r.statMount(entry)
+		if errors.Is(err, ErrNoBlockDevice) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", mountPoint, err))
+			continue
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, errors.Join(errs...)
+}
+
+// statMount reads the statistics of the device backing a mountinfo entry
+func (r *BlockDeviceReader) statMount(entry MountInfo) (BlockDeviceStats, error) {
+	dir, err := r.Resolve(entry)
+	if err != nil {
+		return BlockDeviceStats{}, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return BlockDeviceStats{}, fmt.Errorf("failed to read block device stat: %w", err)
+	}
+	stat, err := ParseBlockStat(string(data))
+	if err != nil {
+		return BlockDeviceStats{}, err
+	}
+
+	result := BlockDeviceStats{
+		MountPoint: entry.MountPoint,
+		Device:     filepath.Base(dir),
+		Stat:       stat,
+	}
+	if name, err := os.ReadFile(filepath.Join(dir, "dm", "name")); err == nil {
+		result.DMName = strings.TrimSpace(string(name))
+	}
+	return result, nil
+}
+
+// Resolve returns the sysfs directory of the block device backing entry.
+// The device is found through its major:minor number, so /dev/mapper,
+// /dev/<vg>/<lv> and /dev/md/<name> aliases all resolve to the same dm-N or
+// mdN device. Filesystems reporting an anonymous device (major 0, e.g.
+// btrfs) are resolved through their source device node instead.
+func (r *BlockDeviceReader) Resolve(entry MountInfo) (string, error) {
+	if nonBlockFSTypes[entry.FSType] || strings.HasPrefix(entry.FSType, "fuse") {
+		return "", fmt.Errorf("%s filesystem: %w", entry.FSType, ErrNoBlockDevice)
+	}
+
+	major, minor := entry.Major, entry.Minor
+	if major == 0 {
+		var ok bool
+		if major, minor, ok = blockDeviceNumber(entry.Source); !ok {
+			return "", fmt.Errorf("source %q: %w", entry.Source, ErrNoBlockDevice)
+		}
+	}
+
+	link := filepath.Join(r.sysRoot, "dev", "block", fmt.Sprintf("%d:%d", major, minor))
+	dir, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve block device %d:%d: %w", major, minor, err)
+	}
+	return dir, nil
+}
+
+// blockDeviceNumber returns the major and minor number of a block device node
+func blockDeviceNumber(path string) (int, int, bool) {
+	if !filepath.IsAbs(path) {
+		return 0, 0, false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeDevice == 0 || info.Mode()&os.ModeCharDevice != 0 {
+		return 0, 0, false
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+
+	dev := uint64(st.Rdev)
+	major := (dev>>8)&0xfff | (dev>>32)&^0xfff
+	minor := dev&0xff | (dev>>12)&^0xff
+	return int(major), int(minor), true
+}
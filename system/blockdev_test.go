@@ -0,0 +1,121 @@
+package system
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newFakeBlockRoots creates proc and sys trees with a partition, an LVM
+// logical volume and mounts without a block device
+func newFakeBlockRoots(t *testing.T) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	procRoot := filepath.Join(root, "proc")
+	sysRoot := filepath.Join(root, "sys")
+
+	mustWrite := func(path, data string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mustWrite(filepath.Join(procRoot, "self", "mountinfo"), ""+
+		"36 22 8:17 / /data rw,relatime - xfs /dev/sdb1 rw\n"+
+		"37 22 253:0 / /srv rw,relatime - ext4 /dev/mapper/vg-srv rw\n"+
+		"38 22 0:50 / /mnt/nfs rw - nfs4 nas:/export rw\n"+
+		"39 22 0:51 / /tmp rw - tmpfs tmpfs rw\n"+
+		"40 22 0:52 / /var/lib/docker/overlay2/x/merged rw - overlay overlay rw\n")
+
+	partition := filepath.Join(sysRoot, "devices", "pci0000:00", "block", "sdb", "sdb1")
+	mustWrite(filepath.Join(partition, "stat"), "  100 5 2048 30 200 10 4096 60 1 80 90 0 0 0 0\n")
+	dm := filepath.Join(sysRoot, "devices", "virtual", "block", "dm-0")
+	mustWrite(filepath.Join(dm, "stat"), "1 0 8 1 2 0 16 2 0 3 3\n")
+	mustWrite(filepath.Join(dm, "dm", "name"), "vg-srv\n")
+
+	links := filepath.Join(sysRoot, "dev", "block")
+	if err := os.MkdirAll(links, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.Symlink(partition, filepath.Join(links, "8:17"))
+	os.Symlink(dm, filepath.Join(links, "253:0"))
+
+	return procRoot, sysRoot
+}
+
+func TestParseBlockStat(t *testing.T) {
+	stat, err := ParseBlockStat("  100 5 2048 30 200 10 4096 60 1 80 90 0 0 0 0\n")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := BlockStat{
+		ReadsCompleted:  100,
+		ReadSectors:     2048,
+		ReadTime:        30 * time.Millisecond,
+		WritesCompleted: 200,
+		WriteSectors:    4096,
+		WriteTime:       60 * time.Millisecond,
+		InFlight:        1,
+		IOTime:          80 * time.Millisecond,
+		TimeInQueue:     90 * time.Millisecond,
+	}
+	if stat != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stat)
+	}
+	if stat.ReadBytes() != 2048*512 || stat.WrittenBytes() != 4096*512 {
+		t.Errorf("Unexpected byte counts: %d read, %d written", stat.ReadBytes(), stat.WrittenBytes())
+	}
+
+	if _, err := ParseBlockStat("1 2 3"); err == nil {
+		t.Error("Expected error for short stat")
+	}
+}
+
+func TestBlockDeviceReader_Stats(t *testing.T) {
+	procRoot, sysRoot := newFakeBlockRoots(t)
+	reader := NewBlockDeviceReader(BlockDeviceReaderConfig{ProcRoot: procRoot, SysRoot: sysRoot})
+
+	stats, err := reader.Stats([]string{"/data", "/srv", "/mnt/nfs", "/tmp", "/var/lib/docker/overlay2/x/merged", "/not-mounted"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("Expected stats for /data and /srv only, got %+v", stats)
+	}
+
+	if stats[0].MountPoint != "/data" || stats[0].Device != "sdb1" || stats[0].DMName != "" || stats[0].Stat.ReadsCompleted != 100 {
+		t.Errorf("Unexpected partition stats: %+v", stats[0])
+	}
+	if stats[1].MountPoint != "/srv" || stats[1].Device != "dm-0" || stats[1].DMName != "vg-srv" || stats[1].Stat.WritesCompleted != 2 {
+		t.Errorf("Unexpected logical volume stats: %+v", stats[1])
+	}
+}
+
+func TestBlockDeviceReader_Resolve(t *testing.T) {
+	procRoot, sysRoot := newFakeBlockRoots(t)
+	reader := NewBlockDeviceReader(BlockDeviceReaderConfig{ProcRoot: procRoot, SysRoot: sysRoot})
+
+	for _, entry := range []MountInfo{
+		{Major: 0, Minor: 50, FSType: "nfs4", Source: "nas:/export"},
+		{Major: 0, Minor: 51, FSType: "tmpfs", Source: "tmpfs"},
+		{Major: 0, Minor: 52, FSType: "overlay", Source: "overlay"},
+		{Major: 0, Minor: 53, FSType: "fuse.sshfs", Source: "host:/"},
+		{Major: 0, Minor: 54, FSType: "btrfs", Source: "/nonexistent/device"},
+	} {
+		if _, err := reader.Resolve(entry); !errors.Is(err, ErrNoBlockDevice) {
+			t.Errorf("Expected ErrNoBlockDevice for %s, got %v", entry.FSType, err)
+		}
+	}
+
+	// A device missing from sysfs is an error, not skipped
+	if _, err := reader.Resolve(MountInfo{Major: 8, Minor: 99, FSType: "ext4"}); err == nil || errors.Is(err, ErrNoBlockDevice) {
+		t.Errorf("Expected resolution error for unknown device, got %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package system
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// MountKind classifies a mount by how it was created
+type MountKind int
+
+const (
+	MountKindUnknown MountKind = iota
+	// MountKindDevice is a filesystem mounted from a block device
+	MountKindDevice
+	// MountKindBind is a bind mount of a directory or of another mount
+	MountKindBind
+	// MountKindOverlay is an overlay filesystem, e.g. a container root
+	MountKindOverlay
+	// MountKindTmpfs is a memory-backed filesystem
+	MountKindTmpfs
+	// MountKindNetwork is a network filesystem such as NFS or CIFS
+	MountKindNetwork
+	// MountKindFuse is a FUSE filesystem
+	MountKindFuse
+)
+
+// String returns the string representation of MountKind
+func (k MountKind) String() string {
+	switch k {
+	case MountKindDevice:
+		return "device"
+	case MountKindBind:
+		return "bind"
+	case MountKindOverlay:
+		return "overlay"
+	case MountKindTmpfs:
+		return "tmpfs"
+	case MountKindNetwork:
+		return "network"
+	case MountKindFuse:
+		return "fuse"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseMountKind parses the string representation of a MountKind
+func ParseMountKind(s string) (MountKind, error) {
+	for k := MountKindDevice; k <= MountKindFuse; k++ {
+		if k.String() == s {
+			return k, nil
+		}
+	}
+	return MountKindUnknown, fmt.Errorf("unknown mount kind %q", s)
+}
+
+// MountKindNames returns the names accepted by ParseMountKind
+func MountKindNames() []string {
+	var names []string
+	for k := MountKindDevice; k <= MountKindFuse; k++ {
+		names = append(names, k.String())
+	}
+	return names
+}
+
+// networkFSTypes are filesystems classified as network mounts
+var networkFSTypes = map[string]bool{
+	"nfs": true, "nfs4": true, "cifs": true, "smb3": true, "9p": true,
+	"ceph": true, "glusterfs": true, "lustre": true, "afs": true,
+}
+
+// containerRuntimeDirs are where container runtimes keep their mounts
+var containerRuntimeDirs = []string{
+	"/var/lib/docker",
+	"/run/docker",
+	"/var/lib/containerd",
+	"/run/containerd",
+	"/var/lib/containers",
+	"/run/containers",
+	"/run/k3s/containerd",
+}
+
+// MountClass is the classification of a mount table entry
+type MountClass struct {
+	Kind MountKind
+	// ContainerRuntime is set for mounts managed by a container runtime
+	ContainerRuntime bool
+}
+
+// OverlayDirs are the layers of an overlay mount
+type OverlayDirs struct {
+	Lower []string
+	Upper string
+	Work  string
+}
+
+// OverlayDirs returns the lower, upper and work directories of an overlay
+// mount from its super options
+func (m MountInfo) OverlayDirs() (OverlayDirs, bool) {
+	if m.FSType != "overlay" {
+		return OverlayDirs{}, false
+	}
+
+	var dirs OverlayDirs
+	for _, opt := range strings.Split(m.SuperOptions, ",") {
+		key, value, _ := strings.Cut(opt, "=")
+		switch key {
+		case "lowerdir":
+			dirs.Lower = strings.Split(value, ":")
+		case "upperdir":
+			dirs.Upper = value
+		case "workdir":
+			dirs.Work = value
+		}
+	}
+	return dirs, true
+}
+
+// ClassifyMountTable classifies every entry of a mount table. A mount is a
+// bind mount when its root is a subdirectory of the filesystem (or of the
+// btrfs subvolume it mounts), or when the same filesystem subtree is already
+// mounted earlier in the table.
+func ClassifyMountTable(entries []MountInfo) []MountClass {
+	classes := make([]MountClass, len(entries))
+
+	// Overlays laid out the way runtimes do it are container roots, and
+	// everything mounted below them belongs to the runtime as well
+	var runtimeRoots []string
+	for _, entry := range entries {
+		if isRuntimeOverlay(entry) {
+			runtimeRoots = append(runtimeRoots, entry.MountPoint)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, entry := range entries {
+		device := fmt.Sprintf("%d:%d:%s", entry.Major, entry.Minor, entry.Root)
+		bind := entry.Root != entry.subvolume() || seen[device]
+		seen[device] = true
+
+		classes[i] = MountClass{
+			Kind:             classifyMount(entry, bind),
+			ContainerRuntime: isContainerRuntimePath(entry.MountPoint, runtimeRoots),
+		}
+	}
+	return classes
+}
+
+// isRuntimeOverlay reports whether an overlay is mounted next to its upper
+// and work directories, e.g. on <id>/merged with <id>/diff and <id>/work
+// as docker does. Overlays set up by hand usually keep their layers
+// elsewhere.
+func isRuntimeOverlay(entry MountInfo) bool {
+	dirs, ok := entry.OverlayDirs()
+	if !ok || dirs.Upper == "" || dirs.Work == "" {
+		return false
+	}
+	parent := filepath.Dir(entry.MountPoint)
+	return filepath.Dir(dirs.Upper) == parent && filepath.Dir(dirs.Work) == parent
+}
+
+// subvolume returns the root of the filesystem tree a mount was made from:
+// the subvolume for btrfs, "/" otherwise
+func (m MountInfo) subvolume() string {
+	if m.FSType != "btrfs" {
+		return "/"
+	}
+	for _, opt := range strings.Split(m.SuperOptions, ",") {
+		if value, ok := strings.CutPrefix(opt, "subvol="); ok {
+			return value
+		}
+	}
+	return "/"
+}
+
+// ClassifyMountPoint returns the class of the visible (last) mount on
+// mountPoint
+func ClassifyMountPoint(entries []MountInfo, mountPoint string) (MountClass, bool) {
+	classes := ClassifyMountTable(entries)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].MountPoint == mountPoint {
+			return classes[i], true
+		}
+	}
+	return MountClass{}, false
+}
+
+// MountClasses classifies the mounts of the exporter's own mount namespace
+// by mount point
+func (f *FindmntWrapper) MountClasses() (map[string]MountClass, error) {
+	entries, err := ReadMountInfo(filepath.Join(f.procRoot, "self", "mountinfo"))
+	if err != nil {
+		return nil, err
+	}
+
+	classes := make(map[string]MountClass, len(entries))
+	for i, class := range ClassifyMountTable(entries) {
+		// Later entries are stacked on top of earlier ones
+		classes[entries[i].MountPoint] = class
+	}
+	return classes, nil
+}
+
+// classifyMount returns the kind of a single entry
+func classifyMount(entry MountInfo, bind bool) MountKind {
+	switch {
+	case bind:
+		return MountKindBind
+	case entry.FSType == "overlay" || entry.FSType == "aufs":
+		return MountKindOverlay
+	case entry.FSType == "tmpfs" || entry.FSType == "ramfs":
+		return MountKindTmpfs
+	case networkFSTypes[entry.FSType]:
+		return MountKindNetwork
+	case entry.FSType == "fuse" || strings.HasPrefix(entry.FSType, "fuse."):
+		return MountKindFuse
+	case entry.Major != 0 || strings.HasPrefix(entry.Source, "/dev/"):
+		return MountKindDevice
+	default:
+		return MountKindUnknown
+	}
+}
+
+// isContainerRuntimePath reports whether path lies in a container runtime
+// directory or in a container root mounted elsewhere
+func isContainerRuntimePath(path string, runtimeRoots []string) bool {
+	for _, dir := range containerRuntimeDirs {
+		if isWithin(path, dir) {
+			return true
+		}
+	}
+	for _, dir := range runtimeRoots {
+		if isWithin(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin reports whether path is dir or below it
+func isWithin(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+// MountFilter excludes classes of mounts, e.g. during discovery
+type MountFilter struct {
+	Kinds            []MountKind
+	ContainerRuntime bool
+}
+
+// Excludes reports whether a mount of the given class is filtered out
+func (f MountFilter) Excludes(class MountClass) bool {
+	if f.ContainerRuntime && class.ContainerRuntime {
+		return true
+	}
+	for _, kind := range f.Kinds {
+		if class.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const classifyMountInfo = `22 1 8:1 / / rw - ext4 /dev/sda1 rw
+23 22 0:21 / /proc rw - proc proc rw
+24 22 0:25 / /run rw - tmpfs tmpfs rw,size=812M
+25 22 8:1 /srv/data /data rw - ext4 /dev/sda1 rw
+26 22 8:17 / /mnt/disk rw - xfs /dev/sdb rw
+27 22 8:17 / /mnt/disk-again rw - xfs /dev/sdb rw
+28 22 0:50 / /mnt/nfs rw - nfs4 nas:/export rw,vers=4.2
+29 22 0:51 / /mnt/sshfs rw - fuse.sshfs user@host:/ rw
+30 22 0:52 / /var/lib/docker/overlay2/abc/merged rw - overlay overlay rw,lowerdir=/var/lib/docker/overlay2/l/X:/var/lib/docker/overlay2/l/Y,upperdir=/var/lib/docker/overlay2/abc/diff,workdir=/var/lib/docker/overlay2/abc/work
+31 22 0:53 / /srv/containers/ctr/rootfs rw - overlay overlay rw,lowerdir=/srv/containers/layers/1,upperdir=/srv/containers/ctr/upper,workdir=/srv/containers/ctr/work
+32 22 0:54 / /mnt/btrfs rw - btrfs /dev/sdc1 rw
+33 22 0:54 /@home /home rw - btrfs /dev/sdc1 rw,subvolid=257,subvol=/@home
+34 22 0:54 /@home/shared /srv/shared rw - btrfs /dev/sdc1 rw,subvolid=257,subvol=/@home
+35 22 0:54 /@home /home-again rw - btrfs /dev/sdc1 rw,subvolid=257,subvol=/@home
+36 31 0:56 / /srv/containers/ctr/rootfs/run rw - tmpfs tmpfs rw
+37 22 0:55 / /mnt/union rw - overlay overlay rw,lowerdir=/srv/lower,upperdir=/srv/upper,workdir=/srv/work
+38 22 8:49 / /srv/backup rw - ext4 /dev/sdd1 rw
+`
+
+func TestMountKind_String(t *testing.T) {
+	for k := MountKindUnknown; k <= MountKindFuse; k++ {
+		parsed, err := ParseMountKind(k.String())
+		if k == MountKindUnknown {
+			if err == nil {
+				t.Errorf("Expected %q to be rejected", k.String())
+			}
+			continue
+		}
+		if err != nil || parsed != k {
+			t.Errorf("ParseMountKind(%q) = %v, %v", k.String(), parsed, err)
+		}
+	}
+}
+
+func TestMountInfo_OverlayDirs(t *testing.T) {
+	entries, err := ParseMountInfo(strings.NewReader(classifyMountInfo))
+	if err != nil {
+		t.Fatalf("Failed to parse mountinfo: %v", err)
+	}
+
+	dirs, ok := entries[8].OverlayDirs()
+	if !ok {
+		t.Fatal("Expected overlay directories")
+	}
+	if len(dirs.Lower) != 2 || dirs.Lower[1] != "/var/lib/docker/overlay2/l/Y" ||
+		dirs.Upper != "/var/lib/docker/overlay2/abc/diff" || dirs.Work != "/var/lib/docker/overlay2/abc/work" {
+		t.Errorf("Unexpected overlay directories: %+v", dirs)
+	}
+
+	if _, ok := entries[0].OverlayDirs(); ok {
+		t.Error("Expected no overlay directories for ext4")
+	}
+}
+
+func TestClassifyMountTable(t *testing.T) {
+	entries, err := ParseMountInfo(strings.NewReader(classifyMountInfo))
+	if err != nil {
+		t.Fatalf("Failed to parse mountinfo: %v", err)
+	}
+
+	expected := map[string]MountClass{
+		"/":                                   {Kind: MountKindDevice},
+		"/proc":                               {Kind: MountKindUnknown},
+		"/run":                                {Kind: MountKindTmpfs},
+		"/data":                               {Kind: MountKindBind},
+		"/mnt/disk":                           {Kind: MountKindDevice},
+		"/mnt/disk-again":                     {Kind: MountKindBind},
+		"/mnt/nfs":                            {Kind: MountKindNetwork},
+		"/mnt/sshfs":                          {Kind: MountKindFuse},
+		"/var/lib/docker/overlay2/abc/merged": {Kind: MountKindOverlay, ContainerRuntime: true},
+		"/srv/containers/ctr/rootfs":          {Kind: MountKindOverlay, ContainerRuntime: true},
+		"/mnt/btrfs":                          {Kind: MountKindDevice},
+		"/home":                               {Kind: MountKindDevice},
+		"/srv/shared":                         {Kind: MountKindBind},
+		"/home-again":                         {Kind: MountKindBind},
+		"/srv/containers/ctr/rootfs/run":      {Kind: MountKindTmpfs, ContainerRuntime: true},
+		// Layers next to unrelated trees do not make them runtime paths
+		"/mnt/union":  {Kind: MountKindOverlay},
+		"/srv/backup": {Kind: MountKindDevice},
+	}
+
+	classes := ClassifyMountTable(entries)
+	for i, entry := range entries {
+		if classes[i] != expected[entry.MountPoint] {
+			t.Errorf("%s: expected %+v, got %+v", entry.MountPoint, expected[entry.MountPoint], classes[i])
+		}
+	}
+}
+
+func TestClassifyMountPoint(t *testing.T) {
+	entries, err := ParseMountInfo(strings.NewReader(`22 1 8:1 / / rw - ext4 /dev/sda1 rw
+23 22 0:25 / /data rw - tmpfs tmpfs rw
+24 22 8:1 /srv /data rw - ext4 /dev/sda1 rw
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse mountinfo: %v", err)
+	}
+
+	// The bind mount stacked on top is the visible one
+	class, ok := ClassifyMountPoint(entries, "/data")
+	if !ok || class.Kind != MountKindBind {
+		t.Errorf("Expected a bind mount, got %+v, %v", class, ok)
+	}
+
+	if _, ok := ClassifyMountPoint(entries, "/missing"); ok {
+		t.Error("Expected no class for an unmounted path")
+	}
+}
+
+func TestFindmntWrapper_MountClasses(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "self", "mountinfo"), []byte(classifyMountInfo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewFindmntWrapper(time.Second)
+	f.procRoot = root
+
+	classes, err := f.MountClasses()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if classes["/mnt/nfs"].Kind != MountKindNetwork || classes["/data"].Kind != MountKindBind {
+		t.Errorf("Unexpected classes: %+v", classes)
+	}
+
+	f.procRoot = filepath.Join(root, "missing")
+	if _, err := f.MountClasses(); err == nil {
+		t.Error("Expected an error for a missing mount table")
+	}
+}
+
+func TestMountFilter_Excludes(t *testing.T) {
+	filter := MountFilter{Kinds: []MountKind{MountKindTmpfs}, ContainerRuntime: true}
+
+	tests := []struct {
+		class    MountClass
+		excluded bool
+	}{
+		{MountClass{Kind: MountKindTmpfs}, true},
+		{MountClass{Kind: MountKindOverlay, ContainerRuntime: true}, true},
+		{MountClass{Kind: MountKindBind, ContainerRuntime: true}, true},
+		{MountClass{Kind: MountKindDevice}, false},
+		{MountClass{Kind: MountKindBind}, false},
+	}
+
+	for _, tt := range tests {
+		if got := filter.Excludes(tt.class); got != tt.excluded {
+			t.Errorf("Excludes(%+v) = %v, expected %v", tt.class, got, tt.excluded)
+		}
+	}
+
+	if (MountFilter{}).Excludes(MountClass{Kind: MountKindTmpfs, ContainerRuntime: true}) {
+		t.Error("Expected an empty filter to exclude nothing")
+	}
+}
@@ -0,0 +1,207 @@
+package system
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ignoredMountOptions are fstab options that never show up in mountinfo,
+// either because they only steer mount(8) and systemd or because they are
+// kernel defaults
+var ignoredMountOptions = map[string]bool{
+	"defaults": true, "auto": true, "noauto": true, "user": true, "users": true,
+	"nouser": true, "owner": true, "group": true, "nofail": true, "_netdev": true,
+	"exec": true, "suid": true, "dev": true, "async": true, "atime": true,
+}
+
+// DriftDetectorConfig holds the configuration for a DriftDetector
+type DriftDetectorConfig struct {
+	// FstabPath is the fstab to read; empty skips fstab
+	FstabPath string
+	// UnitDirs are searched for systemd .mount units; empty skips units
+	UnitDirs []string
+	// ProcRoot is where /proc is mounted (default "/proc")
+	ProcRoot string
+	// DevRoot is where /dev is mounted (default "/dev")
+	DevRoot string
+}
+
+// DriftDetector compares declared mounts against what is mounted
+type DriftDetector struct {
+	config DriftDetectorConfig
+}
+
+// DriftResult describes how one declared mount compares to the mount table
+type DriftResult struct {
+	Declared     DeclaredMount
+	Mounted      bool
+	SourceMatch  bool
+	FSTypeMatch  bool
+	OptionsMatch bool
+	// MissingOptions are declared options absent from the mount
+	MissingOptions []string
+}
+
+// DriftReport is the result of a drift check
+type DriftReport struct {
+	Results  []DriftResult
+	declared map[string]bool
+}
+
+// IsDeclared reports whether mountPoint is declared in fstab or a unit
+func (r *DriftReport) IsDeclared(mountPoint string) bool {
+	return r.declared[mountPoint]
+}
+
+// NewDriftDetector creates a new DriftDetector
+func NewDriftDetector(config DriftDetectorConfig) *DriftDetector {
+	if config.ProcRoot == "" {
+		config.ProcRoot = "/proc"
+	}
+	if config.DevRoot == "" {
+		config.DevRoot = "/dev"
+	}
+
+	return &DriftDetector{config: config}
+}
+
+// Check reads the declared mounts and the current mount table and compares them
+func (d *DriftDetector) Check() (*DriftReport, error) {
+	var declared []DeclaredMount
+
+	if d.config.FstabPath != "" {
+		mounts, err := ReadFstab(d.config.FstabPath)
+		if err != nil {
+			return nil, err
+		}
+		declared = append(declared, mounts...)
+	}
+
+	if len(d.config.UnitDirs) > 0 {
+		mounts, err := ReadMountUnits(d.config.UnitDirs)
+		if err != nil {
+			return nil, err
+		}
+		declared = append(declared, mounts...)
+	}
+
+	mounted, err := ReadMountInfo(filepath.Join(d.config.ProcRoot, "self", "mountinfo"))
+	if err != nil {
+		return nil, err
+	}
+
+	return d.compare(declared, mounted), nil
+}
+
+// compare builds a report for the declared mounts. When a mount point is
+// declared twice by the same origin, the last declaration wins.
+func (d *DriftDetector) compare(declared []DeclaredMount, mounted []MountInfo) *DriftReport {
+	report := &DriftReport{declared: make(map[string]bool)}
+
+	index := make(map[string]int)
+	for _, decl := range declared {
+		key := decl.Origin + "\x00" + decl.MountPoint
+		result := d.compareOne(decl, mounted)
+		if i, ok := index[key]; ok {
+			report.Results[i] = result
+			continue
+		}
+		index[key] = len(report.Results)
+		report.Results = append(report.Results, result)
+		report.declared[decl.MountPoint] = true
+	}
+
+	return report
+}
+
+// compareOne compares a declared mount against the mount table
+func (d *DriftDetector) compareOne(decl DeclaredMount, mounted []MountInfo) DriftResult {
+	result := DriftResult{Declared: decl}
+
+	entry, ok := FindMountInfo(mounted, decl.MountPoint)
+	if !ok {
+		return result
+	}
+
+	result.Mounted = true
+	result.SourceMatch = d.sameSource(decl.Source, entry.Source)
+	result.FSTypeMatch = sameFSType(decl.FSType, entry.FSType)
+	result.MissingOptions = missingOptions(decl.Options, entry)
+	result.OptionsMatch = len(result.MissingOptions) == 0
+
+	return result
+}
+
+// sameSource compares a declared source, which may be a UUID=, LABEL=,
+// PARTUUID= or PARTLABEL= tag or a device symlink, with a mounted source
+func (d *DriftDetector) sameSource(declared, actual string) bool {
+	if declared == "" || declared == actual {
+		return true
+	}
+
+	return d.resolveDevice(declared) == d.resolveDevice(actual)
+}
+
+// resolveDevice resolves tags and device symlinks to the underlying device
+func (d *DriftDetector) resolveDevice(source string) string {
+	tags := map[string]string{
+		"UUID":      "by-uuid",
+		"LABEL":     "by-label",
+		"PARTUUID":  "by-partuuid",
+		"PARTLABEL": "by-partlabel",
+	}
+
+	path := source
+	if tag, value, ok := strings.Cut(source, "="); ok {
+		dir, known := tags[tag]
+		if !known {
+			return source
+		}
+		path = filepath.Join(d.config.DevRoot, "disk", dir, value)
+	} else if strings.HasPrefix(source, "/dev/") {
+		path = filepath.Join(d.config.DevRoot, strings.TrimPrefix(source, "/dev/"))
+	} else {
+		return source
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return source
+	}
+	return resolved
+}
+
+// sameFSType compares filesystem types, treating "auto" as a wildcard and
+// nfs as matching nfs4
+func sameFSType(declared, actual string) bool {
+	switch {
+	case declared == "" || declared == "auto" || declared == actual:
+		return true
+	case declared == "nfs" && actual == "nfs4":
+		return true
+	default:
+		return false
+	}
+}
+
+// missingOptions returns the declared options not present on the mount
+func missingOptions(declared []string, entry MountInfo) []string {
+	actual := make(map[string]bool)
+	for _, opt := range strings.Split(entry.Options, ",") {
+		actual[opt] = true
+	}
+	for _, opt := range strings.Split(entry.SuperOptions, ",") {
+		actual[opt] = true
+	}
+
+	var missing []string
+	for _, opt := range declared {
+		if opt == "" || ignoredMountOptions[opt] || strings.HasPrefix(opt, "x-") || strings.HasPrefix(opt, "comment=") {
+			continue
+		}
+		if !actual[opt] {
+			missing = append(missing, opt)
+		}
+	}
+	return missing
+}
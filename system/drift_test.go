@@ -0,0 +1,110 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeDriftFixture creates fstab, mountinfo and /dev trees for drift tests
+func writeDriftFixture(t *testing.T, fstab, mountinfo string) DriftDetectorConfig {
+	t.Helper()
+	root := t.TempDir()
+
+	dev := filepath.Join(root, "dev")
+	if err := os.MkdirAll(filepath.Join(dev, "disk", "by-uuid"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sda1", "sdb1"} {
+		if err := os.WriteFile(filepath.Join(dev, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("../../sda1", filepath.Join(dev, "disk", "by-uuid", "1234-abcd")); err != nil {
+		t.Fatal(err)
+	}
+
+	proc := filepath.Join(root, "proc")
+	if err := os.MkdirAll(filepath.Join(proc, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(proc, "self", "mountinfo"), []byte(mountinfo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fstabPath := filepath.Join(root, "fstab")
+	if err := os.WriteFile(fstabPath, []byte(fstab), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return DriftDetectorConfig{FstabPath: fstabPath, ProcRoot: proc, DevRoot: dev}
+}
+
+func TestDriftDetector_Check(t *testing.T) {
+	fstab := `UUID=1234-abcd / ext4 defaults,errors=remount-ro 0 1
+/dev/sda1 /data xfs noatime,nofail 0 2
+/dev/sdb1 /backup ext4 defaults 0 2
+nas:/exports /mnt/nas nfs noauto,vers=4.2 0 0
+`
+	mountinfo := `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw,errors=remount-ro
+36 22 8:17 / /data rw,relatime shared:2 - ext4 /dev/sdb1 rw
+`
+	report, err := NewDriftDetector(writeDriftFixture(t, fstab, mountinfo)).Check()
+	if err != nil {
+		t.Fatalf("Drift check failed: %v", err)
+	}
+	if len(report.Results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(report.Results))
+	}
+
+	root := report.Results[0]
+	if !root.Mounted || !root.SourceMatch || !root.FSTypeMatch || !root.OptionsMatch {
+		t.Errorf("Expected / to match its UUID declaration, got %+v", root)
+	}
+
+	data := report.Results[1]
+	if !data.Mounted || data.SourceMatch || data.FSTypeMatch || data.OptionsMatch {
+		t.Errorf("Expected /data to drift in source, fs_type and options, got %+v", data)
+	}
+	if len(data.MissingOptions) != 1 || data.MissingOptions[0] != "noatime" {
+		t.Errorf("Expected noatime to be missing, got %v", data.MissingOptions)
+	}
+
+	if report.Results[2].Mounted {
+		t.Error("Expected /backup not to be mounted")
+	}
+	if nas := report.Results[3]; nas.Mounted || !nas.Declared.NoAuto() {
+		t.Errorf("Expected unmounted noauto /mnt/nas, got %+v", nas)
+	}
+
+	if !report.IsDeclared("/data") || report.IsDeclared("/srv") {
+		t.Error("Unexpected IsDeclared result")
+	}
+}
+
+func TestDriftDetector_MissingFstab(t *testing.T) {
+	config := writeDriftFixture(t, "", "")
+	config.FstabPath = filepath.Join(t.TempDir(), "missing")
+
+	if _, err := NewDriftDetector(config).Check(); err == nil {
+		t.Error("Expected error for missing fstab")
+	}
+}
+
+func TestSameFSType(t *testing.T) {
+	tests := []struct {
+		declared, actual string
+		expected         bool
+	}{
+		{"ext4", "ext4", true},
+		{"auto", "xfs", true},
+		{"nfs", "nfs4", true},
+		{"ext4", "xfs", false},
+	}
+
+	for _, tt := range tests {
+		if got := sameFSType(tt.declared, tt.actual); got != tt.expected {
+			t.Errorf("sameFSType(%q, %q) = %v, expected %v", tt.declared, tt.actual, got, tt.expected)
+		}
+	}
+}
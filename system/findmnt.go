@@ -3,8 +3,10 @@ package system
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -33,6 +35,19 @@ func (ms MountStatus) String() string {
 	}
 }
 
+// ErrStillPending is returned when an earlier blocking operation on the same
+// path has not finished yet
+var ErrStillPending = errors.New("previous operation is still pending")
+
+// ErrNotMounted is returned internally when findmnt reports that a mount point
+// is not mounted. It is a valid check result, not a command failure.
+var ErrNotMounted = errors.New("mount point is not mounted")
+
+// isCheckSuccessful reports whether a findmnt call produced a definite answer
+func isCheckSuccessful(err error) bool {
+	return err == nil || errors.Is(err, ErrNotMounted)
+}
+
 // FindmntResult represents the result of a findmnt command
 type FindmntResult struct {
 	MountPoint string      `json:"mount_point"`
@@ -41,14 +56,39 @@ type FindmntResult struct {
 	FSType     string      `json:"fs_type,omitempty"`
 	Options    string      `json:"options,omitempty"`
 	Source     string      `json:"source,omitempty"`
-	Error      error       `json:"error,omitempty"`
+	// Propagation is findmnt's propagation flags, e.g. "shared" or "private,slave"
+	Propagation string `json:"propagation,omitempty"`
+	// Kind is only set when the mount table was classified
+	Kind  MountKind `json:"kind,omitempty"`
+	Error error     `json:"error,omitempty"`
+}
+
+// OptionList returns the mount options as a list
+func (r *FindmntResult) OptionList() []string {
+	if r.Options == "" {
+		return nil
+	}
+	return strings.Split(r.Options, ",")
+}
+
+// ReadOnly reports whether the mount is read-only
+func (r *FindmntResult) ReadOnly() bool {
+	for _, opt := range r.OptionList() {
+		if opt == "ro" {
+			return true
+		}
+	}
+	return false
 }
 
 // FindmntWrapper provides a wrapper around the findmnt command
 type FindmntWrapper struct {
 	timeout        time.Duration
+	procRoot       string
 	circuitBreaker *reliability.CircuitBreaker
+	latencyBreakers map[string]*reliability.CircuitBreaker
 	retry          *reliability.Retry
+	pending        map[string]bool
 	mu             sync.RWMutex
 	stats          struct {
 		totalCalls       int64
@@ -65,6 +105,7 @@ func NewFindmntWrapper(timeout time.Duration) *FindmntWrapper {
 		Name:         "findmnt-circuit-breaker",
 		MaxFailures:  5,
 		ResetTimeout: 60 * time.Second,
+		IsSuccessful: isCheckSuccessful,
 		OnStateChange: func(name string, from, to reliability.State) {
 			// Log circuit breaker state changes
 			if to == reliability.StateOpen {
@@ -78,16 +119,35 @@ func NewFindmntWrapper(timeout time.Duration) *FindmntWrapper {
 		reliability.WithInitialDelay(100*time.Millisecond),
 		reliability.WithMaxDelay(5*time.Second),
 		reliability.WithBackoffStrategy(reliability.BackoffStrategyExponential),
-		reliability.WithShouldRetry(reliability.IsTransientError),
+		reliability.WithJitterStrategy(reliability.JitterStrategyFull),
+		reliability.WithShouldRetry(func(err error) bool {
+			return !isCheckSuccessful(err) && reliability.IsTransientError(err)
+		}),
 	)
 
 	return &FindmntWrapper{
 		timeout:        timeout,
+		procRoot:       "/proc",
 		circuitBreaker: cb,
 		retry:          retry,
 	}
 }
 
+// Timeout returns the timeout of findmnt commands and probe operations
+func (f *FindmntWrapper) Timeout() time.Duration {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.timeout
+}
+
+// SetTimeout changes the timeout of subsequent calls. Pending operations and
+// the circuit breakers are kept, so a reload does not restart hung probes.
+func (f *FindmntWrapper) SetTimeout(timeout time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.timeout = timeout
+}
+
 // CheckMountPoint checks if a mount point is currently mounted using findmnt
 func (f *FindmntWrapper) CheckMountPoint(ctx context.Context, mountPoint string) *FindmntResult {
 	f.mu.Lock()
@@ -99,32 +159,22 @@ func (f *FindmntWrapper) CheckMountPoint(ctx context.Context, mountPoint string)
 		Status:     MountStatusUnknown,
 	}
 
-	// Check circuit breaker first
-	if f.circuitBreaker.IsOpen() {
-		f.mu.Lock()
-		f.stats.failedCalls++
-		f.mu.Unlock()
-		result.Error = fmt.Errorf("circuit breaker is open - findmnt commands are temporarily disabled")
-		result.Status = MountStatusUnknown
-		return result
-	}
-
 	// Execute findmnt through circuit breaker
-	err := f.circuitBreaker.Execute(func() error {
+	err := f.circuitBreaker.ExecuteContext(ctx, func(ctx context.Context) error {
 		return f.executeFindmnt(ctx, mountPoint, result)
 	})
 
-	if err != nil {
+	if err != nil && !errors.Is(err, ErrNotMounted) {
 		f.mu.Lock()
 		f.stats.failedCalls++
 		f.mu.Unlock()
 
-		if err.Error() == "circuit breaker is open" {
-			result.Error = fmt.Errorf("circuit breaker is open - findmnt commands are temporarily disabled")
-			result.Status = MountStatusUnknown
+		if errors.Is(err, reliability.ErrOpen) || errors.Is(err, reliability.ErrTooManyRequests) {
+			result.Error = fmt.Errorf("findmnt commands are temporarily disabled: %w", err)
 		} else {
 			result.Error = err
 		}
+		result.Status = MountStatusUnknown
 		return result
 	}
 
@@ -144,59 +194,27 @@ func (f *FindmntWrapper) executeFindmnt(ctx context.Context, mountPoint string,
 		f.mu.Unlock()
 
 		// Create context with timeout
-		cmdCtx, cancel := context.WithTimeout(ctx, f.timeout)
+		timeout := f.Timeout()
+		cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
 
 		// Execute findmnt command
-		cmd := exec.CommandContext(cmdCtx, "findmnt", "-n", "-o", "TARGET,FSTYPE,OPTIONS,SOURCE", "--mountpoint", mountPoint)
+		cmd := exec.CommandContext(cmdCtx, "findmnt", "-n", "-P", "-o", "TARGET,FSTYPE,OPTIONS,SOURCE,PROPAGATION", "--mountpoint", mountPoint)
 		output, err := cmd.Output()
 
 		if err != nil {
 			if cmdCtx.Err() == context.DeadlineExceeded {
-				return fmt.Errorf("findmnt command timed out after %v", f.timeout)
+				return fmt.Errorf("findmnt command timed out after %v: %w", timeout, cmdCtx.Err())
 			} else if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 1 {
 				// Exit code 1 typically means mount point not found - this is not a failure
 				result.Status = MountStatusNotMounted
-				return nil
+				return ErrNotMounted
 			} else {
 				return fmt.Errorf("findmnt command failed: %w", err)
 			}
 		}
 
-		// Parse the output
-		outputStr := string(output)
-		if len(strings.TrimSpace(outputStr)) == 0 {
-			result.Status = MountStatusNotMounted
-			return nil
-		}
-
-		// Parse findmnt output
-		scanner := bufio.NewScanner(strings.NewReader(outputStr))
-		if scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line != "" {
-				fields := strings.Split(line, " ")
-				if len(fields) >= 1 {
-					result.Status = MountStatusMounted
-					result.Target = fields[0]
-					if len(fields) >= 2 {
-						result.FSType = fields[1]
-					}
-					if len(fields) >= 3 {
-						result.Options = fields[2]
-					}
-					if len(fields) >= 4 {
-						result.Source = strings.Join(fields[3:], " ")
-					}
-				}
-			}
-		}
-
-		if err := scanner.Err(); err != nil {
-			return fmt.Errorf("failed to parse findmnt output: %w", err)
-		}
-
-		return nil
+		return parseFindmntOutput(string(output), result)
 	})
 }
 
@@ -301,4 +319,114 @@ func (f *FindmntWrapper) ResetCircuitBreaker() {
 // GetCircuitBreakerState returns the current circuit breaker state
 func (f *FindmntWrapper) GetCircuitBreakerState() reliability.State {
 	return f.circuitBreaker.State()
-}
\ No newline at end of file
+}
+
+// runPending runs fn, which may block in the kernel indefinitely, waiting at
+// most timeout. While an earlier call with the same key is still running, fn
+// is not started again and an error is returned instead.
+func (f *FindmntWrapper) runPending(ctx context.Context, key string, timeout time.Duration, fn func() error) error {
+	f.mu.Lock()
+	if f.pending == nil {
+		f.pending = make(map[string]bool)
+	}
+	if f.pending[key] {
+		f.mu.Unlock()
+		return ErrStillPending
+	}
+	f.pending[key] = true
+	f.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			f.mu.Lock()
+			delete(f.pending, key)
+			f.mu.Unlock()
+		}()
+		done <- fn()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("timed out after %v: %w", timeout, ctx.Err())
+	}
+}
+
+// parseFindmntOutput parses the first line of `findmnt -P` output into result.
+// Key="value" pairs are used because the default table output pads columns
+// and cannot tell a space inside a source apart from a column separator.
+func parseFindmntOutput(output string, result *FindmntResult) error {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		pairs, err := parseFindmntPairs(line)
+		if err != nil {
+			return fmt.Errorf("failed to parse findmnt output: %w", err)
+		}
+		result.Status = MountStatusMounted
+		result.Target = pairs["TARGET"]
+		result.FSType = pairs["FSTYPE"]
+		result.Options = pairs["OPTIONS"]
+		result.Source = pairs["SOURCE"]
+		result.Propagation = pairs["PROPAGATION"]
+		return nil
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to parse findmnt output: %w", err)
+	}
+
+	result.Status = MountStatusNotMounted
+	return ErrNotMounted
+}
+
+// parseFindmntPairs parses a line of KEY="value" pairs. findmnt escapes
+// quotes, backslashes and unprintable characters in values as \xNN.
+func parseFindmntPairs(line string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for line = strings.TrimSpace(line); line != ""; line = strings.TrimSpace(line) {
+		eq := strings.Index(line, "=\"")
+		if eq <= 0 {
+			return nil, fmt.Errorf("expected KEY=\"value\" in %q", line)
+		}
+		key := line[:eq]
+		rest := line[eq+2:]
+
+		end := strings.IndexByte(rest, '"')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated value for %s", key)
+		}
+		pairs[key] = unescapeFindmntValue(rest[:end])
+		line = rest[end+1:]
+	}
+	return pairs, nil
+}
+
+// unescapeFindmntValue decodes \xNN escapes in a findmnt -P value
+func unescapeFindmntValue(s string) string {
+	if !strings.Contains(s, `\x`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) && s[i+1] == 'x' {
+			if v, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
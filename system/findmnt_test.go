@@ -2,10 +2,13 @@ package system
 
 import (
 	"context"
+	"errors"
 	"os"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/mount-exporter/mount-exporter/reliability"
 )
 
 func TestNewFindmntWrapper(t *testing.T) {
@@ -26,12 +29,12 @@ func TestFindmntWrapper_CheckMountPoint_ContextTimeout(t *testing.T) {
 
 	result := wrapper.CheckMountPoint(ctx, "/nonexistent")
 
-	if result.Status != MountStatusNotMounted {
-		t.Errorf("Expected status MountStatusNotMounted, got %v", result.Status)
+	if result.Status != MountStatusUnknown {
+		t.Errorf("Expected status MountStatusUnknown, got %v", result.Status)
 	}
 
 	if result.Error == nil {
-		t.Error("Expected timeout error, got nil")
+		t.Fatal("Expected timeout error, got nil")
 	}
 
 	if !contains(result.Error.Error(), "timed out") {
@@ -238,8 +241,8 @@ func TestFindmntWrapper_CheckMountPoint_InvalidCommand(t *testing.T) {
 
 	result := wrapper.CheckMountPoint(context.Background(), "/")
 
-	if result.Status != MountStatusNotMounted {
-		t.Errorf("Expected status MountStatusNotMounted when findmnt unavailable, got %v", result.Status)
+	if result.Status != MountStatusUnknown {
+		t.Errorf("Expected status MountStatusUnknown when findmnt unavailable, got %v", result.Status)
 	}
 
 	if result.Error == nil {
@@ -271,7 +274,7 @@ func TestFindmntWrapper_CircuitBreaker(t *testing.T) {
 
 	// Get initial stats
 	stats := wrapper.GetStats()
-	if stats["total_calls"] != float64(0) {
+	if stats["total_calls"] != int64(0) {
 		t.Errorf("Expected 0 total calls initially, got %v", stats["total_calls"])
 	}
 }
@@ -285,7 +288,7 @@ func TestFindmntWrapper_GetStats(t *testing.T) {
 	stats := wrapper.GetStats()
 
 	// Check that stats are populated
-	if stats["total_calls"] == float64(0) {
+	if stats["total_calls"] == int64(0) {
 		t.Error("Expected total_calls to be > 0 after making a call")
 	}
 
@@ -318,6 +321,25 @@ func TestFindmntWrapper_ResetCircuitBreaker(t *testing.T) {
 	}
 }
 
+func TestFindmntWrapper_NotMountedDoesNotTripCircuitBreaker(t *testing.T) {
+	wrapper := NewFindmntWrapper(5 * time.Second)
+
+	for i := 0; i < 10; i++ {
+		result := wrapper.CheckMountPoint(context.Background(), "/definitely-nonexistent-mount-point-12345")
+		if result.Error != nil {
+			t.Fatalf("Expected no error for non-existent mount, got %v", result.Error)
+		}
+	}
+
+	if wrapper.GetCircuitBreakerState() != reliability.StateClosed {
+		t.Errorf("Expected circuit breaker to stay CLOSED, got %s", wrapper.GetCircuitBreakerState())
+	}
+
+	if failures := wrapper.GetStats()["circuit_breaker_failures"]; failures != 0 {
+		t.Errorf("Expected no circuit breaker failures, got %v", failures)
+	}
+}
+
 // Helper function to check if a string contains a substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
@@ -330,4 +352,49 @@ func contains(s, substr string) bool {
 			}
 			return false
 		}())))
-}
\ No newline at end of file
+}
+func TestParseFindmntOutput(t *testing.T) {
+	// A source with a space and a quote, which single-space splitting mangled
+	output := `TARGET="/mnt/my share" FSTYPE="cifs" OPTIONS="rw,nosuid,nodev" SOURCE="//nas/my\x20\x22share\x22" PROPAGATION="shared"` + "\n"
+
+	result := &FindmntResult{MountPoint: "/mnt/my share"}
+	if err := parseFindmntOutput(output, result); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := &FindmntResult{
+		MountPoint:  "/mnt/my share",
+		Status:      MountStatusMounted,
+		Target:      "/mnt/my share",
+		FSType:      "cifs",
+		Options:     "rw,nosuid,nodev",
+		Source:      `//nas/my "share"`,
+		Propagation: "shared",
+	}
+	if *result != *expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+	if result.ReadOnly() {
+		t.Error("Expected rw mount not to be read-only")
+	}
+}
+
+func TestParseFindmntOutput_Invalid(t *testing.T) {
+	result := &FindmntResult{}
+	if err := parseFindmntOutput("   \n", result); !errors.Is(err, ErrNotMounted) || result.Status != MountStatusNotMounted {
+		t.Errorf("Expected empty output to mean not mounted, got %v", err)
+	}
+
+	if err := parseFindmntOutput(`TARGET="/data`, &FindmntResult{}); err == nil {
+		t.Error("Expected error for unterminated value")
+	}
+}
+
+func TestFindmntResult_ReadOnly(t *testing.T) {
+	if !(&FindmntResult{Options: "ro,relatime"}).ReadOnly() {
+		t.Error("Expected ro mount to be read-only")
+	}
+	if (&FindmntResult{Options: "rw,errors=remount-ro"}).ReadOnly() {
+		t.Error("Expected rw mount not to be read-only")
+	}
+}
@@ -0,0 +1,182 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DeclaredMount is a mount declared in /etc/fstab or a systemd .mount unit
+type DeclaredMount struct {
+	Source     string
+	MountPoint string
+	FSType     string
+	Options    []string
+	// Origin is "fstab" or the name of the .mount unit
+	Origin string
+}
+
+// NoAuto reports whether the mount is not mounted automatically at boot
+func (d DeclaredMount) NoAuto() bool {
+	for _, opt := range d.Options {
+		if opt == "noauto" {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadFstab reads and parses an fstab file
+func ReadFstab(path string) ([]DeclaredMount, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fstab: %w", err)
+	}
+	defer file.Close()
+
+	return ParseFstab(file)
+}
+
+// ParseFstab parses fstab entries, see fstab(5). Swap entries and entries
+// without an absolute mount point are skipped.
+func ParseFstab(r io.Reader) ([]DeclaredMount, error) {
+	var mounts []DeclaredMount
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("malformed fstab line %d: %q", lineNo, line)
+		}
+
+		mount := DeclaredMount{
+			Source:     unescapeMountPath(fields[0]),
+			MountPoint: unescapeMountPath(fields[1]),
+			FSType:     fields[2],
+			Origin:     "fstab",
+		}
+		if len(fields) >= 4 {
+			mount.Options = strings.Split(fields[3], ",")
+		}
+
+		if mount.FSType == "swap" || !strings.HasPrefix(mount.MountPoint, "/") {
+			continue
+		}
+
+		mounts = append(mounts, mount)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read fstab: %w", err)
+	}
+
+	return mounts, nil
+}
+
+// ReadMountUnits reads the .mount units in dirs. A unit in an earlier
+// directory overrides one of the same name in a later directory, as with
+// systemd's unit search path.
+func ReadMountUnits(dirs []string) ([]DeclaredMount, error) {
+	seen := make(map[string]bool)
+	var mounts []DeclaredMount
+
+	for _, dir := range dirs {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.mount"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list mount units in %s: %w", dir, err)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			name := filepath.Base(path)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			mount, err := readMountUnit(path)
+			if err != nil {
+				return nil, err
+			}
+			if mount.MountPoint != "" {
+				mounts = append(mounts, mount)
+			}
+		}
+	}
+
+	return mounts, nil
+}
+
+// readMountUnit reads a single .mount unit
+func readMountUnit(path string) (DeclaredMount, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return DeclaredMount{}, fmt.Errorf("failed to open mount unit: %w", err)
+	}
+	defer file.Close()
+
+	mount, err := ParseMountUnit(file)
+	if err != nil {
+		return DeclaredMount{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	mount.Origin = filepath.Base(path)
+	return mount, nil
+}
+
+// ParseMountUnit parses the [Mount] section of a systemd .mount unit
+func ParseMountUnit(r io.Reader) (DeclaredMount, error) {
+	var mount DeclaredMount
+	inMount := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inMount = line == "[Mount]"
+			continue
+		}
+		if !inMount {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "What":
+			mount.Source = value
+		case "Where":
+			mount.MountPoint = value
+		case "Type":
+			mount.FSType = value
+		case "Options":
+			if value != "" {
+				mount.Options = strings.Split(value, ",")
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return DeclaredMount{}, err
+	}
+
+	return mount, nil
+}
@@ -0,0 +1,74 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleFstab = `# /etc/fstab
+UUID=1234-abcd  /              ext4  defaults,errors=remount-ro  0 1
+/dev/sdb1       /data          xfs   noatime                     0 2
+nas:/exports    /mnt/my\040nas nfs   noauto,_netdev,vers=4.2     0 0
+/swapfile       none           swap  sw                          0 0
+
+tmpfs           /tmp           tmpfs  defaults,size=1g
+`
+
+func TestParseFstab(t *testing.T) {
+	mounts, err := ParseFstab(strings.NewReader(sampleFstab))
+	if err != nil {
+		t.Fatalf("Failed to parse fstab: %v", err)
+	}
+	if len(mounts) != 4 {
+		t.Fatalf("Expected 4 entries (swap skipped), got %d: %+v", len(mounts), mounts)
+	}
+
+	if mounts[0].Source != "UUID=1234-abcd" || mounts[0].MountPoint != "/" || mounts[0].Origin != "fstab" {
+		t.Errorf("Unexpected root entry: %+v", mounts[0])
+	}
+	if mounts[2].MountPoint != "/mnt/my nas" || !mounts[2].NoAuto() {
+		t.Errorf("Expected unescaped noauto NFS entry, got %+v", mounts[2])
+	}
+	if mounts[1].NoAuto() {
+		t.Error("Expected /data to be mounted automatically")
+	}
+
+	if _, err := ParseFstab(strings.NewReader("/dev/sda1 /\n")); err == nil {
+		t.Error("Expected error for malformed line")
+	}
+}
+
+func TestReadMountUnits(t *testing.T) {
+	etc := t.TempDir()
+	lib := t.TempDir()
+
+	unit := "[Unit]\nDescription=Data\n\n[Mount]\nWhat=/dev/sdc1\nWhere=/srv/data\nType=ext4\nOptions=noatime,noauto\n\n[Install]\nWantedBy=multi-user.target\n"
+	if err := os.WriteFile(filepath.Join(etc, "srv-data.mount"), []byte(unit), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Overridden by the unit of the same name in the earlier directory
+	if err := os.WriteFile(filepath.Join(lib, "srv-data.mount"), []byte("[Mount]\nWhere=/srv/other\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(lib, "srv-backup.mount"), []byte("[Mount]\nWhat=nas:/b\nWhere=/srv/backup\nType=nfs\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mounts, err := ReadMountUnits([]string{etc, lib})
+	if err != nil {
+		t.Fatalf("Failed to read mount units: %v", err)
+	}
+	if len(mounts) != 2 {
+		t.Fatalf("Expected 2 units, got %+v", mounts)
+	}
+
+	data := mounts[0]
+	if data.Origin != "srv-data.mount" || data.MountPoint != "/srv/data" || data.FSType != "ext4" || !data.NoAuto() {
+		t.Errorf("Unexpected unit: %+v", data)
+	}
+	if mounts[1].MountPoint != "/srv/backup" {
+		t.Errorf("Expected /srv/backup, got %+v", mounts[1])
+	}
+}
@@ -0,0 +1,137 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/reliability"
+)
+
+// Latency probe operations
+const (
+	OperationStat       = "stat"
+	OperationReadDir    = "readdir"
+	OperationCanaryRead = "canary_read"
+)
+
+// canaryReadSize bounds how much of a canary file is read
+const canaryReadSize = 4096
+
+// LatencySample is the timing of one metadata or read operation
+type LatencySample struct {
+	MountPoint string
+	Operation  string
+	Duration   time.Duration
+	Error      error
+}
+
+// latencyBreaker returns the circuit breaker of latency probes on
+// mountPoint, creating it on first use. Every mount point has its own, so a
+// hung mount does not stop probes of the others, and none of them is the
+// findmnt breaker, so failing probes never stop mount checks.
+func (f *FindmntWrapper) latencyBreaker(mountPoint string) *reliability.CircuitBreaker {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if cb, ok := f.latencyBreakers[mountPoint]; ok {
+		return cb
+	}
+	if f.latencyBreakers == nil {
+		f.latencyBreakers = make(map[string]*reliability.CircuitBreaker)
+	}
+	cb := reliability.NewCircuitBreaker(reliability.CircuitBreakerConfig{
+		Name:         "latency-probe-circuit-breaker:" + mountPoint,
+		MaxFailures:  5,
+		ResetTimeout: 60 * time.Second,
+		IsSuccessful: isLatencyProbeSuccessful,
+	})
+	f.latencyBreakers[mountPoint] = cb
+	return cb
+}
+
+// isLatencyProbeSuccessful counts only transient errors, such as timeouts
+// or EIO from a hung mount, as breaker failures. A missing canary, a denied
+// read or a probe still pending says nothing new about the mount.
+func isLatencyProbeSuccessful(err error) bool {
+	return err == nil || !reliability.IsTransientError(err)
+}
+
+// Ran reports whether the operation was started, rather than rejected by
+// the circuit breaker or skipped while an earlier one is still pending. The
+// duration of an operation that did not run says nothing about the mount.
+func (s LatencySample) Ran() bool {
+	return !errors.Is(s.Error, reliability.ErrOpen) &&
+		!errors.Is(s.Error, reliability.ErrTooManyRequests) &&
+		!errors.Is(s.Error, ErrStillPending)
+}
+
+// ProbeLatency times a stat and a readdir of the mount root and, when
+// canary is set, a small read of that file relative to the mount point.
+// Operations share the findmnt timeout and have a circuit breaker per mount
+// point, so a mount that keeps hanging stops being probed until its breaker
+// resets.
+func (f *FindmntWrapper) ProbeLatency(ctx context.Context, mountPoint, canary string) []LatencySample {
+	ops := []struct {
+		name string
+		run  func() error
+	}{
+		{OperationStat, func() error {
+			_, err := os.Stat(mountPoint)
+			return err
+		}},
+		{OperationReadDir, func() error {
+			return readDirEntry(mountPoint)
+		}},
+	}
+	if canary != "" {
+		path := filepath.Join(mountPoint, canary)
+		ops = append(ops, struct {
+			name string
+			run  func() error
+		}{OperationCanaryRead, func() error {
+			return readCanary(path)
+		}})
+	}
+
+	timeout := f.Timeout()
+	breaker := f.latencyBreaker(mountPoint)
+	samples := make([]LatencySample, 0, len(ops))
+	for _, op := range ops {
+		start := time.Now()
+		err := breaker.ExecuteContext(ctx, func(ctx context.Context) error {
+			return f.runPending(ctx, op.name+":"+mountPoint, timeout, op.run)
+		})
+		if errors.Is(err, reliability.ErrOpen) || errors.Is(err, reliability.ErrTooManyRequests) {
+			err = fmt.Errorf("latency probes are temporarily disabled: %w", err)
+		}
+
+		samples = append(samples, LatencySample{
+			MountPoint: mountPoint,
+			Operation:  op.name,
+			Duration:   time.Since(start),
+			Error:      err,
+		})
+	}
+
+	return samples
+}
+
+// readCanary reads the beginning of a canary file
+func readCanary(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buf := make([]byte, canaryReadSize)
+	if _, err := file.Read(buf); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
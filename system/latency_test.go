@@ -0,0 +1,132 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mount-exporter/mount-exporter/reliability"
+)
+
+func TestFindmntWrapper_ProbeLatency(t *testing.T) {
+	mountPoint := t.TempDir()
+	if err := os.WriteFile(filepath.Join(mountPoint, ".canary"), []byte("ok\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write canary: %v", err)
+	}
+	f := NewFindmntWrapper(time.Second)
+
+	samples := f.ProbeLatency(context.Background(), mountPoint, ".canary")
+	if len(samples) != 3 {
+		t.Fatalf("Expected 3 samples, got %d", len(samples))
+	}
+	for i, op := range []string{OperationStat, OperationReadDir, OperationCanaryRead} {
+		if samples[i].Operation != op || samples[i].MountPoint != mountPoint || samples[i].Error != nil {
+			t.Errorf("Expected successful %s sample, got %+v", op, samples[i])
+		}
+	}
+
+	// Without a canary only the mount root is probed
+	if samples := f.ProbeLatency(context.Background(), mountPoint, ""); len(samples) != 2 {
+		t.Errorf("Expected 2 samples without canary, got %d", len(samples))
+	}
+}
+
+func TestFindmntWrapper_ProbeLatency_MissingCanary(t *testing.T) {
+	f := NewFindmntWrapper(time.Second)
+
+	samples := f.ProbeLatency(context.Background(), t.TempDir(), "missing")
+	if samples[0].Error != nil || samples[1].Error != nil {
+		t.Errorf("Expected stat and readdir to succeed, got %+v", samples)
+	}
+	if !errors.Is(samples[2].Error, syscall.ENOENT) {
+		t.Errorf("Expected ENOENT for missing canary, got %v", samples[2].Error)
+	}
+}
+
+func TestFindmntWrapper_ProbeLatency_PermanentErrors(t *testing.T) {
+	f := NewFindmntWrapper(time.Second)
+	mountPoint := t.TempDir()
+
+	// A missing canary fails on every probe without tripping a breaker
+	for i := 0; i < 10; i++ {
+		samples := f.ProbeLatency(context.Background(), mountPoint, "missing")
+		if !errors.Is(samples[2].Error, syscall.ENOENT) {
+			t.Fatalf("Probe %d: expected ENOENT for missing canary, got %v", i, samples[2].Error)
+		}
+	}
+	if state := f.latencyBreaker(mountPoint).State(); state != reliability.StateClosed {
+		t.Errorf("Expected latency breaker to stay closed, got %s", state)
+	}
+	if state := f.GetCircuitBreakerState(); state != reliability.StateClosed {
+		t.Errorf("Expected findmnt breaker to stay closed, got %s", state)
+	}
+}
+
+func TestFindmntWrapper_ProbeLatency_BreakerPerMountPoint(t *testing.T) {
+	f := NewFindmntWrapper(time.Second)
+	hung, healthy := t.TempDir(), t.TempDir()
+
+	// Trip the breaker of one mount point as repeated I/O errors would
+	for i := 0; i < 5; i++ {
+		f.latencyBreaker(hung).Execute(func() error { return syscall.EIO })
+	}
+
+	if samples := f.ProbeLatency(context.Background(), hung, ""); !errors.Is(samples[0].Error, reliability.ErrOpen) {
+		t.Errorf("Expected probes of %s to be disabled, got %v", hung, samples[0].Error)
+	}
+	for _, sample := range f.ProbeLatency(context.Background(), healthy, "") {
+		if sample.Error != nil {
+			t.Errorf("Expected probes of other mount points to run, got %+v", sample)
+		}
+	}
+}
+
+func TestIsLatencyProbeSuccessful(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, true},
+		{&os.PathError{Op: "open", Path: "/data/canary", Err: syscall.ENOENT}, true},
+		{&os.PathError{Op: "open", Path: "/data/canary", Err: syscall.EACCES}, true},
+		{ErrStillPending, true},
+		{context.DeadlineExceeded, false},
+		{&os.PathError{Op: "stat", Path: "/data", Err: syscall.EIO}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isLatencyProbeSuccessful(tt.err); got != tt.expected {
+			t.Errorf("isLatencyProbeSuccessful(%v) = %v, expected %v", tt.err, got, tt.expected)
+		}
+	}
+}
+
+func TestFindmntWrapper_ProbeLatency_Timeout(t *testing.T) {
+	// Opening a FIFO blocks until a writer appears, like a hung mount
+	mountPoint := t.TempDir()
+	fifo := filepath.Join(mountPoint, "canary")
+	if err := syscall.Mkfifo(fifo, 0o644); err != nil {
+		t.Skipf("Cannot create FIFO: %v", err)
+	}
+	defer func() {
+		if w, err := os.OpenFile(fifo, os.O_WRONLY, 0); err == nil {
+			w.Close()
+		}
+	}()
+	f := NewFindmntWrapper(20 * time.Millisecond)
+
+	samples := f.ProbeLatency(context.Background(), mountPoint, "canary")
+	if !errors.Is(samples[2].Error, context.DeadlineExceeded) {
+		t.Errorf("Expected canary read to time out, got %v", samples[2].Error)
+	}
+
+	// The hung read is not repeated
+	samples = f.ProbeLatency(context.Background(), mountPoint, "canary")
+	if !errors.Is(samples[2].Error, ErrStillPending) {
+		t.Errorf("Expected pending canary read to be reported, got %v", samples[2].Error)
+	}
+}
@@ -0,0 +1,185 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MountInfo is one entry of /proc/<pid>/mountinfo
+type MountInfo struct {
+	ID             int
+	ParentID       int
+	Major          int
+	Minor          int
+	Root           string
+	MountPoint     string
+	Options        string
+	OptionalFields []string
+	FSType         string
+	Source         string
+	SuperOptions   string
+}
+
+// Propagation returns the propagation flags in findmnt's format, e.g.
+// "shared", "private,slave" or "private,unbindable"
+func (m MountInfo) Propagation() string {
+	flags := []string{"private"}
+	for _, field := range m.OptionalFields {
+		switch {
+		case strings.HasPrefix(field, "shared:"):
+			flags[0] = "shared"
+		case strings.HasPrefix(field, "master:"):
+			flags = append(flags, "slave")
+		case field == "unbindable":
+			flags = append(flags, "unbindable")
+		}
+	}
+	return strings.Join(flags, ",")
+}
+
+// MergedOptions returns the per-mount options followed by the filesystem's
+// super options, as findmnt's OPTIONS column does. Super options already
+// set per mount, and the superblock's rw/ro, are left out.
+func (m MountInfo) MergedOptions() string {
+	if m.SuperOptions == "" {
+		return m.Options
+	}
+
+	seen := make(map[string]bool)
+	merged := strings.Split(m.Options, ",")
+	for _, opt := range merged {
+		seen[opt] = true
+	}
+	for _, opt := range strings.Split(m.SuperOptions, ",") {
+		if opt == "" || opt == "rw" || opt == "ro" || seen[opt] {
+			continue
+		}
+		seen[opt] = true
+		merged = append(merged, opt)
+	}
+	return strings.Join(merged, ",")
+}
+
+// ReadMountInfo reads and parses a mountinfo file
+func ReadMountInfo(path string) ([]MountInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mountinfo: %w", err)
+	}
+	defer file.Close()
+
+	return ParseMountInfo(file)
+}
+
+// ParseMountInfo parses mountinfo entries, see proc(5)
+func ParseMountInfo(r io.Reader) ([]MountInfo, error) {
+	var entries []MountInfo
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		entry, err := parseMountInfoLine(line)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mountinfo: %w", err)
+	}
+
+	return entries, nil
+}
+
+// parseMountInfoLine parses a single mountinfo line
+func parseMountInfoLine(line string) (MountInfo, error) {
+	fields := strings.Fields(line)
+
+	// The optional fields end at a lone "-" separator
+	sep := -1
+	for i := 6; i < len(fields); i++ {
+		if fields[i] == "-" {
+			sep = i
+			break
+		}
+	}
+	if len(fields) < 7 || sep < 0 || len(fields) < sep+3 {
+		return MountInfo{}, fmt.Errorf("malformed mountinfo line: %q", line)
+	}
+
+	id, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return MountInfo{}, fmt.Errorf("malformed mount ID in %q: %w", line, err)
+	}
+	parentID, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return MountInfo{}, fmt.Errorf("malformed parent ID in %q: %w", line, err)
+	}
+
+	var major, minor int
+	if _, err := fmt.Sscanf(fields[2], "%d:%d", &major, &minor); err != nil {
+		return MountInfo{}, fmt.Errorf("malformed device number in %q: %w", line, err)
+	}
+
+	entry := MountInfo{
+		ID:             id,
+		ParentID:       parentID,
+		Major:          major,
+		Minor:          minor,
+		Root:           unescapeMountPath(fields[3]),
+		MountPoint:     unescapeMountPath(fields[4]),
+		Options:        fields[5],
+		OptionalFields: append([]string{}, fields[6:sep]...),
+		FSType:         fields[sep+1],
+		Source:         unescapeMountPath(fields[sep+2]),
+	}
+	if len(fields) > sep+3 {
+		entry.SuperOptions = fields[sep+3]
+	}
+
+	return entry, nil
+}
+
+// unescapeMountPath decodes the octal escapes (\040 for space, etc.) the
+// kernel uses for whitespace and backslashes in mount paths
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// FindMountInfo returns the entry mounted on mountPoint. When several
+// mounts are stacked on the same path, the last (visible) one wins.
+func FindMountInfo(entries []MountInfo, mountPoint string) (MountInfo, bool) {
+	var found MountInfo
+	ok := false
+	for _, entry := range entries {
+		if entry.MountPoint == mountPoint {
+			found = entry
+			ok = true
+		}
+	}
+	return found, ok
+}
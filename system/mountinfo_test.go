@@ -0,0 +1,108 @@
+package system
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleMountInfo = `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw,errors=remount-ro
+36 22 8:17 / /data rw,noatime shared:2 master:1 - xfs /dev/sdb1 rw,attr2
+40 22 0:45 /exports /mnt/my\040share rw,relatime - nfs4 nas:/exports rw,vers=4.2
+41 36 0:46 / /data rw,relatime - tmpfs tmpfs rw,size=1024k
+`
+
+func TestParseMountInfo(t *testing.T) {
+	entries, err := ParseMountInfo(strings.NewReader(sampleMountInfo))
+	if err != nil {
+		t.Fatalf("Failed to parse mountinfo: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %d", len(entries))
+	}
+
+	data := entries[1]
+	if data.ID != 36 || data.ParentID != 22 || data.Major != 8 || data.Minor != 17 {
+		t.Errorf("Unexpected IDs or device number: %+v", data)
+	}
+	if data.MountPoint != "/data" || data.FSType != "xfs" || data.Source != "/dev/sdb1" {
+		t.Errorf("Unexpected mount fields: %+v", data)
+	}
+	if len(data.OptionalFields) != 2 || data.OptionalFields[1] != "master:1" {
+		t.Errorf("Expected optional fields [shared:2 master:1], got %v", data.OptionalFields)
+	}
+	if data.SuperOptions != "rw,attr2" {
+		t.Errorf("Expected super options rw,attr2, got %s", data.SuperOptions)
+	}
+
+	if entries[2].MountPoint != "/mnt/my share" {
+		t.Errorf("Expected escaped space to be decoded, got %q", entries[2].MountPoint)
+	}
+}
+
+func TestParseMountInfo_Malformed(t *testing.T) {
+	for _, line := range []string{
+		"22 1 8:1 / / rw,relatime shared:1 ext4 /dev/sda1 rw",
+		"x 1 8:1 / / rw - ext4 /dev/sda1 rw",
+		"22 1 8-1 / / rw - ext4 /dev/sda1 rw",
+	} {
+		if _, err := ParseMountInfo(strings.NewReader(line)); err == nil {
+			t.Errorf("Expected error for %q", line)
+		}
+	}
+}
+
+func TestFindMountInfo_StackedMounts(t *testing.T) {
+	entries, err := ParseMountInfo(strings.NewReader(sampleMountInfo))
+	if err != nil {
+		t.Fatalf("Failed to parse mountinfo: %v", err)
+	}
+
+	entry, ok := FindMountInfo(entries, "/data")
+	if !ok || entry.FSType != "tmpfs" {
+		t.Errorf("Expected the top-most mount on /data (tmpfs), got %+v", entry)
+	}
+
+	if _, ok := FindMountInfo(entries, "/missing"); ok {
+		t.Error("Expected /missing not to be found")
+	}
+}
+
+func TestMountInfo_Propagation(t *testing.T) {
+	tests := []struct {
+		fields   []string
+		expected string
+	}{
+		{nil, "private"},
+		{[]string{"shared:1"}, "shared"},
+		{[]string{"master:3"}, "private,slave"},
+		{[]string{"shared:5", "master:3"}, "shared,slave"},
+		{[]string{"unbindable"}, "private,unbindable"},
+	}
+
+	for _, tt := range tests {
+		entry := MountInfo{OptionalFields: tt.fields}
+		if got := entry.Propagation(); got != tt.expected {
+			t.Errorf("Propagation() with %v = %q, expected %q", tt.fields, got, tt.expected)
+		}
+	}
+}
+
+func TestMountInfo_MergedOptions(t *testing.T) {
+	tests := []struct {
+		options, superOptions string
+		expected              string
+	}{
+		{"rw,relatime", "", "rw,relatime"},
+		{"rw,nosuid,relatime", "rw,vers=4.2,sec=krb5p", "rw,nosuid,relatime,vers=4.2,sec=krb5p"},
+		// The per-mount rw/ro wins over the superblock's
+		{"ro,relatime", "rw,seclabel", "ro,relatime,seclabel"},
+		{"rw,noexec", "rw,noexec,size=812m", "rw,noexec,size=812m"},
+	}
+
+	for _, tt := range tests {
+		entry := MountInfo{Options: tt.options, SuperOptions: tt.superOptions}
+		if got := entry.MergedOptions(); got != tt.expected {
+			t.Errorf("MergedOptions() of %q and %q = %q, expected %q", tt.options, tt.superOptions, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package system
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NFSByteStats are the byte counters of an NFS mount
+type NFSByteStats struct {
+	NormalRead  uint64
+	NormalWrite uint64
+	DirectRead  uint64
+	DirectWrite uint64
+	ServerRead  uint64
+	ServerWrite uint64
+	ReadPages   uint64
+	WritePages  uint64
+}
+
+// Read returns the bytes read by applications, buffered and O_DIRECT
+func (b NFSByteStats) Read() uint64 {
+	return b.NormalRead + b.DirectRead
+}
+
+// Written returns the bytes written by applications, buffered and O_DIRECT
+func (b NFSByteStats) Written() uint64 {
+	return b.NormalWrite + b.DirectWrite
+}
+
+// NFSOperationStats are the RPC statistics of one NFS operation
+type NFSOperationStats struct {
+	Operation     string
+	Requests      uint64
+	Transmissions uint64
+	MajorTimeouts uint64
+	BytesSent     uint64
+	BytesReceived uint64
+	QueueTime     time.Duration
+	RTT           time.Duration
+	ExecuteTime   time.Duration
+	// Errors is only reported by statvers 1.1 and later
+	Errors uint64
+}
+
+// Retransmissions returns how often requests had to be sent again
+func (o NFSOperationStats) Retransmissions() uint64 {
+	if o.Transmissions < o.Requests {
+		return 0
+	}
+	return o.Transmissions - o.Requests
+}
+
+// NFSMountStats are the statistics of one NFS mount in /proc/self/mountstats
+type NFSMountStats struct {
+	// Device is the exported share, e.g. "nas:/export"
+	Device      string
+	MountPoint  string
+	FSType      string
+	StatVersion string
+	Age         time.Duration
+	Bytes       NFSByteStats
+	Operations  []NFSOperationStats
+}
+
+// Retransmissions returns the retransmissions summed over all operations
+func (s *NFSMountStats) Retransmissions() uint64 {
+	var total uint64
+	for _, op := range s.Operations {
+		total += op.Retransmissions()
+	}
+	return total
+}
+
+// ReadMountStats reads the NFS mounts of a mountstats file
+func ReadMountStats(path string) ([]*NFSMountStats, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mountstats: %w", err)
+	}
+	defer file.Close()
+
+	return ParseMountStats(file)
+}
+
+// ParseMountStats parses /proc/<pid>/mountstats, returning NFS mounts only.
+// Other filesystems have a single "device" line and are skipped.
+func ParseMountStats(r io.Reader) ([]*NFSMountStats, error) {
+	var mounts []*NFSMountStats
+	var current *NFSMountStats
+	inOps := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Mounts without a device name start with "no device"
+		if fields[0] == "no" && len(fields) > 1 && fields[1] == "device" {
+			current, inOps = nil, false
+			continue
+		}
+		if fields[0] == "device" {
+			current, inOps = nil, false
+			stats, err := parseMountStatsDevice(fields)
+			if err != nil {
+				return nil, err
+			}
+			if strings.HasPrefix(stats.FSType, "nfs") {
+				current = stats
+				mounts = append(mounts, current)
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch {
+		case fields[0] == "age:" && len(fields) >= 2:
+			age, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mountstats age for %s: %w", current.MountPoint, err)
+			}
+			current.Age = time.Duration(age) * time.Second
+		case fields[0] == "bytes:":
+			bytes, err := parseMountStatsBytes(fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid mountstats bytes for %s: %w", current.MountPoint, err)
+			}
+			current.Bytes = bytes
+		case strings.TrimSpace(line) == "per-op statistics":
+			inOps = true
+		case inOps && strings.HasSuffix(fields[0], ":"):
+			op, err := parseMountStatsOperation(fields)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mountstats operation for %s: %w", current.MountPoint, err)
+			}
+			current.Operations = append(current.Operations, op)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mountstats: %w", err)
+	}
+
+	return mounts, nil
+}
+
+// parseMountStatsDevice parses a line of the form
+// "device <dev> mounted on <mount point> with fstype <type> [statvers=<v>]"
+func parseMountStatsDevice(fields []string) (*NFSMountStats, error) {
+	if len(fields) < 8 || fields[2] != "mounted" || fields[3] != "on" || fields[5] != "with" || fields[6] != "fstype" {
+		return nil, fmt.Errorf("invalid mountstats device line: %q", strings.Join(fields, " "))
+	}
+
+	stats := &NFSMountStats{
+		Device:     unescapeMountPath(fields[1]),
+		MountPoint: unescapeMountPath(fields[4]),
+		FSType:     fields[7],
+	}
+	if len(fields) >= 9 {
+		stats.StatVersion = strings.TrimPrefix(fields[8], "statvers=")
+	}
+	return stats, nil
+}
+
+// parseMountStatsBytes parses the counters of a "bytes:" line
+func parseMountStatsBytes(fields []string) (NFSByteStats, error) {
+	values, err := parseUints(fields, 8)
+	if err != nil {
+		return NFSByteStats{}, err
+	}
+
+	return NFSByteStats{
+		NormalRead:  values[0],
+		NormalWrite: values[1],
+		DirectRead:  values[2],
+		DirectWrite: values[3],
+		ServerRead:  values[4],
+		ServerWrite: values[5],
+		ReadPages:   values[6],
+		WritePages:  values[7],
+	}, nil
+}
+
+// parseMountStatsOperation parses a per-op line: "<OP>: ops trans timeouts
+// bytes_sent bytes_recv queue_ms rtt_ms execute_ms [errors]"
+func parseMountStatsOperation(fields []string) (NFSOperationStats, error) {
+	values, err := parseUints(fields[1:], 8)
+	if err != nil {
+		return NFSOperationStats{}, err
+	}
+
+	op := NFSOperationStats{
+		Operation:     strings.TrimSuffix(fields[0], ":"),
+		Requests:      values[0],
+		Transmissions: values[1],
+		MajorTimeouts: values[2],
+		BytesSent:     values[3],
+		BytesReceived: values[4],
+		QueueTime:     time.Duration(values[5]) * time.Millisecond,
+		RTT:           time.Duration(values[6]) * time.Millisecond,
+		ExecuteTime:   time.Duration(values[7]) * time.Millisecond,
+	}
+	if len(values) > 8 {
+		op.Errors = values[8]
+	}
+	return op, nil
+}
+
+// parseUints parses at least count unsigned integers
+func parseUints(fields []string, count int) ([]uint64, error) {
+	if len(fields) < count {
+		return nil, fmt.Errorf("expected at least %d values, got %d", count, len(fields))
+	}
+
+	values := make([]uint64, len(fields))
+	for i, field := range fields {
+		v, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,94 @@
+package system
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMountStats = `device proc mounted on /proc with fstype proc
+device nas:/export mounted on /mnt/nas\040share with fstype nfs4 statvers=1.1
+	opts:	rw,vers=4.2,rsize=1048576,wsize=1048576,proto=tcp,sec=sys
+	age:	3600
+	caps:	caps=0x3ffbffff,wtmult=512,dtsize=32768,bsize=0,namlen=255
+	sec:	flavor=1,pseudoflavor=1
+	events:	1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17 18 19 20 21 22 23 24 25 26 27
+	bytes:	1000 2000 300 400 1500 2500 10 20
+	RPC iostats version: 1.1  p/v: 100003/4 (nfs)
+	xprt:	tcp 0 1 2 0 11 6428 6428 0 12154 0 24 26 5726
+	per-op statistics
+	        NULL: 0 0 0 0 0 0 0 0 0
+	        READ: 100 103 1 12000 1048576 5 250 300 0
+	       WRITE: 50 50 0 2097152 6400 2 400 450 1
+no device mounted on /mnt/none with fstype tmpfs
+device tmpfs mounted on /tmp with fstype tmpfs
+device old:/share mounted on /mnt/old with fstype nfs statvers=1.0
+	age:	60
+	bytes:	1 2 3 4 5 6 7 8
+	per-op statistics
+	     GETATTR: 10 12 0 1000 2000 1 20 25
+`
+
+func TestParseMountStats(t *testing.T) {
+	mounts, err := ParseMountStats(strings.NewReader(testMountStats))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mounts) != 2 {
+		t.Fatalf("Expected 2 NFS mounts, got %d", len(mounts))
+	}
+
+	nas := mounts[0]
+	if nas.Device != "nas:/export" || nas.MountPoint != "/mnt/nas share" || nas.FSType != "nfs4" || nas.StatVersion != "1.1" {
+		t.Errorf("Unexpected mount header: %+v", nas)
+	}
+	if nas.Age != time.Hour {
+		t.Errorf("Expected age of 1h, got %v", nas.Age)
+	}
+	if nas.Bytes.Read() != 1300 || nas.Bytes.Written() != 2400 || nas.Bytes.ServerRead != 1500 {
+		t.Errorf("Unexpected bytes: %+v", nas.Bytes)
+	}
+	if len(nas.Operations) != 3 {
+		t.Fatalf("Expected 3 operations, got %d", len(nas.Operations))
+	}
+
+	read := nas.Operations[1]
+	expected := NFSOperationStats{
+		Operation:     "READ",
+		Requests:      100,
+		Transmissions: 103,
+		MajorTimeouts: 1,
+		BytesSent:     12000,
+		BytesReceived: 1048576,
+		QueueTime:     5 * time.Millisecond,
+		RTT:           250 * time.Millisecond,
+		ExecuteTime:   300 * time.Millisecond,
+	}
+	if read != expected {
+		t.Errorf("Expected %+v, got %+v", expected, read)
+	}
+	if nas.Operations[2].Errors != 1 {
+		t.Errorf("Expected 1 WRITE error, got %d", nas.Operations[2].Errors)
+	}
+	if nas.Retransmissions() != 3 {
+		t.Errorf("Expected 3 retransmissions, got %d", nas.Retransmissions())
+	}
+
+	// statvers 1.0 has no error counter
+	old := mounts[1]
+	if old.MountPoint != "/mnt/old" || len(old.Operations) != 1 || old.Operations[0].Retransmissions() != 2 {
+		t.Errorf("Unexpected statvers 1.0 mount: %+v", old)
+	}
+}
+
+func TestParseMountStats_Malformed(t *testing.T) {
+	for _, input := range []string{
+		"device nas:/export mounted on\n",
+		"device nas:/e mounted on /mnt with fstype nfs\n\tbytes:\t1 2 3\n",
+		"device nas:/e mounted on /mnt with fstype nfs\n\tper-op statistics\n\tREAD: 1 2 x 4 5 6 7 8\n",
+	} {
+		if _, err := ParseMountStats(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
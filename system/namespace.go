@@ -0,0 +1,208 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrContainerNotFound is returned when no process belongs to a container
+var ErrContainerNotFound = errors.New("no process found for container")
+
+// MountNamespace identifies the mount namespace a mount point is checked in.
+// The zero value is the exporter's own namespace.
+type MountNamespace struct {
+	// PID of any process in the namespace
+	PID int
+	// Path to the namespace, in the form /proc/<pid>/ns/mnt
+	Path string
+	// Container name or ID, matched against process cgroup paths
+	Container string
+}
+
+// IsHost reports whether ns refers to the exporter's own namespace
+func (ns MountNamespace) IsHost() bool {
+	return ns.PID == 0 && ns.Path == "" && ns.Container == ""
+}
+
+// Label returns the value of the namespace label for series in ns
+func (ns MountNamespace) Label() string {
+	switch {
+	case ns.Container != "":
+		return ns.Container
+	case ns.PID != 0:
+		return fmt.Sprintf("pid:%d", ns.PID)
+	case ns.Path != "":
+		if pid, err := pidFromNamespacePath(ns.Path); err == nil {
+			return fmt.Sprintf("pid:%d", pid)
+		}
+		return ns.Path
+	default:
+		return ""
+	}
+}
+
+// pidFromNamespacePath extracts the PID from a /proc/<pid>/ns/mnt path
+func pidFromNamespacePath(path string) (int, error) {
+	parts := strings.Split(strings.Trim(filepath.Clean(path), "/"), "/")
+	if len(parts) != 4 || parts[0] != "proc" || parts[2] != "ns" || parts[3] != "mnt" {
+		return 0, fmt.Errorf("namespace path must be /proc/<pid>/ns/mnt, got %s", path)
+	}
+
+	pid, err := strconv.Atoi(parts[1])
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("namespace path must be /proc/<pid>/ns/mnt, got %s", path)
+	}
+	return pid, nil
+}
+
+// ResolveNamespacePID returns a PID whose mountinfo shows the namespace
+func (f *FindmntWrapper) ResolveNamespacePID(ns MountNamespace) (int, error) {
+	switch {
+	case ns.PID != 0:
+		return ns.PID, nil
+	case ns.Path != "":
+		return pidFromNamespacePath(ns.Path)
+	case ns.Container != "":
+		return findContainerPID(f.procRoot, ns.Container)
+	default:
+		return 0, fmt.Errorf("mount namespace is not set")
+	}
+}
+
+// findContainerPID returns the lowest PID whose cgroup path names the
+// container, which is the container's init process for the usual runtimes
+func findContainerPID(procRoot, container string) (int, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", procRoot, err)
+	}
+
+	var pids []int
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+
+	for _, pid := range pids {
+		if cgroupMentions(filepath.Join(procRoot, strconv.Itoa(pid), "cgroup"), container) {
+			return pid, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrContainerNotFound, container)
+}
+
+// containerScopes are how runtimes wrap container IDs in cgroup path
+// segments, as in docker-<id>.scope
+var containerScopes = []struct{ prefix, suffix string }{
+	{"docker-", ".scope"},
+	{"cri-containerd-", ".scope"},
+	{"crio-", ".scope"},
+	{"libpod-", ".scope"},
+}
+
+// shortIDLength is the length of abbreviated container IDs, as shown by
+// docker ps
+const shortIDLength = 12
+
+// cgroupMentions reports whether a cgroup path in the file names the
+// container: a path segment, or the ID in a runtime scope, equal to name
+func cgroupMentions(path, name string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, segment := range strings.Split(parts[2], "/") {
+			if segmentNamesContainer(segment, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// segmentNamesContainer reports whether a cgroup path segment is the
+// container name or ID. IDs may be abbreviated to shortIDLength or more.
+func segmentNamesContainer(segment, name string) bool {
+	id := segment
+	for _, scope := range containerScopes {
+		if strings.HasPrefix(segment, scope.prefix) && strings.HasSuffix(segment, scope.suffix) {
+			id = strings.TrimSuffix(strings.TrimPrefix(segment, scope.prefix), scope.suffix)
+			break
+		}
+	}
+	if id == name {
+		return true
+	}
+	return len(name) >= shortIDLength && isHex(name) && strings.HasPrefix(id, name)
+}
+
+// isHex reports whether s only contains lowercase hexadecimal digits
+func isHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckMountPointInNamespace checks a mount point in another mount namespace
+// by reading /proc/<pid>/mountinfo of a process in that namespace
+func (f *FindmntWrapper) CheckMountPointInNamespace(ctx context.Context, mountPoint string, ns MountNamespace) *FindmntResult {
+	if ns.IsHost() {
+		return f.CheckMountPoint(ctx, mountPoint)
+	}
+
+	result := &FindmntResult{
+		MountPoint: mountPoint,
+		Status:     MountStatusUnknown,
+	}
+
+	pid, err := f.ResolveNamespacePID(ns)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	entries, err := ReadMountInfo(filepath.Join(f.procRoot, strconv.Itoa(pid), "mountinfo"))
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	entry, ok := FindMountInfo(entries, mountPoint)
+	if !ok {
+		result.Status = MountStatusNotMounted
+		return result
+	}
+
+	result.Status = MountStatusMounted
+	result.Target = entry.MountPoint
+	result.FSType = entry.FSType
+	result.Options = entry.MergedOptions()
+	result.Source = entry.Source
+	result.Propagation = entry.Propagation()
+	if class, ok := ClassifyMountPoint(entries, mountPoint); ok {
+		result.Kind = class.Kind
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeProcess creates /proc/<pid>/{cgroup,mountinfo} under root
+func writeProcess(t *testing.T, root, pid, cgroup, mountinfo string) {
+	t.Helper()
+	dir := filepath.Join(root, pid)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "cgroup"), []byte(cgroup), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mountinfo"), []byte(mountinfo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newNamespaceWrapper(t *testing.T) *FindmntWrapper {
+	root := t.TempDir()
+	writeProcess(t, root, "1", "0::/init.scope\n", sampleMountInfo)
+	writeProcess(t, root, "4242", "0::/system.slice/docker-abc123.scope\n",
+		"50 49 0:50 / / rw - overlay overlay rw\n51 50 8:33 / /var/lib/postgresql rw - ext4 /dev/sdc1 rw\n"+
+			"52 50 0:60 / /mnt/nfs rw,nosuid,relatime shared:7 - nfs4 nas:/export rw,vers=4.2,rsize=1048576,sec=krb5p,clientaddr=10.0.0.2\n")
+	writeProcess(t, root, "4243", "0::/system.slice/docker-abc123.scope\n", "")
+
+	f := NewFindmntWrapper(time.Second)
+	f.procRoot = root
+	return f
+}
+
+func TestMountNamespace_Label(t *testing.T) {
+	tests := []struct {
+		ns       MountNamespace
+		expected string
+	}{
+		{MountNamespace{}, ""},
+		{MountNamespace{PID: 42}, "pid:42"},
+		{MountNamespace{Path: "/proc/1/ns/mnt"}, "pid:1"},
+		{MountNamespace{Container: "postgres"}, "postgres"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.ns.Label(); got != tt.expected {
+			t.Errorf("Expected label %q for %+v, got %q", tt.expected, tt.ns, got)
+		}
+	}
+}
+
+func TestFindmntWrapper_ResolveNamespacePID(t *testing.T) {
+	f := newNamespaceWrapper(t)
+
+	tests := []struct {
+		ns       MountNamespace
+		expected int
+	}{
+		{MountNamespace{PID: 7}, 7},
+		{MountNamespace{Path: "/proc/1/ns/mnt"}, 1},
+		{MountNamespace{Container: "abc123"}, 4242},
+	}
+	for _, tt := range tests {
+		pid, err := f.ResolveNamespacePID(tt.ns)
+		if err != nil || pid != tt.expected {
+			t.Errorf("Expected PID %d for %+v, got %d (%v)", tt.expected, tt.ns, pid, err)
+		}
+	}
+
+	// A partial name does not resolve to another container
+	if _, err := f.ResolveNamespacePID(MountNamespace{Container: "abc"}); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound for a partial name, got %v", err)
+	}
+	if _, err := f.ResolveNamespacePID(MountNamespace{Container: "missing"}); !errors.Is(err, ErrContainerNotFound) {
+		t.Errorf("Expected ErrContainerNotFound, got %v", err)
+	}
+	if _, err := f.ResolveNamespacePID(MountNamespace{Path: "/proc/self/ns/net"}); err == nil {
+		t.Error("Expected error for invalid namespace path")
+	}
+}
+
+func TestCgroupMentions(t *testing.T) {
+	const id = "4db5c0ffee2a9e8b1f0d3c7a6b5e4d3c2b1a0f9e8d7c6b5a4f3e2d1c0b9a8f7e"
+	tests := []struct {
+		cgroup   string
+		name     string
+		expected bool
+	}{
+		{"0::/system.slice/docker-" + id + ".scope\n", id, true},
+		{"0::/system.slice/docker-" + id + ".scope\n", id[:12], true},
+		{"12:memory:/docker/" + id + "\n", id, true},
+		{"0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + id + ".scope\n", id, true},
+		{"0::/machine.slice/libpod-" + id + ".scope\n", id[:16], true},
+		{"0::/lxc.payload.web\n0::/lxc/web\n", "web", true},
+		// Names must not match parts of other containers' IDs or paths
+		{"0::/system.slice/docker-" + id + ".scope\n", "db", false},
+		{"0::/system.slice/docker-" + id + ".scope\n", id[:6], false},
+		{"0::/system.slice/webapp.service\n", "web", false},
+		{"0::/docker/" + id + "\n", "ocker", false},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range tests {
+		path := filepath.Join(dir, "cgroup")
+		if err := os.WriteFile(path, []byte(tt.cgroup), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got := cgroupMentions(path, tt.name); got != tt.expected {
+			t.Errorf("Test %d: expected %v for %q in %q, got %v", i, tt.expected, tt.name, tt.cgroup, got)
+		}
+	}
+}
+
+func TestFindmntWrapper_CheckMountPointInNamespace(t *testing.T) {
+	f := newNamespaceWrapper(t)
+	ctx := context.Background()
+
+	result := f.CheckMountPointInNamespace(ctx, "/var/lib/postgresql", MountNamespace{Container: "abc123"})
+	if result.Status != MountStatusMounted || result.FSType != "ext4" || result.Source != "/dev/sdc1" {
+		t.Errorf("Expected mounted ext4 from /dev/sdc1, got %+v", result)
+	}
+	if result.Kind != MountKindDevice {
+		t.Errorf("Expected a device mount, got %s", result.Kind)
+	}
+
+	// Filesystem options such as vers= come from the super options, as with
+	// findmnt on the host
+	result = f.CheckMountPointInNamespace(ctx, "/mnt/nfs", MountNamespace{Container: "abc123"})
+	expected := "rw,nosuid,relatime,vers=4.2,rsize=1048576,sec=krb5p,clientaddr=10.0.0.2"
+	if result.Status != MountStatusMounted || result.Options != expected {
+		t.Errorf("Expected options %s, got %+v", expected, result)
+	}
+
+	result = f.CheckMountPointInNamespace(ctx, "/data", MountNamespace{Container: "abc123"})
+	if result.Status != MountStatusNotMounted || result.Error != nil {
+		t.Errorf("Expected /data not mounted in the container, got %+v", result)
+	}
+
+	result = f.CheckMountPointInNamespace(ctx, "/data", MountNamespace{PID: 99})
+	if result.Status != MountStatusUnknown || result.Error == nil {
+		t.Errorf("Expected an error for a missing process, got %+v", result)
+	}
+}
@@ -0,0 +1,197 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrProbeMismatch is returned when a write probe reads back different data
+var ErrProbeMismatch = errors.New("write probe read back different data")
+
+// WriteProbeResult is the result of a write probe
+type WriteProbeResult struct {
+	MountPoint string
+	Duration   time.Duration
+	Error      error
+}
+
+// ErrorClass returns the errno class of the probe error, "" on success
+func (r *WriteProbeResult) ErrorClass() string {
+	if r.Error == nil {
+		return ""
+	}
+	return ErrnoClass(r.Error)
+}
+
+// Ran reports whether the probe was started, rather than skipped because
+// nothing is mounted or an earlier probe is still pending
+func (r *WriteProbeResult) Ran() bool {
+	return !errors.Is(r.Error, ErrNotMounted) && !errors.Is(r.Error, ErrStillPending)
+}
+
+// ErrnoClass maps a filesystem error to a short, low-cardinality class
+func ErrnoClass(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, ErrProbeMismatch):
+		return "mismatch"
+	case errors.Is(err, ErrNotMounted):
+		return "not_mounted"
+	case errors.Is(err, syscall.ENOSPC):
+		return "enospc"
+	case errors.Is(err, syscall.EDQUOT):
+		return "edquot"
+	case errors.Is(err, syscall.EROFS):
+		return "erofs"
+	case errors.Is(err, syscall.EACCES), errors.Is(err, syscall.EPERM):
+		return "eacces"
+	case errors.Is(err, syscall.EIO):
+		return "eio"
+	case errors.Is(err, syscall.ESTALE):
+		return "estale"
+	case errors.Is(err, syscall.ENOENT):
+		return "enoent"
+	default:
+		return "other"
+	}
+}
+
+// WriteProber creates, fsyncs, reads back and removes a small file in a
+// subdirectory of a mount point to check that writes succeed
+type WriteProber struct {
+	directory string
+	timeout   time.Duration
+	procRoot  string
+	seq       atomic.Uint64
+	mu        sync.Mutex
+	pending   map[string]bool
+}
+
+// NewWriteProber creates a WriteProber writing into directory, relative to
+// the probed mount point, with the given deadline per probe
+func NewWriteProber(directory string, timeout time.Duration) *WriteProber {
+	return &WriteProber{
+		directory: directory,
+		timeout:   timeout,
+		procRoot:  "/proc",
+		pending:   make(map[string]bool),
+	}
+}
+
+// Probe runs a write probe on mountPoint, or reports ErrNotMounted when
+// nothing is mounted there. File operations cannot be interrupted, so a
+// probe that misses its deadline keeps running in the background and no
+// new probe of the same mount point starts until it ends.
+func (p *WriteProber) Probe(ctx context.Context, mountPoint string) *WriteProbeResult {
+	result := &WriteProbeResult{MountPoint: mountPoint}
+
+	// Checked against mountinfo rather than by comparing st_dev with the
+	// parent directory, which would touch a possibly hung filesystem
+	if err := p.checkMounted(mountPoint); err != nil {
+		result.Error = err
+		return result
+	}
+
+	p.mu.Lock()
+	if p.pending[mountPoint] {
+		p.mu.Unlock()
+		result.Error = fmt.Errorf("write probe on %s: %w: %w", mountPoint, ErrStillPending, context.DeadlineExceeded)
+		return result
+	}
+	p.pending[mountPoint] = true
+	p.mu.Unlock()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			p.mu.Lock()
+			delete(p.pending, mountPoint)
+			p.mu.Unlock()
+		}()
+		done <- p.writeReadRemove(filepath.Join(mountPoint, p.directory))
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	select {
+	case err := <-done:
+		result.Error = err
+	case <-ctx.Done():
+		result.Error = fmt.Errorf("write probe on %s timed out after %v: %w", mountPoint, p.timeout, ctx.Err())
+	}
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+// checkMounted returns ErrNotMounted unless mountPoint is listed in the
+// mount table
+func (p *WriteProber) checkMounted(mountPoint string) error {
+	entries, err := ReadMountInfo(filepath.Join(p.procRoot, "self", "mountinfo"))
+	if err != nil {
+		return fmt.Errorf("failed to check whether %s is mounted: %w", mountPoint, err)
+	}
+
+	mountPoint = filepath.Clean(mountPoint)
+	for _, entry := range entries {
+		if entry.MountPoint == mountPoint {
+			return nil
+		}
+	}
+	return fmt.Errorf("skipped write probe on %s: %w", mountPoint, ErrNotMounted)
+}
+
+// writeReadRemove performs the probe's file operations in dir. Only dir
+// itself is created: a missing parent means the mount is not what it
+// should be, and creating it would write to the wrong filesystem.
+func (p *WriteProber) writeReadRemove(dir string) error {
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("failed to create probe directory: %w", err)
+	}
+
+	name := filepath.Join(dir, fmt.Sprintf("probe-%d-%d", os.Getpid(), p.seq.Add(1)))
+	payload := []byte("mount-exporter write probe " + strconv.FormatInt(time.Now().UnixNano(), 10) + "\n")
+
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create probe file: %w", err)
+	}
+	defer os.Remove(name)
+
+	if _, err := file.Write(payload); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write probe file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to fsync probe file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close probe file: %w", err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("failed to read back probe file: %w", err)
+	}
+	if !bytes.Equal(data, payload) {
+		return ErrProbeMismatch
+	}
+
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("failed to remove probe file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestErrnoClass(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{&os.PathError{Op: "write", Path: "/data/x", Err: syscall.ENOSPC}, "enospc"},
+		{fmt.Errorf("failed: %w", syscall.EROFS), "erofs"},
+		{syscall.EDQUOT, "edquot"},
+		{syscall.EPERM, "eacces"},
+		{syscall.EIO, "eio"},
+		{context.DeadlineExceeded, "timeout"},
+		{ErrProbeMismatch, "mismatch"},
+		{fmt.Errorf("/data: %w", ErrNotMounted), "not_mounted"},
+		{errors.New("boom"), "other"},
+	}
+
+	for _, tt := range tests {
+		if got := ErrnoClass(tt.err); got != tt.expected {
+			t.Errorf("ErrnoClass(%v) = %s, expected %s", tt.err, got, tt.expected)
+		}
+	}
+}
+
+// newMountedProber returns a prober whose mount table lists mountPoints
+func newMountedProber(t *testing.T, directory string, mountPoints ...string) *WriteProber {
+	t.Helper()
+
+	procRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(procRoot, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	mountinfo := "22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw\n"
+	for i, mp := range mountPoints {
+		mountinfo += fmt.Sprintf("%d 22 8:%d / %s rw,relatime shared:%d - ext4 /dev/sdb%d rw\n", 30+i, 17+i, mp, 2+i, 1+i)
+	}
+	if err := os.WriteFile(filepath.Join(procRoot, "self", "mountinfo"), []byte(mountinfo), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	prober := NewWriteProber(directory, time.Second)
+	prober.procRoot = procRoot
+	return prober
+}
+
+func TestWriteProber_Probe(t *testing.T) {
+	mountPoint := t.TempDir()
+	prober := newMountedProber(t, ".probe", mountPoint)
+
+	result := prober.Probe(context.Background(), mountPoint)
+	if result.Error != nil {
+		t.Fatalf("Expected probe to succeed, got %v", result.Error)
+	}
+	if result.Duration <= 0 || result.ErrorClass() != "" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// The probe file is removed, the directory is kept for the next probe
+	entries, err := os.ReadDir(filepath.Join(mountPoint, ".probe"))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected an empty probe directory, got %v (%v)", entries, err)
+	}
+}
+
+func TestWriteProber_ProbeFailure(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("Permission checks do not apply to root")
+	}
+
+	mountPoint := t.TempDir()
+	if err := os.Chmod(mountPoint, 0o500); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(mountPoint, 0o700)
+
+	result := newMountedProber(t, ".probe", mountPoint).Probe(context.Background(), mountPoint)
+	if result.Error == nil || result.ErrorClass() != "eacces" {
+		t.Errorf("Expected eacces failure, got %+v", result)
+	}
+}
+
+func TestWriteProber_NotMounted(t *testing.T) {
+	mountPoint := t.TempDir()
+	prober := newMountedProber(t, ".probe", "/data")
+
+	result := prober.Probe(context.Background(), mountPoint)
+	if !errors.Is(result.Error, ErrNotMounted) || result.ErrorClass() != "not_mounted" || result.Ran() {
+		t.Errorf("Expected not_mounted failure, got %+v", result)
+	}
+
+	// Nothing is written to the parent filesystem
+	entries, err := os.ReadDir(mountPoint)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected the directory to be left untouched, got %v (%v)", entries, err)
+	}
+}
+
+func TestWriteProber_MissingParent(t *testing.T) {
+	mountPoint := t.TempDir()
+	prober := newMountedProber(t, "missing/.probe", mountPoint)
+
+	result := prober.Probe(context.Background(), mountPoint)
+	if result.ErrorClass() != "enoent" {
+		t.Errorf("Expected enoent failure, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(mountPoint, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the missing parent not to be created, got %v", err)
+	}
+}
+
+func TestWriteProber_PendingProbe(t *testing.T) {
+	mountPoint := t.TempDir()
+	prober := newMountedProber(t, ".probe", mountPoint)
+
+	// Stand in for an earlier probe that is still hanging
+	prober.mu.Lock()
+	prober.pending[mountPoint] = true
+	prober.mu.Unlock()
+
+	result := prober.Probe(context.Background(), mountPoint)
+	if !errors.Is(result.Error, context.DeadlineExceeded) || !errors.Is(result.Error, ErrStillPending) || result.Ran() {
+		t.Errorf("Expected pending probe to be reported as a timeout, got %+v", result)
+	}
+	if result.ErrorClass() != "timeout" {
+		t.Errorf("Expected timeout class, got %s", result.ErrorClass())
+	}
+}
@@ -236,9 +236,9 @@ func TestIntegration_ConcurrentAccess(t *testing.T) {
 
 	for i := 0; i < numGoroutines; i++ {
 		go func(index int) {
-			newCfg := *cfg
+			newCfg := cfg.Clone()
 			newCfg.MountPoints = append(newCfg.MountPoints, fmt.Sprintf("/test-%d", index))
-			srv.GetCollector().UpdateConfig(&newCfg)
+			srv.GetCollector().UpdateConfig(newCfg)
 			done <- true
 		}(i)
 	}